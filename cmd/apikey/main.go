@@ -0,0 +1,198 @@
+// Command apikey manages the API keys of the server-to-server clients.
+//
+// Usage:
+//
+//	apikey create -name billing -scopes cart:read,cart:write [-roles support] [-expires 720h]
+//	apikey list
+//	apikey rotate -id 1
+//	apikey revoke -id 1
+//
+// The database is configured with the same POSTGRES_URL as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/config"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+)
+
+const usage = `usage:
+	apikey create -name <name> -scopes <scope,...> [-roles <role,...>] [-expires <duration>]
+	apikey list
+	apikey rotate -id <id>
+	apikey revoke -id <id>`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	c, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Process config file error: %v", err)
+	}
+
+	log.SetOutput(logging.Redact(os.Stderr, c.Secrets()...))
+
+	ctx := context.Background()
+
+	poolConfig, err := postgres.ParseConfig(c.DatabaseURL(), postgres.PoolOptions{
+		MaxConns:       int32(c.DBMaxConns),
+		ConnectTimeout: c.DBConnectTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Parse database config error: %v", err)
+	}
+
+	settings := postgres.NewSettings(c.DBQueryTimeout, c.DBRetryAttempts)
+
+	pool, err := postgres.Connect(ctx, poolConfig, c.DBStartupTimeout, settings)
+	if err != nil {
+		log.Fatalf("Connect to database error: %v", err)
+	}
+
+	defer pool.Close()
+
+	keys := service.NewAPIKeyService(pool)
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "create":
+		err = create(ctx, keys, args)
+	case "list":
+		err = list(ctx, keys)
+	case "rotate":
+		err = rotate(ctx, keys, args)
+	case "revoke":
+		err = revoke(ctx, keys, args)
+	default:
+		err = fmt.Errorf("unknown command %q\n%s", cmd, usage)
+	}
+
+	if err != nil {
+		pool.Close()
+		log.Fatal(err)
+	}
+}
+
+// create creates the API key and prints the plain key.
+func create(ctx context.Context, keys service.APIKeys, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "name of the client which uses the key")
+	scopes := fs.String("scopes", "", "comma separated scopes granted to the client")
+	roles := fs.String("roles", "", "comma separated roles of the client, shopper by default")
+	expires := fs.Duration("expires", 0, "lifetime of the key, zero means it never expires")
+
+	_ = fs.Parse(args)
+
+	k := model.APIKey{Name: *name, Scopes: splitList(*scopes), Roles: splitList(*roles)}
+
+	if *expires > 0 {
+		k.ExpiresAt = time.Now().Add(*expires)
+	}
+
+	created, err := keys.CreateAPIKey(ctx, &k)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %d %q, store the key now, it is not shown again:\n%s\n",
+		created.ID, created.Name, created.Key)
+
+	return nil
+}
+
+// list prints all the API keys.
+func list(ctx context.Context, keys service.APIKeys) error {
+	all, err := keys.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tROLES\tEXPIRES\tLAST USED\tREVOKED")
+
+	for i := range all {
+		k := &all[i]
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","),
+			strings.Join(k.Roles, ","), formatTime(k.ExpiresAt), formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+	}
+
+	return w.Flush()
+}
+
+// rotate replaces the key of the API key and prints the new plain key.
+func rotate(ctx context.Context, keys service.APIKeys, args []string) error {
+	id, err := parseID("rotate", args)
+	if err != nil {
+		return err
+	}
+
+	k, err := keys.RotateAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rotated API key %d %q, store the key now, it is not shown again:\n%s\n", k.ID, k.Name, k.Key)
+
+	return nil
+}
+
+// revoke revokes the API key.
+func revoke(ctx context.Context, keys service.APIKeys, args []string) error {
+	id, err := parseID("revoke", args)
+	if err != nil {
+		return err
+	}
+
+	err = keys.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked API key %d\n", id)
+
+	return nil
+}
+
+// parseID parses the required -id flag of the command.
+func parseID(cmd string, args []string) (int, error) {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	id := fs.Int("id", 0, "ID of the API key")
+
+	_ = fs.Parse(args)
+
+	if *id <= 0 {
+		return 0, fmt.Errorf("-id is required\n%s", usage)
+	}
+
+	return *id, nil
+}
+
+// splitList splits the comma separated list, the empty list is nil.
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	return strings.Split(list, ",")
+}
+
+// formatTime formats the time as RFC 3339, the zero time is printed as a dash.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
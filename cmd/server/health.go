@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/migrations"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+)
+
+// newHealthChecker creates the checker of the database, its schema and the background workers.
+// A worker is unhealthy if it hasn't beaten within maxAge.
+func newHealthChecker(pool *postgres.Pool, maxAge time.Duration, relay, dispatcher *health.Heartbeat) *health.Checker {
+	checker := health.NewChecker()
+
+	checker.Add("database", pool.Ping)
+	checker.Add("migrations", func(ctx context.Context) error {
+		return checkMigrations(ctx, pool)
+	})
+
+	checker.Add("outbox_relay", relay.Check(maxAge))
+	checker.Add("webhook_dispatcher", dispatcher.Check(maxAge))
+
+	return checker
+}
+
+// checkMigrations returns an error if the schema isn't migrated to the latest embedded migration
+// or the last migration failed.
+func checkMigrations(ctx context.Context, pool *postgres.Pool) error {
+	expected, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := postgres.SchemaVersion(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", version)
+	}
+
+	if version != expected {
+		return fmt.Errorf("schema version %d, expected %d", version, expected)
+	}
+
+	return nil
+}
@@ -2,44 +2,431 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/metrics"
+	"github.com/fedo3nik/cart-go-api/internal/application/outbox"
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
 	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/application/webhook"
 	"github.com/fedo3nik/cart-go-api/internal/config"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tlsconfig"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
 	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+	"github.com/fedo3nik/cart-go-api/internal/interface/middleware"
+	"github.com/fedo3nik/cart-go-api/internal/interface/router"
+	"github.com/fedo3nik/cart-go-api/internal/interface/rpc"
 
-	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	c, err := config.NewConfig()
+	loader := config.Loader{Args: os.Args[1:], LookupEnv: os.LookupEnv}
+
+	c, err := loader.Load()
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		log.Fatalf("Load config error: %v", err)
+	}
+
+	if loader.PrintConfig {
+		err = c.Print(os.Stdout)
+		if err != nil {
+			log.Fatalf("Print config error: %v", err)
+		}
+
+		return
+	}
+
+	// The level is set globally, so it can be changed on the config reload.
+	logger, err := logging.New(logging.Redact(os.Stderr, c.Secrets()...), logging.LevelAll, c.LogFormat)
+	if err != nil {
+		log.Fatalf("Configure logging error: %v", err)
+	}
+
+	err = logging.SetLevel(c.LogLevel)
+	if err != nil {
+		log.Fatalf("Configure logging error: %v", err)
+	}
+
+	logging.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), c.TraceExporter, c.TraceEndpoint, os.Stdout)
 	if err != nil {
-		log.Fatalf("Process config file error: %v", err)
+		logger.Fatal().Err(err).Msg("Configure tracing error")
 	}
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
+
+	poolConfig, err := postgres.ParseConfig(c.DatabaseURL(), poolOptions(c))
 	if err != nil {
-		log.Fatalf("Connect to database error: %v", err)
+		logger.Fatal().Err(err).Msg("Parse database config error")
 	}
 
-	handler := mux.NewRouter()
+	poolConfig.ConnConfig.Logger = logging.PgxLogger{}
+	poolConfig.ConnConfig.LogLevel = pgx.LogLevelWarn
+
+	pool, err := postgres.Connect(context.Background(), poolConfig, c.DBStartupTimeout,
+		postgres.NewSettings(c.DBQueryTimeout, c.DBRetryAttempts))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Connect to database error")
+	}
 
 	cartService := service.NewCartService(pool)
+	cartService.SetLimits(cartLimits(c))
+
+	go reloadOnSignal(&loader, c, cartService, pool.Settings)
+
+	bg := newWorkers()
+
+	var (
+		m          *metrics.Metrics
+		adminSrv   *http.Server
+		grpcServer *grpc.Server
+	)
+
+	probes := http.NewServeMux()
 
-	createCartHandler := controller.NewHTTPCreateCartHandler(cartService)
-	addItemHandler := controller.NewHTTPAddItemHandler(cartService)
-	removeItemHandler := controller.NewHTTPRemoveItemHandler(cartService)
-	getCartHandler := controller.NewHTTPGetCartHandler(cartService)
+	if c.AdminPort != 0 {
+		reg := metrics.NewRegistry()
+		reg.MustRegister(metrics.NewPoolCollector(pool.Pool))
 
-	handler.Handle("/carts", createCartHandler).Methods(http.MethodPost)
-	handler.Handle("/carts/{cartID}/items", addItemHandler).Methods(http.MethodPost)
-	handler.Handle("/carts/{cartID}/items/{itemID}", removeItemHandler).Methods(http.MethodDelete)
-	handler.Handle("/carts/{cartID}", getCartHandler)
+		m = metrics.New(reg)
+		cartService.Metrics = m
+
+		adminRouter := http.NewServeMux()
+		adminRouter.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		adminRouter.Handle("/", probes)
+
+		adminSrv = newHTTPServer(c, c.Addr(c.AdminPort), adminRouter)
+
+		go func() {
+			err := adminSrv.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				logger.Fatal().Err(err).Msg("Listen & Serve admin error")
+			}
+		}()
+	}
+
+	hub := pubsub.NewHub()
+	cartService.Events = hub
+
+	if c.EventsFanout {
+		cartService.Events = pubsub.NewNotifyPublisher(pool)
+
+		bg.Go(func(ctx context.Context) {
+			pubsub.Relay(ctx, pool, hub)
+		})
+	}
 
-	err = http.ListenAndServe(c.Host+c.Port, handler)
+	sinks, err := outbox.NewSinks(c.OutboxSinks, c.OutboxFile, c.OutboxURL)
 	if err != nil {
-		log.Fatalf("Listen & Serve error: %v", err)
+		logger.Fatal().Err(err).Msg("Configure outbox sinks error")
 	}
+
+	relay := outbox.NewRelay(pool, append(sinks, webhook.NewSink(pool))...)
+	relay.Retention = c.OutboxRetention
+	relay.Heartbeat = &health.Heartbeat{}
+
+	bg.Go(relay.Run)
+
+	dispatcher := webhook.NewDispatcher(pool)
+	dispatcher.MaxAttempts = c.WebhookMaxAttempts
+	dispatcher.Heartbeat = &health.Heartbeat{}
+
+	bg.Go(dispatcher.Run)
+
+	checker := newHealthChecker(pool, c.WorkerMaxAge, relay.Heartbeat, dispatcher.Heartbeat)
+
+	probes.Handle("/healthz", controller.NewHTTPLivenessHandler())
+	probes.Handle("/readyz", controller.NewHTTPReadinessHandler(checker, false))
+	probes.Handle("/health/details", controller.NewHTTPReadinessHandler(checker, true))
+
+	apiKeyService := service.NewAPIKeyService(pool)
+
+	carts := service.NewTracedCart(cartService)
+
+	verifier, err := newJWTVerifier(c)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Configure authentication error")
+	}
+
+	var (
+		authenticators    []middleware.Authenticator
+		rpcAuthenticators []rpc.Authenticator
+	)
+
+	if verifier != nil {
+		authenticators = append(authenticators, middleware.NewBearerAuthenticator(verifier))
+		rpcAuthenticators = append(rpcAuthenticators, rpc.NewBearerAuthenticator(verifier))
+	}
+
+	if c.APIKeys {
+		authenticators = append(authenticators, middleware.NewAPIKeyAuthenticator(apiKeyService))
+		rpcAuthenticators = append(rpcAuthenticators, rpc.NewAPIKeyAuthenticator(apiKeyService))
+	}
+
+	tlsReloader, err := newTLSReloader(c)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Configure TLS error")
+	}
+
+	var tlsConfig *tls.Config
+
+	if tlsReloader != nil {
+		tlsConfig = tlsReloader.TLSConfig()
+
+		bg.Go(tlsReloader.Run)
+
+		// The explicit credentials take precedence over the client certificate.
+		if !strings.EqualFold(c.TLSClientAuth, tlsconfig.ClientAuthNone) {
+			authenticators = append(authenticators, middleware.NewClientCertAuthenticator(c.TLSClientScopes))
+			rpcAuthenticators = append(rpcAuthenticators, rpc.NewClientCertAuthenticator(c.TLSClientScopes))
+		}
+	}
+
+	v1Routes := router.V1Routes(carts, hub)
+
+	versions := []router.Version{
+		{Prefix: "", Routes: v1Routes, Deprecated: true, Sunset: c.V1Sunset, Successor: "/v2"},
+		{Prefix: "/v1", Routes: v1Routes, Deprecated: true, Sunset: c.V1Sunset, Successor: "/v2"},
+		{Prefix: "/v2", Routes: router.V2Routes(carts, hub), RESTErrors: true},
+		{Prefix: "", Routes: router.GraphQLRoutes(carts)},
+	}
+
+	// Without authentication anyone would be an admin, so the admin and webhook routes aren't served.
+	if len(authenticators) > 0 {
+		versions = append(versions,
+			router.Version{Prefix: "/v2", Routes: router.WebhookRoutes(service.NewWebhookService(pool)), RESTErrors: true},
+			router.Version{Prefix: "/admin", Routes: router.AdminRoutes(service.NewAuditService(pool), apiKeyService), RESTErrors: true},
+		)
+	}
+
+	r := router.New(versions...)
+
+	limits, err := ratelimit.ParseLimits(c.RateLimits)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Configure rate limits error")
+	}
+
+	var authFailures *ratelimit.FailureLimiter
+
+	if len(limits) > 0 || c.AuthFailureLimit != "" {
+		store, err := newRateLimitStore(c, pool, bg)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Configure rate limits error")
+		}
+
+		if len(limits) > 0 {
+			r.Use(middleware.RateLimit(store, limits))
+		}
+
+		if c.AuthFailureLimit != "" {
+			limit, err := ratelimit.ParseLimit(c.AuthFailureLimit)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Configure authentication failure limit error")
+			}
+
+			authFailures = ratelimit.NewFailureLimiter(store, limit)
+		}
+	}
+
+	var handler http.Handler = r
+
+	if len(authenticators) > 0 {
+		handler = middleware.Authenticate(authFailures, authenticators...)(handler)
+	} else {
+		logger.Warn().Msg("Authentication is disabled and the admin and webhook routes aren't served, " +
+			"set CART_JWT_HS256_SECRET, CART_JWT_JWKS, CART_API_KEYS or CART_TLS_CLIENT_AUTH to enable it")
+	}
+
+	if c.GRPCPort != 0 {
+		lis, err := net.Listen("tcp", c.Addr(c.GRPCPort))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Listen gRPC error")
+		}
+
+		grpcServer = rpc.NewTLSServer(carts, hub, tlsConfig, authFailures, rpcAuthenticators...)
+
+		go func() {
+			err := grpcServer.Serve(lis)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Serve gRPC error")
+			}
+		}()
+	}
+
+	handler = middleware.Tracing(r)(middleware.AccessLog(logger, r)(middleware.Metrics(m, r)(handler)))
+
+	// The probes bypass the authentication, rate limits and access log.
+	root := http.NewServeMux()
+	root.Handle("/healthz", probes)
+	root.Handle("/readyz", probes)
+	root.Handle("/health/details", probes)
+	root.Handle("/", handler)
+
+	srv := newHTTPServer(c, c.Addr(c.Port), middleware.RequestMetadata(c.TrustedProxies)(root))
+	srv.TLSConfig = tlsConfig
+
+	go func() {
+		logger.Info().Str("addr", srv.Addr).Bool("tls", tlsConfig != nil).Msg("Listen & Serve")
+
+		var err error
+		if tlsConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.Fatal().Err(err).Msg("Listen & Serve error")
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	<-ctx.Done()
+	stop() // the second signal kills the process
+
+	// The readiness fails during the drain, so the load balancers stop sending new requests
+	// while the server still serves them.
+	logger.Info().Dur("drain", c.ShutdownDrain).Msg("Draining")
+	checker.Drain()
+	time.Sleep(c.ShutdownDrain)
+
+	logger.Info().Dur("timeout", c.ShutdownTimeout).Msg("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+	defer cancel()
+
+	shutdownHTTP(shutdownCtx, "http", srv)
+	shutdownGRPC(shutdownCtx, grpcServer)
+	shutdownHTTP(shutdownCtx, "admin", adminSrv)
+
+	// The workers are stopped after the servers, so the events of the last requests are delivered.
+	err = bg.Stop(shutdownCtx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Background workers haven't stopped")
+	}
+
+	pool.Close()
+
+	logger.Info().Msg("Shutdown completed")
+}
+
+// newHTTPServer creates the server with the timeouts from the config.
+func newHTTPServer(c *config.Config, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       c.ReadTimeout,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		ConnContext:       controller.WithConn,
+	}
+}
+
+// newJWTVerifier creates the verifier of the bearer tokens from the config.
+// Returns nil if neither the HS256 secret nor the JWKS is configured.
+func newJWTVerifier(c *config.Config) (*auth.JWTVerifier, error) {
+	if c.JWTSecret == "" && c.JWKS == "" {
+		return nil, nil
+	}
+
+	v := auth.JWTVerifier{Issuer: c.JWTIssuer, Audience: c.JWTAudience}
+
+	if c.JWTSecret != "" {
+		v.Secret = []byte(c.JWTSecret)
+	}
+
+	if c.JWKS != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		keys, err := auth.LoadKeySet(ctx, c.JWKS, &http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			return nil, err
+		}
+
+		v.Keys = keys
+	}
+
+	return &v, nil
+}
+
+// newTLSReloader creates the reloader of the TLS certificates from the config.
+// Returns nil if the certificate isn't configured, so the servers listen in plaintext.
+func newTLSReloader(c *config.Config) (*tlsconfig.Reloader, error) {
+	if c.TLSCertFile == "" && c.TLSKeyFile == "" {
+		return nil, nil
+	}
+
+	r, err := tlsconfig.New(tlsconfig.Options{
+		CertFile:     c.TLSCertFile,
+		KeyFile:      c.TLSKeyFile,
+		MinVersion:   c.TLSMinVersion,
+		CipherSuites: c.TLSCipherSuites,
+		ClientAuth:   c.TLSClientAuth,
+		ClientCAFile: c.TLSClientCAFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.ReloadInterval = c.TLSReloadInterval
+
+	return r, nil
+}
+
+// poolOptions returns the settings of the database connection pool from the config.
+func poolOptions(c *config.Config) postgres.PoolOptions {
+	return postgres.PoolOptions{
+		MaxConns:          int32(c.DBMaxConns),
+		MinConns:          int32(c.DBMinConns),
+		MaxConnLifetime:   c.DBMaxConnLifetime,
+		MaxConnIdleTime:   c.DBMaxConnIdleTime,
+		HealthCheckPeriod: c.DBHealthCheckPeriod,
+		ConnectTimeout:    c.DBConnectTimeout,
+	}
+}
+
+// newRateLimitStore creates the store of the token buckets from the config.
+// The postgres store deletes the idle buckets in the background until the workers are stopped.
+// Returns an error if the store is unknown.
+func newRateLimitStore(c *config.Config, pool *postgres.Pool, bg *workers) (ratelimit.Store, error) {
+	switch c.RateLimitStore {
+	case "memory":
+		return ratelimit.NewMemoryStore(), nil
+	case "postgres":
+		store := ratelimit.NewPostgresStore(pool)
+
+		bg.Go(store.Run)
+
+		return store, nil
+	}
+
+	return nil, fmt.Errorf("unknown rate limit store %q", c.RateLimitStore)
 }
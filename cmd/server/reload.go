@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/config"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+)
+
+// cartLimits returns the limits of the carts from the config.
+func cartLimits(c *config.Config) model.CartLimits {
+	return model.CartLimits{
+		MaxLines:         c.MaxCartLines,
+		MaxLineQuantity:  c.MaxLineQuantity,
+		MaxUnits:         c.MaxCartUnits,
+		MaxProductLength: c.MaxProductLength,
+		ProductLimits:    c.ProductLimits,
+	}
+}
+
+// reloadOnSignal reloads the config on SIGHUP and applies the settings which are safe to change at runtime:
+// the log level, the limits of the carts and the query timeout and the retries of the database operations of the pool.
+// The changes of the other settings are logged and take effect after a restart.
+// An invalid config is rejected and the current one is kept.
+func reloadOnSignal(loader *config.Loader, current *config.Config, cartService *service.CartService, db *postgres.Settings) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	for range sig {
+		logger := logging.Default()
+
+		updated, err := loader.Load()
+		if err != nil {
+			logger.Error().Err(err).Msg("Reload config error, keeping the current config")
+
+			continue
+		}
+
+		var applied, restart []string
+
+		for _, change := range config.Diff(current, updated) {
+			if change.Reload {
+				applied = append(applied, change.Env)
+			} else {
+				restart = append(restart, change.Env)
+			}
+		}
+
+		// The settings which require a restart keep their current values, so they are reported until then.
+		current = current.WithReloaded(updated)
+
+		// The validation guarantees that the level is known.
+		_ = logging.SetLevel(current.LogLevel)
+		cartService.SetLimits(cartLimits(current))
+		db.SetQueryTimeout(current.DBQueryTimeout)
+		db.SetRetryAttempts(current.DBRetryAttempts)
+
+		logger.Info().Strs("applied", applied).Strs("restart_required", restart).Msg("Config reloaded")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+
+	"google.golang.org/grpc"
+)
+
+// workers runs the background workers until they are stopped.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newWorkers is a constructor for workers struct.
+func newWorkers() *workers {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &workers{ctx: ctx, cancel: cancel}
+}
+
+// Go runs the worker in the background, the worker must return when its context is canceled.
+func (w *workers) Go(run func(ctx context.Context)) {
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		run(w.ctx)
+	}()
+}
+
+// Stop cancels the context of the workers and waits until they return or the ctx is done.
+func (w *workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdownHTTP stops the server accepting new connections and waits for the in-flight requests.
+// The connections still active when the ctx is done, e.g. the event streams, are closed.
+func shutdownHTTP(ctx context.Context, name string, srv *http.Server) {
+	if srv == nil {
+		return
+	}
+
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		return
+	}
+
+	logging.Default().Warn().Err(err).Str("server", name).Msg("Graceful shutdown timed out, closing connections")
+
+	_ = srv.Close()
+}
+
+// shutdownGRPC stops the server accepting new connections and waits for the in-flight calls.
+// The calls still active when the ctx is done are canceled.
+func shutdownGRPC(ctx context.Context, srv *grpc.Server) {
+	if srv == nil {
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logging.Default().Warn().Err(ctx.Err()).Str("server", "grpc").Msg("Graceful shutdown timed out, closing connections")
+		srv.Stop()
+	}
+}
@@ -0,0 +1,50 @@
+// Package audit carries the information about the origin of the request to the service layer
+// so every mutation can be recorded in the audit log.
+package audit
+
+import "context"
+
+// AnonymousActor is an actor of the requests made without authentication.
+const AnonymousActor = "anonymous"
+
+// Metadata represents the origin of the request.
+type Metadata struct {
+	Actor     string // Authenticated user or client, empty for anonymous requests
+	ClientIP  string // IP address of the client
+	UserAgent string // User agent of the client
+	RequestID string // ID of the request
+}
+
+// metadataKey is a context key of the request Metadata.
+type metadataKey struct{}
+
+// WithMetadata returns a copy of the context carrying the metadata.
+func WithMetadata(ctx context.Context, m Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, m)
+}
+
+// MetadataFrom returns the metadata carried by the context.
+// Requests without metadata, e.g. made by the background workers, get the zero Metadata.
+func MetadataFrom(ctx context.Context) Metadata {
+	m, _ := ctx.Value(metadataKey{}).(Metadata)
+
+	return m
+}
+
+// WithActor returns a copy of the context whose metadata has the actor set.
+// It is used by the authentication layers once the caller is known.
+func WithActor(ctx context.Context, actor string) context.Context {
+	m := MetadataFrom(ctx)
+	m.Actor = actor
+
+	return WithMetadata(ctx, m)
+}
+
+// ActorOrAnonymous returns the actor of the metadata or AnonymousActor if it is blank.
+func (m Metadata) ActorOrAnonymous() string {
+	if m.Actor == "" {
+		return AnonymousActor
+	}
+
+	return m.Actor
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// MethodAPIKey is an authentication method of the principals authenticated with the API key.
+const MethodAPIKey = "api_key"
+
+// Format of the API keys.
+const (
+	apiKeyPrefix    = "ck_"
+	apiKeyBytes     = 32
+	apiKeyPrefixLen = len(apiKeyPrefix) + 8
+)
+
+// APIKeyVerifier is the interface that authenticates the callers by the API key.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// NewAPIKey generates a random API key in the ck_<hex> format.
+func NewAPIKey() (string, error) {
+	b := make([]byte, apiKeyBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash of the key under which it is stored.
+// The keys are random enough to not need a salt or a slow hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix returns the first characters of the key which are stored to recognize it.
+func APIKeyPrefix(key string) string {
+	if len(key) < apiKeyPrefixLen {
+		return key
+	}
+
+	return key[:apiKeyPrefixLen]
+}
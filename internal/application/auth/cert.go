@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+)
+
+// MethodClientCert is an authentication method of the principals authenticated with the client certificate.
+const MethodClientCert = "client_cert"
+
+// ClientIdentity represents the client identified by its verified TLS certificate.
+type ClientIdentity struct {
+	CommonName   string    // Common name of the subject
+	Organization []string  // Organizations of the subject
+	DNSNames     []string  // DNS names of the subject alternative name
+	URIs         []string  // URIs of the subject alternative name, e.g. the SPIFFE ID
+	Emails       []string  // Email addresses of the subject alternative name
+	SerialNumber string    // Serial number of the certificate
+	Fingerprint  string    // Hex SHA-256 of the certificate
+	Issuer       string    // Common name of the issuer
+	NotAfter     time.Time // Time after which the certificate expires
+}
+
+// NewClientIdentity is a constructor for ClientIdentity struct from the certificate.
+func NewClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	sum := sha256.Sum256(cert.Raw)
+
+	id := ClientIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		Emails:       cert.EmailAddresses,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+		Issuer:       cert.Issuer.CommonName,
+		NotAfter:     cert.NotAfter,
+	}
+
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+
+	return &id
+}
+
+// Name returns the name of the client: the first URI, e.g. the SPIFFE ID, or the common name.
+func (ci *ClientIdentity) Name() string {
+	if len(ci.URIs) > 0 {
+		return ci.URIs[0]
+	}
+
+	return ci.CommonName
+}
+
+// VerifiedClientIdentity returns the identity of the client certificate verified during the handshake.
+// Returns nil if the connection isn't TLS or the client presented no certificate.
+func VerifiedClientIdentity(state *tls.ConnectionState) *ClientIdentity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return NewClientIdentity(state.VerifiedChains[0][0])
+}
+
+// NewClientCertPrincipal returns the principal of the client identified by the certificate
+// which is granted the scopes.
+func NewClientCertPrincipal(id *ClientIdentity, scopes []string) *Principal {
+	return &Principal{Subject: id.Name(), Scopes: scopes, Method: MethodClientCert}
+}
+
+// clientIdentityKey is a context key of the client identity.
+type clientIdentityKey struct{}
+
+// WithClientIdentity returns a copy of the context carrying the identity of the client certificate.
+func WithClientIdentity(ctx context.Context, id *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, id)
+}
+
+// ClientIdentityFrom returns the identity of the client certificate carried by the context,
+// nil if the client presented no certificate.
+func ClientIdentityFrom(ctx context.Context) *ClientIdentity {
+	id, _ := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+
+	return id
+}
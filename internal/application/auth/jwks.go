@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+)
+
+// jwksRefreshInterval limits how often a remote key set is reloaded when an unknown key ID is received,
+// so the tokens with random key IDs can't make the service fetch the key set on every request.
+const jwksRefreshInterval = time.Minute
+
+// jwk represents a single key of the JSON Web Key Set, only RSA keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// KeySet represents the RSA public keys loaded from the JWKS file or URL.
+// Remote key sets are reloaded when a token is signed with an unknown key so the keys can be rotated.
+type KeySet struct {
+	Source string       // Path of the file or URL of the key set
+	Client *http.Client // HTTP client used for the remote key set
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+
+	refreshMu   sync.Mutex // serializes the reloads, so the concurrent requests share one
+	refreshedAt time.Time  // time of the last load attempt, successful or not
+}
+
+// LoadKeySet loads the key set from the path of the file or the http(s) URL.
+// Returns an error if the key set can't be read or contains no RSA keys.
+func LoadKeySet(ctx context.Context, source string, client *http.Client) (*KeySet, error) {
+	ks := KeySet{Source: source, Client: client, refreshedAt: time.Now()}
+
+	err := ks.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ks, nil
+}
+
+// remote reports whether the key set is loaded by URL.
+func (ks *KeySet) remote() bool {
+	return strings.HasPrefix(ks.Source, "http://") || strings.HasPrefix(ks.Source, "https://")
+}
+
+// read reads the raw key set from the source.
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !ks.remote() {
+		return ioutil.ReadFile(ks.Source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks responded with status %d", resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// load reads and parses the key set replacing the current keys.
+func (ks *KeySet) load(ctx context.Context) error {
+	raw, err := ks.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseKeySet(raw)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// refresh reloads the remote key set unless it was attempted within jwksRefreshInterval.
+// The concurrent callers wait for the single reload instead of fetching the key set each,
+// and the failed attempts count too, so the unavailable source isn't hammered.
+func (ks *KeySet) refresh(ctx context.Context) {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	if time.Since(ks.refreshedAt) < jwksRefreshInterval {
+		return
+	}
+
+	ks.refreshedAt = time.Now()
+
+	err := ks.load(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn().Err(err).Str("jwks", ks.Source).Msg("Reload JWKS error")
+	}
+}
+
+// Key returns the public key with the key ID.
+// Unknown key ID reloads the remote key set at most once per jwksRefreshInterval.
+func (ks *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, bool) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+
+	if ok || !ks.remote() {
+		return key, ok
+	}
+
+	ks.refresh(ctx)
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok = ks.keys[kid]
+
+	return key, ok
+}
+
+// ParseKeySet parses the RSA signing keys of the JSON Web Key Set.
+// Returns an error if the key set can't be decoded or contains no RSA keys.
+func ParseKeySet(raw []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	err := json.Unmarshal(raw, &set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus of key %q: %w", k.Kid, err)
+		}
+
+		exp, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent of key %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(exp).Int64())}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks contains no RSA signing keys")
+	}
+
+	return keys, nil
+}
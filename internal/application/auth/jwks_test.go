@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Key(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	set, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		_, _ = w.Write(set)
+	}))
+	defer server.Close()
+
+	ks, err := LoadKeySet(context.Background(), server.URL, server.Client())
+	require.NoError(t, err)
+
+	_, ok := ks.Key(context.Background(), "key-2")
+	assert.False(t, ok)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "the key set must not be reloaded right after the load")
+
+	ks.refreshedAt = time.Time{}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, ok := ks.Key(context.Background(), "key-2")
+			assert.False(t, ok)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the concurrent unknown key IDs must share one reload")
+
+	_, ok = ks.Key(context.Background(), "key-1")
+	assert.True(t, ok)
+}
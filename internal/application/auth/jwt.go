@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+// MethodJWT is an authentication method of the principals authenticated with the JWT bearer token.
+const MethodJWT = "jwt"
+
+// JWTVerifier validates the JWT bearer tokens signed with HS256 or RS256.
+type JWTVerifier struct {
+	Secret   []byte  // Secret of the HS256 tokens, nil disables HS256
+	Keys     *KeySet // Public keys of the RS256 tokens, nil disables RS256
+	Issuer   string  // Required iss claim, empty skips the check
+	Audience string  // Required aud claim, empty skips the check
+}
+
+// methods returns the signing methods accepted by the verifier.
+func (v *JWTVerifier) methods() []string {
+	var methods []string
+
+	if v.Secret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if v.Keys != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	return methods
+}
+
+// Verify validates the signature, the expiration and the issuer and audience of the token.
+// The tokens without the exp claim are rejected, because they could never be revoked.
+// Returns the principal with the sub claim as the subject, the scopes from
+// the space separated scope claim or the scp array claim and the roles from
+// the roles array claim or the role claim.
+// Also it returns ErrUnauthenticated error if the token is invalid.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parser := jwt.Parser{ValidMethods: v.methods()}
+
+	claims := jwt.MapClaims{}
+
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			return v.Secret, nil
+		case jwt.SigningMethodRS256.Alg():
+			kid, _ := t.Header["kid"].(string)
+
+			key, ok := v.Keys.Key(ctx, kid)
+			if !ok {
+				return nil, fmt.Errorf("unknown key %q", kid)
+			}
+
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	})
+	if err != nil {
+		return nil, errors.Wrap(e.ErrUnauthenticated, err.Error())
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "token has no expiration")
+	}
+
+	if v.Issuer != "" && !claims.VerifyIssuer(v.Issuer, true) {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "unexpected issuer")
+	}
+
+	if v.Audience != "" && !claims.VerifyAudience(v.Audience, true) {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "unexpected audience")
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "token has no subject")
+	}
+
+	return &Principal{Subject: sub, Scopes: tokenScopes(claims), Roles: tokenRoles(claims), Method: MethodJWT}, nil
+}
+
+// tokenScopes extracts the scopes from the scope or scp claims.
+func tokenScopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	return stringsClaim(claims, "scp")
+}
+
+// tokenRoles extracts the roles from the roles or role claims.
+func tokenRoles(claims jwt.MapClaims) []string {
+	if role, ok := claims["role"].(string); ok {
+		return []string{role}
+	}
+
+	return stringsClaim(claims, "roles")
+}
+
+// stringsClaim extracts the strings of the array claim.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	var values []string
+
+	if array, ok := claims[name].([]interface{}); ok {
+		for _, v := range array {
+			if v, ok := v.(string); ok {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
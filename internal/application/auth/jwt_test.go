@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func signHS256(t *testing.T, secret []byte, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestJWTVerifier_VerifyHS256(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+
+	tt := []struct {
+		name           string
+		secret         []byte
+		claims         jwt.MapClaims
+		expectedScopes []string
+		expectedRoles  []string
+		expectedErr    bool
+	}{
+		{
+			name:           "Valid token with scope claim",
+			secret:         testSecret,
+			claims:         jwt.MapClaims{"sub": "alice", "exp": future, "scope": "cart:read cart:write"},
+			expectedScopes: []string{ScopeCartRead, ScopeCartWrite},
+		},
+		{
+			name:           "Valid token with scp claim",
+			secret:         testSecret,
+			claims:         jwt.MapClaims{"sub": "alice", "exp": future, "scp": []string{ScopeAdmin}, "iss": "issuer", "aud": "cart"},
+			expectedScopes: []string{ScopeAdmin},
+		},
+		{
+			name:          "Valid token with roles claim",
+			secret:        testSecret,
+			claims:        jwt.MapClaims{"sub": "alice", "exp": future, "roles": []string{RoleSupport, RoleAdmin}},
+			expectedRoles: []string{RoleSupport, RoleAdmin},
+		},
+		{
+			name:          "Valid token with role claim",
+			secret:        testSecret,
+			claims:        jwt.MapClaims{"sub": "alice", "exp": future, "role": RoleSupport},
+			expectedRoles: []string{RoleSupport},
+		},
+		{name: "Expired token", secret: testSecret, claims: jwt.MapClaims{"sub": "alice", "exp": past}, expectedErr: true},
+		{name: "Wrong secret", secret: []byte("other"), claims: jwt.MapClaims{"sub": "alice", "exp": future}, expectedErr: true},
+		{name: "No expiration", secret: testSecret, claims: jwt.MapClaims{"sub": "alice"}, expectedErr: true},
+		{name: "No subject", secret: testSecret, claims: jwt.MapClaims{"exp": future}, expectedErr: true},
+		{
+			name:        "Wrong issuer",
+			secret:      testSecret,
+			claims:      jwt.MapClaims{"sub": "alice", "exp": future, "iss": "other", "aud": "cart"},
+			expectedErr: true,
+		},
+		{
+			name:        "Wrong audience",
+			secret:      testSecret,
+			claims:      jwt.MapClaims{"sub": "alice", "exp": future, "iss": "issuer", "aud": "other"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			v := JWTVerifier{Secret: testSecret}
+			if _, ok := tc.claims["iss"]; ok {
+				v.Issuer, v.Audience = "issuer", "cart"
+			}
+
+			p, err := v.Verify(context.Background(), signHS256(t, tc.secret, tc.claims))
+			if tc.expectedErr {
+				assert.ErrorIs(t, err, e.ErrUnauthenticated)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "alice", p.Subject)
+			assert.Equal(t, tc.expectedScopes, p.Scopes)
+			assert.Equal(t, tc.expectedRoles, p.Roles)
+			assert.Equal(t, MethodJWT, p.Method)
+		})
+	}
+}
+
+func TestJWTVerifier_VerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	set, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "jwks")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jwks.json")
+	require.NoError(t, ioutil.WriteFile(path, set, 0600))
+
+	keys, err := LoadKeySet(context.Background(), path, nil)
+	require.NoError(t, err)
+
+	future := time.Now().Add(time.Hour).Unix()
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "bob", "exp": future, "scope": ScopeCartRead})
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	v := JWTVerifier{Keys: keys}
+
+	p, err := v.Verify(context.Background(), sign("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "bob", p.Subject)
+	assert.True(t, p.HasScope(ScopeCartRead))
+
+	_, err = v.Verify(context.Background(), sign("key-2"))
+	assert.ErrorIs(t, err, e.ErrUnauthenticated)
+
+	_, err = v.Verify(context.Background(), signHS256(t, testSecret, jwt.MapClaims{"sub": "bob", "exp": future}))
+	assert.ErrorIs(t, err, e.ErrUnauthenticated)
+}
+
+func TestRequire(t *testing.T) {
+	tt := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{name: "Not enforced", ctx: context.Background()},
+		{name: "Anonymous", ctx: WithAuthentication(context.Background(), nil), expectedErr: e.ErrUnauthenticated},
+		{
+			name:        "Missing scope",
+			ctx:         WithAuthentication(context.Background(), &Principal{Subject: "alice", Scopes: []string{ScopeCartRead}}),
+			expectedErr: e.ErrForbidden,
+		},
+		{
+			name: "Granted scope",
+			ctx:  WithAuthentication(context.Background(), &Principal{Subject: "alice", Scopes: []string{ScopeCartWrite}}),
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := Require(tc.ctx, ScopeCartWrite)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
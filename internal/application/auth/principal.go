@@ -0,0 +1,124 @@
+// Package auth authenticates the callers of the API and checks the scopes required by the operations.
+package auth
+
+import (
+	"context"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+)
+
+// Scopes required by the operations.
+const (
+	ScopeCartRead       = "cart:read"
+	ScopeCartWrite      = "cart:write"
+	ScopeWebhooksManage = "webhooks:manage"
+	ScopeAdmin          = "admin"
+)
+
+// Roles of the callers evaluated by the policy of the service layer.
+const (
+	RoleShopper = "shopper"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// KnownRole reports whether the role is one of the roles of the callers.
+func KnownRole(role string) bool {
+	switch role {
+	case RoleShopper, RoleSupport, RoleAdmin:
+		return true
+	}
+
+	return false
+}
+
+// KnownScope reports whether the scope is one of the scopes required by the operations.
+func KnownScope(scope string) bool {
+	switch scope {
+	case ScopeCartRead, ScopeCartWrite, ScopeWebhooksManage, ScopeAdmin:
+		return true
+	}
+
+	return false
+}
+
+// Principal represents the authenticated caller.
+type Principal struct {
+	Subject string   // Subject of the token or the name of the key
+	Scopes  []string // Scopes granted to the caller
+	Roles   []string // Roles of the caller, no roles means shopper
+	Method  string   // Authentication method, e.g. jwt
+}
+
+// HasScope reports whether the principal was granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasRole reports whether the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authKey is a context key of the authentication state.
+type authKey struct{}
+
+// state is the authentication state of the request.
+type state struct {
+	principal *Principal
+}
+
+// WithAuthentication returns a copy of the context in which the authentication is enforced.
+// The principal is nil for the requests without credentials.
+func WithAuthentication(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, authKey{}, state{principal: p})
+}
+
+// PrincipalFrom returns the authenticated caller carried by the context, nil for anonymous callers.
+func PrincipalFrom(ctx context.Context) *Principal {
+	s, _ := ctx.Value(authKey{}).(state)
+
+	return s.principal
+}
+
+// Enforced reports whether the authentication is enforced for the request.
+// It is false when no authentication is configured, e.g. in development.
+func Enforced(ctx context.Context) bool {
+	_, ok := ctx.Value(authKey{}).(state)
+
+	return ok
+}
+
+// Require checks that the caller was granted all the scopes.
+// Returns ErrUnauthenticated error if the caller is anonymous and ErrForbidden error if a scope is missing.
+// It always succeeds if the authentication isn't enforced.
+func Require(ctx context.Context, scopes ...string) error {
+	if !Enforced(ctx) || len(scopes) == 0 {
+		return nil
+	}
+
+	p := PrincipalFrom(ctx)
+	if p == nil {
+		return e.ErrUnauthenticated
+	}
+
+	for _, scope := range scopes {
+		if !p.HasScope(scope) {
+			return e.ErrForbidden
+		}
+	}
+
+	return nil
+}
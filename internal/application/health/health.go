@@ -0,0 +1,142 @@
+// Package health checks whether the application is able to serve the requests.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of the checks and of the whole report.
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// DefaultTimeout limits the duration of a single check.
+const DefaultTimeout = 2 * time.Second
+
+// Check reports whether the dependency of the application is healthy, nil means healthy.
+type Check func(ctx context.Context) error
+
+// Result represents the result of the single check.
+type Result struct {
+	Name     string        // Name of the check
+	Status   string        // StatusOK or StatusFailing
+	Error    string        // Error of the failing check
+	Duration time.Duration // Duration of the check
+}
+
+// Report represents the results of all the checks.
+type Report struct {
+	Status    string        // StatusOK if all the checks pass, StatusDraining during the shutdown and StatusFailing otherwise
+	StartedAt time.Time     // Time when the checker was created
+	Uptime    time.Duration // Time since the checker was created
+	Checks    []Result      // Results of the checks in the order they were added
+}
+
+// Ready reports whether the application may receive the requests.
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// namedCheck is a check with its name.
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks of the readiness.
+type Checker struct {
+	Timeout time.Duration // maximum duration of a single check
+
+	mu        sync.Mutex
+	checks    []namedCheck
+	draining  int32
+	startedAt time.Time
+}
+
+// NewChecker is a constructor for Checker struct.
+func NewChecker() *Checker {
+	return &Checker{Timeout: DefaultTimeout, startedAt: time.Now()}
+}
+
+// Add adds the named check.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain marks the application as shutting down, so it isn't ready anymore
+// and the load balancers stop sending new requests while the in-flight ones are finished.
+func (c *Checker) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+// Draining reports whether the application is shutting down.
+func (c *Checker) Draining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Report runs all the checks concurrently and reports their results.
+func (c *Checker) Report(ctx context.Context) *Report {
+	c.mu.Lock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.Unlock()
+
+	r := Report{
+		Status:    StatusOK,
+		StartedAt: c.startedAt,
+		Uptime:    time.Since(c.startedAt),
+		Checks:    make([]Result, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range checks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			r.Checks[i] = c.run(ctx, checks[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, res := range r.Checks {
+		if res.Status != StatusOK {
+			r.Status = StatusFailing
+		}
+	}
+
+	if c.Draining() {
+		r.Status = StatusDraining
+	}
+
+	return &r
+}
+
+// run runs the check within the timeout.
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	res := Result{Name: nc.name, Status: StatusOK}
+
+	err := nc.check(ctx)
+	if err != nil {
+		res.Status = StatusFailing
+		res.Error = err.Error()
+	}
+
+	res.Duration = time.Since(start)
+
+	return res
+}
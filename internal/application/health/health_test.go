@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Report(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	tt := []struct {
+		name     string
+		checks   map[string]Check
+		drain    bool
+		status   string
+		failures map[string]string
+	}{
+		{
+			name:   "No checks",
+			status: StatusOK,
+		},
+		{
+			name:   "All pass",
+			checks: map[string]Check{"database": ok, "migrations": ok},
+			status: StatusOK,
+		},
+		{
+			name:     "One fails",
+			checks:   map[string]Check{"database": failing, "migrations": ok},
+			status:   StatusFailing,
+			failures: map[string]string{"database": "connection refused"},
+		},
+		{
+			name:     "Timeout",
+			checks:   map[string]Check{"database": slow},
+			status:   StatusFailing,
+			failures: map[string]string{"database": context.DeadlineExceeded.Error()},
+		},
+		{
+			name:   "Draining",
+			checks: map[string]Check{"database": ok},
+			drain:  true,
+			status: StatusDraining,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewChecker()
+			c.Timeout = 10 * time.Millisecond
+
+			for name, check := range tc.checks {
+				c.Add(name, check)
+			}
+
+			if tc.drain {
+				c.Drain()
+			}
+
+			r := c.Report(context.Background())
+			assert.Equal(t, tc.status, r.Status)
+			assert.Equal(t, tc.status == StatusOK, r.Ready())
+			require.Len(t, r.Checks, len(tc.checks))
+
+			for _, res := range r.Checks {
+				if msg, ok := tc.failures[res.Name]; ok {
+					assert.Equal(t, StatusFailing, res.Status)
+					assert.Equal(t, msg, res.Error)
+
+					continue
+				}
+
+				assert.Equal(t, StatusOK, res.Status)
+				assert.Empty(t, res.Error)
+			}
+		})
+	}
+}
+
+func TestHeartbeat_Check(t *testing.T) {
+	var hb Heartbeat
+
+	check := hb.Check(time.Minute)
+	assert.Equal(t, ErrNotStarted, check(context.Background()))
+
+	hb.Beat()
+	assert.NoError(t, check(context.Background()))
+
+	hb.mu.Lock()
+	hb.last = time.Now().Add(-2 * time.Minute)
+	hb.mu.Unlock()
+
+	assert.Error(t, check(context.Background()))
+
+	var nilHB *Heartbeat
+
+	assert.NotPanics(t, nilHB.Beat)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotStarted is a custom error that returns if the worker hasn't beaten yet.
+var ErrNotStarted = errors.New("worker isn't started")
+
+// Heartbeat records the liveness of the background worker which beats on every iteration of its loop.
+// The methods of the nil Heartbeat do nothing, so the workers may run without it.
+type Heartbeat struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Beat records that the worker is alive.
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last = time.Now()
+}
+
+// Last returns the time of the last beat, zero if the worker hasn't beaten yet.
+func (h *Heartbeat) Last() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.last
+}
+
+// Check returns the check which fails if the worker hasn't beaten within maxAge.
+func (h *Heartbeat) Check(maxAge time.Duration) Check {
+	return func(context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			return ErrNotStarted
+		}
+
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago", age.Round(time.Second))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+// Package logging configures the structured logger of the application
+// and carries the logger with the request fields through the context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Formats of the log output.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// defaultLogger is used when the context doesn't carry a logger, e.g. by the background workers.
+var defaultLogger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+// loggerKey is a context key of the logger.
+type loggerKey struct{}
+
+// New creates a logger that writes to the writer with the level, e.g. debug, info, warn or error,
+// in the format: json writes a json object per line and console writes a human readable line.
+// Returns an error if the level or the format is unknown.
+func New(w io.Writer, level, format string) (zerolog.Logger, error) {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return zerolog.Logger{}, err
+	}
+
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+	case FormatConsole:
+		w = zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}
+	default:
+		return zerolog.Logger{}, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return zerolog.New(w).Level(lvl).With().Timestamp().Logger(), nil
+}
+
+// LevelAll is a level of the logger which writes all the events, so the level is controlled with SetLevel.
+const LevelAll = "trace"
+
+// SetLevel changes the minimal level of the events written by all the loggers, e.g. on the config reload.
+// Returns an error if the level is unknown.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(lvl)
+
+	return nil
+}
+
+// SetDefault makes the logger the default one and redirects the standard log package to it.
+// It must be called before the server starts since the default logger isn't guarded.
+func SetDefault(l zerolog.Logger) {
+	defaultLogger = l
+
+	log.SetFlags(0)
+	log.SetOutput(l)
+}
+
+// Default returns the default logger.
+func Default() *zerolog.Logger {
+	return &defaultLogger
+}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, l zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &l)
+}
+
+// FromContext returns the logger carried by the context or the default logger.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zerolog.Logger); ok {
+		return l
+	}
+
+	return &defaultLogger
+}
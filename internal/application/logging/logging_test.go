@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tt := []struct {
+		name        string
+		level       string
+		format      string
+		expectedErr bool
+	}{
+		{name: "JSON", level: "info", format: FormatJSON},
+		{name: "Console", level: "DEBUG", format: FormatConsole},
+		{name: "Default format", level: "warn"},
+		{name: "Unknown level", level: "loud", format: FormatJSON, expectedErr: true},
+		{name: "Unknown format", level: "info", format: "xml", expectedErr: true},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tc.level, tc.format)
+			if tc.expectedErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+
+	l, err := New(&buf, "info", FormatJSON)
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), FromContext(context.Background()))
+
+	ctx := WithLogger(context.Background(), l.With().Str("request_id", "abc").Logger())
+	FromContext(ctx).Debug().Msg("skipped")
+	FromContext(ctx).Error().Int("cart_id", 1).Msg("failed")
+
+	var entry map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, float64(1), entry["cart_id"])
+	assert.Equal(t, "failed", entry["message"])
+}
+
+func TestSetLevel(t *testing.T) {
+	defer func() {
+		require.NoError(t, SetLevel("trace"))
+	}()
+
+	var buf bytes.Buffer
+
+	l, err := New(&buf, LevelAll, FormatJSON)
+	require.NoError(t, err)
+
+	require.NoError(t, SetLevel("warn"))
+	l.Info().Msg("skipped")
+	assert.Zero(t, buf.Len())
+
+	require.NoError(t, SetLevel("DEBUG"))
+	l.Debug().Msg("written")
+	assert.Contains(t, buf.String(), "written")
+
+	assert.Error(t, SetLevel("loud"))
+}
@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+)
+
+// PgxLogger writes the messages of the database driver to the logger of the query context,
+// so the failed queries are logged with the request ID.
+type PgxLogger struct{}
+
+// Log is a method to log the message of the driver.
+func (PgxLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	var lvl zerolog.Level
+
+	switch level {
+	case pgx.LogLevelTrace, pgx.LogLevelDebug:
+		lvl = zerolog.DebugLevel
+	case pgx.LogLevelInfo:
+		lvl = zerolog.InfoLevel
+	case pgx.LogLevelWarn:
+		lvl = zerolog.WarnLevel
+	default:
+		lvl = zerolog.ErrorLevel
+	}
+
+	FromContext(ctx).WithLevel(lvl).Str("component", "pgx").Fields(data).Msg(msg)
+}
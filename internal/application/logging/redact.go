@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the secrets in the logs.
+const Redacted = "REDACTED"
+
+// minSecretLength is a length below which the secrets aren't redacted, since they would mangle the logs.
+const minSecretLength = 4
+
+// redactWriter replaces the secrets in the written logs.
+type redactWriter struct {
+	w        io.Writer
+	replacer *strings.Replacer
+}
+
+// Redact returns the writer which replaces the secrets with Redacted in the logs written to w,
+// e.g. the database password in the connection errors. The json and URL escaped forms of the secrets
+// are replaced as well. Returns w if there are no secrets to redact.
+func Redact(w io.Writer, secrets ...string) io.Writer {
+	forms := make(map[string]bool)
+
+	for _, s := range secrets {
+		if len(s) < minSecretLength {
+			continue
+		}
+
+		forms[s] = true
+		forms[url.QueryEscape(s)] = true
+		forms[url.PathEscape(s)] = true
+
+		if b, err := json.Marshal(s); err == nil {
+			forms[strings.Trim(string(b), `"`)] = true
+		}
+	}
+
+	if len(forms) == 0 {
+		return w
+	}
+
+	sorted := make([]string, 0, len(forms))
+	for s := range forms {
+		sorted = append(sorted, s)
+	}
+
+	// The longest forms are replaced first, so a secret containing another one is redacted completely.
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+
+		return sorted[i] < sorted[j]
+	})
+
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, s := range sorted {
+		pairs = append(pairs, s, Redacted)
+	}
+
+	return &redactWriter{w: w, replacer: strings.NewReplacer(pairs...)}
+}
+
+// Write writes the log with the secrets replaced.
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(rw.w, rw.replacer.Replace(string(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+
+	l, err := New(Redact(&buf, `p@ss"word`, "hmac-key", "pw"), "info", FormatJSON)
+	require.NoError(t, err)
+
+	l.Error().Err(errors.New(`cannot parse "postgres://cart:p%40ss%22word@db/cart": failed`)).
+		Str("dsn", `password=p@ss"word`).
+		Str("secret", "hmac-key").
+		Str("short", "pw").
+		Msg("Connect to database error")
+
+	out := buf.String()
+	assert.NotContains(t, out, "p@ss")
+	assert.NotContains(t, out, "p%40ss")
+	assert.NotContains(t, out, "hmac-key")
+	assert.Contains(t, out, "postgres://cart:REDACTED@db/cart")
+	assert.Contains(t, out, `"dsn":"password=REDACTED"`)
+	assert.Contains(t, out, `"short":"pw"`, "the short secrets aren't redacted")
+
+	w := &bytes.Buffer{}
+	assert.Equal(t, w, Redact(w, "", "pw"))
+}
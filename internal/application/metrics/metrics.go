@@ -0,0 +1,123 @@
+// Package metrics collects the Prometheus metrics of the HTTP API, the carts and the connection pool.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// namespace prefixes the names of all the metrics.
+const namespace = "cart"
+
+// UnmatchedRoute is a route label of the requests which don't match any route.
+const UnmatchedRoute = "unmatched"
+
+// Metrics represents the collectors of the application metrics.
+// The methods of the nil Metrics do nothing, so the metrics may be disabled.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	cartsCreated    prometheus.Counter
+	itemsAdded      prometheus.Counter
+	itemsRemoved    prometheus.Counter
+	cartSize        prometheus.Histogram
+}
+
+// New creates the collectors of the application metrics and registers them with the Registerer.
+func New(reg prometheus.Registerer) *Metrics {
+	m := Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of the HTTP requests by the method, the route template and the status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the HTTP requests by the method, the route template and the status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		cartsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "carts_created_total",
+			Help:      "Number of the created carts.",
+		}),
+		itemsAdded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_added_total",
+			Help:      "Number of the items added to the carts.",
+		}),
+		itemsRemoved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_removed_total",
+			Help:      "Number of the items removed from the carts.",
+		}),
+		cartSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cart_size_items",
+			Help:      "Number of the items in the cart after it was changed.",
+			Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+		}),
+	}
+
+	reg.MustRegister(m.requests, m.requestDuration, m.cartsCreated, m.itemsAdded, m.itemsRemoved, m.cartSize)
+
+	return &m
+}
+
+// NewRegistry creates the registry with the collectors of the Go runtime and the process.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return reg
+}
+
+// ObserveRequest records the HTTP request, blank route means the request didn't match any route.
+func (m *Metrics) ObserveRequest(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	code := strconv.Itoa(status)
+
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(d.Seconds())
+}
+
+// CartCreated records the created cart.
+func (m *Metrics) CartCreated() {
+	if m == nil {
+		return
+	}
+
+	m.cartsCreated.Inc()
+	m.cartSize.Observe(0)
+}
+
+// ItemAdded records the item added to the cart with the size after the addition.
+func (m *Metrics) ItemAdded(cartSize int) {
+	if m == nil {
+		return
+	}
+
+	m.itemsAdded.Inc()
+	m.cartSize.Observe(float64(cartSize))
+}
+
+// ItemRemoved records the item removed from the cart with the size after the removal.
+func (m *Metrics) ItemRemoved(cartSize int) {
+	if m == nil {
+		return
+	}
+
+	m.itemsRemoved.Inc()
+	m.cartSize.Observe(float64(cartSize))
+}
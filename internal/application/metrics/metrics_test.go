@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	m.CartCreated()
+	m.ItemAdded(1)
+	m.ItemAdded(2)
+	m.ItemRemoved(1)
+
+	expected := `
+# HELP cart_carts_created_total Number of the created carts.
+# TYPE cart_carts_created_total counter
+cart_carts_created_total 1
+# HELP cart_items_added_total Number of the items added to the carts.
+# TYPE cart_items_added_total counter
+cart_items_added_total 2
+# HELP cart_items_removed_total Number of the items removed from the carts.
+# TYPE cart_items_removed_total counter
+cart_items_removed_total 1
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"cart_carts_created_total", "cart_items_added_total", "cart_items_removed_total")
+	require.NoError(t, err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, f := range families {
+		if f.GetName() == "cart_cart_size_items" {
+			h := f.GetMetric()[0].GetHistogram()
+			assert.Equal(t, uint64(4), h.GetSampleCount())
+			assert.Equal(t, float64(4), h.GetSampleSum())
+		}
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.CartCreated()
+		m.ItemAdded(1)
+		m.ItemRemoved(0)
+		m.ObserveRequest("GET", "", 200, 0)
+	})
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector collects the statistics of the database connection pool on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+}
+
+// NewPoolCollector is a constructor for PoolCollector struct.
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_conns", "Number of the connections currently acquired from the pool."),
+		idleConns:         desc("idle_conns", "Number of the idle connections in the pool."),
+		constructingConns: desc("constructing_conns", "Number of the connections being established."),
+		totalConns:        desc("total_conns", "Total number of the connections in the pool."),
+		maxConns:          desc("max_conns", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Number of the successful acquires from the pool."),
+		canceledAcquires:  desc("canceled_acquires_total", "Number of the acquires canceled by the context."),
+		emptyAcquires:     desc("empty_acquires_total", "Number of the acquires that waited for a connection because the pool was empty."),
+		acquireDuration:   desc("acquire_wait_seconds_total", "Total time spent waiting for the connections."),
+	}
+}
+
+// Describe is a method to send the descriptions of the pool metrics to the channel.
+func (pc *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.acquiredConns
+	ch <- pc.idleConns
+	ch <- pc.constructingConns
+	ch <- pc.totalConns
+	ch <- pc.maxConns
+	ch <- pc.acquires
+	ch <- pc.canceledAcquires
+	ch <- pc.emptyAcquires
+	ch <- pc.acquireDuration
+}
+
+// Collect is a method to send the current statistics of the pool to the channel.
+func (pc *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := pc.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(pc.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(pc.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(pc.constructingConns, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(pc.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(pc.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(pc.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.canceledAcquires, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
@@ -0,0 +1,206 @@
+package outbox
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default settings of the Relay.
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultMaxBackoff   = 10 * time.Minute
+	DefaultLease        = 5 * time.Minute
+	DefaultRetention    = 7 * 24 * time.Hour
+	DefaultPurgeEvery   = time.Hour
+)
+
+// Relay delivers the messages stored in the outbox to the sinks.
+// A message is marked as delivered only after all the sinks accepted it, so the delivery is at-least-once.
+// Failed messages are retried with exponential backoff only on the sinks which haven't accepted them yet.
+// Delivered messages are purged after the retention, so the outbox doesn't grow forever.
+type Relay struct {
+	Pool         *postgres.Pool    // connection pool
+	Sinks        []Sink            // destinations of the messages
+	BatchSize    int               // maximum number of messages claimed at once
+	PollInterval time.Duration     // how often the outbox is polled when it's empty
+	MaxBackoff   time.Duration     // upper bound of the delay between the delivery attempts
+	Lease        time.Duration     // how long the claimed batch is hidden from other relays while it's delivered
+	Retention    time.Duration     // how long the delivered messages are kept, 0 keeps them forever
+	PurgeEvery   time.Duration     // how often the delivered messages are purged
+	Heartbeat    *health.Heartbeat // records the liveness of the worker, optional
+}
+
+// NewRelay is a constructor for Relay struct with the default settings.
+func NewRelay(pool *postgres.Pool, sinks ...Sink) *Relay {
+	return &Relay{
+		Pool:         pool,
+		Sinks:        sinks,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		MaxBackoff:   DefaultMaxBackoff,
+		Lease:        DefaultLease,
+		Retention:    DefaultRetention,
+		PurgeEvery:   DefaultPurgeEvery,
+	}
+}
+
+// Run delivers the messages and purges the delivered ones every PurgeEvery until the context is canceled.
+func (r *Relay) Run(ctx context.Context) {
+	var purgedAt time.Time
+
+	for {
+		r.Heartbeat.Beat()
+
+		if r.Retention > 0 && time.Since(purgedAt) >= r.PurgeEvery {
+			purgedAt = time.Now()
+
+			_, err := r.Purge(ctx)
+			if err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Error().Err(err).Msg("Purge outbox error")
+			}
+		}
+
+		n, err := r.DeliverBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Deliver outbox batch error")
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.PollInterval):
+		}
+	}
+}
+
+// DeliverBatch claims a single batch of the pending messages, delivers them and stores the results.
+// The batch is claimed and the results are stored in the short transactions, the sinks are called outside of them.
+// The batch is delivered within the lease, the messages which weren't attempted in time are left for the next claim.
+// Returns the number of the claimed messages.
+// Also it returns an error if the batch can't be claimed or the delivery results can't be stored.
+func (r *Relay) DeliverBatch(ctx context.Context) (int, error) {
+	messages, err := postgres.ClaimPendingOutbox(ctx, r.Pool, r.BatchSize, time.Now().Add(r.Lease))
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	deliverCtx, cancel := context.WithTimeout(ctx, r.Lease)
+	defer cancel()
+
+	failures := make([][]string, 0, len(messages))
+
+	for i := range messages {
+		if deliverCtx.Err() != nil {
+			break
+		}
+
+		failures = append(failures, r.deliver(deliverCtx, &messages[i]))
+	}
+
+	err = postgres.RetryTx(ctx, r.Pool, func(tx pgx.Tx) error {
+		for i := range failures {
+			err := r.store(ctx, tx, &messages[i], failures[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return len(messages), err
+}
+
+// Purge deletes the messages delivered longer than Retention ago.
+// Returns the number of the deleted messages.
+// Also it returns an error if the messages can't be deleted.
+func (r *Relay) Purge(ctx context.Context) (int64, error) {
+	return postgres.DeleteDeliveredOutbox(ctx, r.Pool, r.Retention)
+}
+
+// deliver delivers the message to the sinks which haven't accepted it yet and adds the accepting ones to it.
+// The delivery is traced as a span continuing the trace of the request which caused the event.
+// Returns the errors of the failed sinks.
+func (r *Relay) deliver(ctx context.Context, msg *model.OutboxMessage) []string {
+	var failures []string
+
+	ctx, span := tracing.Tracer().Start(tracing.WithTraceParent(ctx, msg.TraceParent), "outbox deliver",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.Int64("outbox.event.id", msg.ID), attribute.String("outbox.event.type", msg.Type)))
+	defer span.End()
+
+	for _, sink := range r.Sinks {
+		if delivered(msg, sink.Name()) {
+			continue
+		}
+
+		err := sink.Deliver(ctx, msg)
+		if err != nil {
+			failures = append(failures, sink.Name()+": "+err.Error())
+
+			continue
+		}
+
+		msg.DeliveredSinks = append(msg.DeliveredSinks, sink.Name())
+	}
+
+	if len(failures) > 0 {
+		span.SetStatus(codes.Error, strings.Join(failures, "; "))
+	}
+
+	return failures
+}
+
+// store stores the result of the delivery of the message.
+func (r *Relay) store(ctx context.Context, tx pgx.Tx, msg *model.OutboxMessage, failures []string) error {
+	if len(failures) == 0 {
+		return postgres.MarkOutboxDelivered(ctx, tx, msg.ID, msg.DeliveredSinks)
+	}
+
+	return postgres.MarkOutboxFailed(ctx, tx, msg.ID, msg.DeliveredSinks, strings.Join(failures, "; "),
+		time.Now().Add(Backoff(msg.Attempts, r.MaxBackoff)))
+}
+
+// delivered reports whether the sink has already accepted the message.
+func delivered(msg *model.OutboxMessage, sink string) bool {
+	for _, name := range msg.DeliveredSinks {
+		if name == sink {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Backoff returns the delay before the next delivery attempt after the number of the failed attempts.
+// The delay doubles with every attempt starting from one second and is limited by max.
+func Backoff(attempts int, max time.Duration) time.Duration {
+	d := time.Second
+
+	for i := 0; i < attempts; i++ {
+		d *= 2
+
+		if d >= max {
+			return max
+		}
+	}
+
+	return d
+}
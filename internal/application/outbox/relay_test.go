@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingSink counts the deliveries and fails them with err.
+type countingSink struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (cs *countingSink) Name() string {
+	return cs.name
+}
+
+func (cs *countingSink) Deliver(_ context.Context, _ *model.OutboxMessage) error {
+	cs.calls++
+
+	return cs.err
+}
+
+func TestRelay_deliver(t *testing.T) {
+	accepted := &countingSink{name: "log"}
+	failed := &countingSink{name: "http", err: errors.New("connection refused")}
+	relay := NewRelay(nil, accepted, failed)
+	msg := testMessage()
+
+	failures := relay.deliver(context.Background(), msg)
+	assert.Equal(t, []string{"http: connection refused"}, failures)
+	assert.Equal(t, []string{"log"}, msg.DeliveredSinks)
+
+	failed.err = nil
+
+	failures = relay.deliver(context.Background(), msg)
+	assert.Empty(t, failures)
+	assert.Equal(t, []string{"log", "http"}, msg.DeliveredSinks)
+	assert.Equal(t, 1, accepted.calls, "the accepting sink must not receive the message again")
+	assert.Equal(t, 2, failed.calls)
+}
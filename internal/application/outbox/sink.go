@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// sinkTimeout limits the time of the single webhook delivery.
+const sinkTimeout = 10 * time.Second
+
+// Sink is the interface that describes a destination of the outbox messages.
+// Name identifies the sink in the outbox, so the message isn't delivered again to the sinks which accepted it.
+// Deliver may still be called several times for the same message, sinks should drop duplicates by the message ID.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, msg *model.OutboxMessage) error
+}
+
+// LogSink writes the messages to the application logger.
+type LogSink struct{}
+
+// Name returns the name of the sink.
+func (LogSink) Name() string {
+	return "log"
+}
+
+// Deliver logs the message.
+func (LogSink) Deliver(ctx context.Context, msg *model.OutboxMessage) error {
+	logging.FromContext(ctx).Info().
+		Int64("event_id", msg.ID).
+		Str("type", msg.Type).
+		Int("cart_id", msg.CartID).
+		RawJSON("payload", msg.Payload).
+		Msg("Domain event")
+
+	return nil
+}
+
+// FileSink appends the messages to the file as json lines.
+type FileSink struct {
+	Path string // Path of the file
+
+	mu sync.Mutex
+}
+
+// NewFileSink is a constructor for FileSink struct.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{Path: path}
+}
+
+// Name returns the name of the sink.
+func (*FileSink) Name() string {
+	return "file"
+}
+
+// Deliver appends the message to the file.
+func (fs *FileSink) Deliver(_ context.Context, msg *model.OutboxMessage) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := os.OpenFile(fs.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// HTTPSink posts the messages as json to the webhook URL.
+type HTTPSink struct {
+	URL    string       // URL of the webhook
+	Client *http.Client // HTTP client used for the delivery
+}
+
+// NewHTTPSink is a constructor for HTTPSink struct.
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	return &HTTPSink{URL: url, Client: client}
+}
+
+// Name returns the name of the sink.
+func (*HTTPSink) Name() string {
+	return "http"
+}
+
+// Deliver posts the message to the webhook.
+// The message ID is sent in the Idempotency-Key header and the trace context in the traceparent header.
+// Returns an error if the webhook doesn't respond with 2xx status.
+func (hs *HTTPSink) Deliver(ctx context.Context, msg *model.OutboxMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatInt(msg.ID, 10))
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := hs.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// NewSinks builds the sinks by their names: log, file or http.
+// Returns an error if the name is unknown or repeated or the sink settings are missing.
+func NewSinks(names []string, filePath, webhookURL string) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("outbox sink %q is repeated", name)
+		}
+
+		seen[name] = true
+
+		switch name {
+		case "log":
+			sinks = append(sinks, LogSink{})
+		case "file":
+			if filePath == "" {
+				return nil, fmt.Errorf("file sink requires the file path")
+			}
+
+			sinks = append(sinks, NewFileSink(filePath))
+		case "http":
+			if webhookURL == "" {
+				return nil, fmt.Errorf("http sink requires the webhook URL")
+			}
+
+			sinks = append(sinks, NewHTTPSink(webhookURL, &http.Client{Timeout: sinkTimeout}))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+
+	return sinks, nil
+}
@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() *model.OutboxMessage {
+	return &model.OutboxMessage{
+		ID:      42,
+		Type:    model.EventItemAdded,
+		CartID:  1,
+		Payload: json.RawMessage(`{"cart_id":1,"item_id":3,"product":"Hat","quantity":1}`),
+	}
+}
+
+func TestHTTPSink_Deliver(t *testing.T) {
+	var received model.OutboxMessage
+
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "42", r.Header.Get("Idempotency-Key"))
+
+		err := json.NewDecoder(r.Body).Decode(&received)
+		assert.NoError(t, err)
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, server.Client())
+
+	err := sink.Deliver(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, model.EventItemAdded, received.Type)
+
+	status = http.StatusServiceUnavailable
+
+	err = sink.Deliver(context.Background(), testMessage())
+	assert.Error(t, err)
+}
+
+func TestFileSink_Deliver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileSink(path)
+
+	for i := 0; i < 2; i++ {
+		err := sink.Deliver(context.Background(), testMessage())
+		require.NoError(t, err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+}
+
+func TestNewSinks(t *testing.T) {
+	sinks, err := NewSinks([]string{"log", "file"}, "events.jsonl", "")
+	require.NoError(t, err)
+	assert.Len(t, sinks, 2)
+
+	_, err = NewSinks([]string{"http"}, "", "")
+	assert.Error(t, err)
+
+	_, err = NewSinks([]string{"kafka"}, "", "")
+	assert.Error(t, err)
+
+	_, err = NewSinks([]string{"log", "log"}, "", "")
+	assert.Error(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	tt := []struct {
+		attempts       int
+		expectedResult time.Duration
+	}{
+		{attempts: 0, expectedResult: time.Second},
+		{attempts: 3, expectedResult: 8 * time.Second},
+		{attempts: 30, expectedResult: time.Minute},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.expectedResult, Backoff(tc.attempts, time.Minute))
+	}
+}
@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
+
+// subscriptionBuffer is a number of events buffered for every subscriber.
+// Events for the subscriber with a full buffer are dropped so a slow client can't block publishers.
+const subscriptionBuffer = 16
+
+// Publisher is the interface that describes publishing of the cart events.
+type Publisher interface {
+	Publish(ctx context.Context, event *model.CartEvent) error
+}
+
+// Subscriber is the interface that describes subscription to the events of a single cart.
+// The returned func cancels the subscription and closes the channel.
+type Subscriber interface {
+	Subscribe(cartID int) (<-chan model.CartEvent, func())
+}
+
+// Hub is an in-process pub/sub which delivers the cart events to the subscribers of the cart.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[int]map[chan model.CartEvent]struct{}
+}
+
+// NewHub is a constructor for Hub struct.
+func NewHub() *Hub {
+	return &Hub{subs: map[int]map[chan model.CartEvent]struct{}{}}
+}
+
+// Publish delivers the event to all the subscribers of the cart.
+func (h *Hub) Publish(_ context.Context, event *model.CartEvent) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subs[event.CartID] {
+		select {
+		case ch <- *event:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the events of the cart.
+func (h *Hub) Subscribe(cartID int) (<-chan model.CartEvent, func()) {
+	ch := make(chan model.CartEvent, subscriptionBuffer)
+
+	h.mu.Lock()
+
+	if h.subs[cartID] == nil {
+		h.subs[cartID] = map[chan model.CartEvent]struct{}{}
+	}
+
+	h.subs[cartID][ch] = struct{}{}
+
+	h.mu.Unlock()
+
+	var once sync.Once
+
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(h.subs[cartID], ch)
+
+			if len(h.subs[cartID]) == 0 {
+				delete(h.subs, cartID)
+			}
+
+			close(ch)
+		})
+	}
+
+	return ch, cancel
+}
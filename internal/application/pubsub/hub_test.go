@@ -0,0 +1,44 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	first, cancelFirst := hub.Subscribe(1)
+	second, cancelSecond := hub.Subscribe(1)
+	other, cancelOther := hub.Subscribe(2)
+
+	defer cancelSecond()
+	defer cancelOther()
+
+	event := model.CartEvent{Type: model.CartEventItemAdded, CartID: 1, Item: model.CartItem{ID: 3, CartID: 1}}
+
+	err := hub.Publish(context.Background(), &event)
+	require.NoError(t, err)
+
+	assert.Equal(t, event, <-first)
+	assert.Equal(t, event, <-second)
+	assert.Len(t, other, 0)
+
+	cancelFirst()
+	cancelFirst()
+
+	_, ok := <-first
+	assert.False(t, ok)
+
+	for i := 0; i < subscriptionBuffer+1; i++ {
+		err = hub.Publish(context.Background(), &event)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, second, subscriptionBuffer)
+}
@@ -0,0 +1,49 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+)
+
+// relayRetryDelay is how long Relay waits before listening again after the connection is lost.
+const relayRetryDelay = time.Second
+
+// NotifyPublisher publishes the cart events with Postgres NOTIFY so every replica receives them.
+type NotifyPublisher struct {
+	Pool *postgres.Pool // connection pool
+}
+
+// NewNotifyPublisher is a constructor for NotifyPublisher struct.
+func NewNotifyPublisher(pool *postgres.Pool) *NotifyPublisher {
+	return &NotifyPublisher{Pool: pool}
+}
+
+// Publish sends the event to the Postgres events channel.
+func (np NotifyPublisher) Publish(ctx context.Context, event *model.CartEvent) error {
+	return postgres.NotifyEvent(ctx, np.Pool, event)
+}
+
+// Relay listens to the Postgres events channel and publishes received events to the hub
+// until the context is canceled. Listening is restarted if the connection is lost.
+func Relay(ctx context.Context, pool *postgres.Pool, hub *Hub) {
+	for {
+		err := postgres.ListenEvents(ctx, pool, func(event *model.CartEvent) {
+			_ = hub.Publish(ctx, event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		logging.FromContext(ctx).Error().Err(err).Msg("Listen cart events error")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetryDelay):
+		}
+	}
+}
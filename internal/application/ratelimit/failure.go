@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+)
+
+// failureKey is a prefix of the keys of the buckets of the failed authentications.
+const failureKey = "authFailure|ip:"
+
+// FailureLimiter throttles the clients which fail the authentication, so the credentials can't be guessed.
+// Every failure takes a token from the bucket of the client IP, and the client which runs out of them
+// is rejected without checking its credentials until a token is refilled.
+// The HTTP and gRPC servers share the limiter, so the client is blocked on both.
+// A nil limiter doesn't throttle the failures.
+type FailureLimiter struct {
+	Store Store // keeps the buckets of the failures
+	Limit Limit // number of the failures allowed per period
+
+	mu      sync.Mutex
+	blocked map[string]time.Time // client IPs mapped to the time when they may retry
+}
+
+// NewFailureLimiter is a constructor for FailureLimiter struct.
+func NewFailureLimiter(store Store, limit Limit) *FailureLimiter {
+	return &FailureLimiter{Store: store, Limit: limit, blocked: map[string]time.Time{}}
+}
+
+// RetryAfter returns how long the client with the IP is blocked, 0 if it isn't.
+func (l *FailureLimiter) RetryAfter(ip string) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Until(l.blocked[ip])
+}
+
+// Fail records the failed authentication of the client with the IP and blocks the client if it ran out of tokens.
+// The failures are allowed if the store fails, so the limiter doesn't take the API down.
+func (l *FailureLimiter) Fail(ctx context.Context, ip string) {
+	if l == nil {
+		return
+	}
+
+	res, err := l.Store.Take(ctx, failureKey+ip, l.Limit)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("Rate limit authentication failure error")
+
+		return
+	}
+
+	if res.Allowed {
+		return
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for k, until := range l.blocked {
+		if !until.After(now) {
+			delete(l.blocked, k)
+		}
+	}
+
+	l.blocked[ip] = now.Add(res.RetryAfter)
+}
@@ -0,0 +1,123 @@
+// Package ratelimit limits the rate of the requests of the clients with the token buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRoute is a name of the limit applied to the routes without their own limit.
+const DefaultRoute = "default"
+
+// Limit allows Requests per Period with bursts of up to Requests.
+// The bucket holds up to Requests tokens and is refilled evenly during the Period.
+type Limit struct {
+	Requests int           // Capacity of the bucket
+	Period   time.Duration // Time in which the empty bucket is refilled
+}
+
+// rate returns the number of tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formats the limit as <requests>/<period>.
+func (l Limit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// ParseLimit parses the limit in the <requests>/<period> format, e.g. 10/1m.
+// Returns an error if the requests or the period aren't positive.
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("rate limit %q must be <requests>/<period>", s)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must have positive number of requests", s)
+	}
+
+	period, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must have positive period", s)
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// ParseLimits parses the limits of the routes keyed by the route name.
+// Returns an error if a limit can't be parsed.
+func ParseLimits(raw map[string]string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(raw))
+
+	for route, s := range raw {
+		l, err := ParseLimit(s)
+		if err != nil {
+			return nil, err
+		}
+
+		limits[route] = l
+	}
+
+	return limits, nil
+}
+
+// Result is an outcome of taking a token from the bucket.
+type Result struct {
+	Allowed    bool          // Allowed reports whether the token was taken
+	Limit      int           // Capacity of the bucket
+	Remaining  int           // Whole tokens left in the bucket
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next token, zero if the request is allowed
+}
+
+// Store is the interface that keeps the token buckets.
+// Take takes a token from the bucket of the key refilling it according to the limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is a state of the token bucket.
+type Bucket struct {
+	Tokens    float64   // Tokens left in the bucket
+	UpdatedAt time.Time // Time when the tokens were counted
+}
+
+// Take refills the bucket up to the time now and takes a token if there is one.
+// A new bucket with zero UpdatedAt is full.
+func (b *Bucket) Take(now time.Time, limit Limit) Result {
+	capacity := float64(limit.Requests)
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed*limit.rate())
+	}
+
+	b.UpdatedAt = now
+
+	res := Result{Limit: limit.Requests}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.Tokens) / limit.rate())
+	}
+
+	res.Remaining = int(b.Tokens)
+	res.Reset = seconds((capacity - b.Tokens) / limit.rate())
+
+	return res
+}
+
+// seconds converts the number of seconds to the duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tt := []struct {
+		name          string
+		raw           string
+		expected      Limit
+		expectedError bool
+	}{
+		{name: "Per minute", raw: "10/1m", expected: Limit{Requests: 10, Period: time.Minute}},
+		{name: "Spaces", raw: " 5 / 30s ", expected: Limit{Requests: 5, Period: 30 * time.Second}},
+		{name: "No period", raw: "10", expectedError: true},
+		{name: "Zero requests", raw: "0/1m", expectedError: true},
+		{name: "Invalid period", raw: "10/minute", expectedError: true},
+		{name: "Negative period", raw: "10/-1s", expectedError: true},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := ParseLimit(tc.raw)
+			if tc.expectedError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, l)
+		})
+	}
+}
+
+func TestBucket_Take(t *testing.T) {
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var b Bucket
+
+	res := b.Take(start, limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+
+	res = b.Take(start, limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res = b.Take(start.Add(500*time.Millisecond), limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, res.Reset)
+
+	res = b.Take(start.Add(time.Second), limit)
+	assert.True(t, res.Allowed)
+
+	res = b.Take(start.Add(time.Hour), limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining, "the bucket isn't filled over the capacity")
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	ms := NewMemoryStore()
+	ms.now = func() time.Time { return now }
+
+	res, err := ms.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = ms.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	res, err = ms.Take(context.Background(), "bob", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "the clients have their own buckets")
+
+	now = now.Add(2 * DefaultIdleTTL)
+
+	_, err = ms.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	assert.Len(t, ms.buckets, 1, "idle buckets are dropped")
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTTL is how long the idle buckets are kept.
+const DefaultIdleTTL = time.Hour
+
+// MemoryStore keeps the token buckets in the memory of the process.
+// The limits are enforced per replica.
+type MemoryStore struct {
+	IdleTTL time.Duration // how long the idle buckets are kept
+
+	mu       sync.Mutex
+	buckets  map[string]*Bucket
+	now      func() time.Time
+	prunedAt time.Time
+}
+
+// NewMemoryStore is a constructor for MemoryStore struct.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{IdleTTL: DefaultIdleTTL, buckets: map[string]*Bucket{}, now: time.Now}
+}
+
+// Take takes a token from the bucket of the key.
+// The buckets idle for longer than IdleTTL are dropped, at most once per IdleTTL.
+func (ms *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+
+	if now.Sub(ms.prunedAt) > ms.IdleTTL {
+		for k, b := range ms.buckets {
+			if now.Sub(b.UpdatedAt) > ms.IdleTTL {
+				delete(ms.buckets, k)
+			}
+		}
+
+		ms.prunedAt = now
+	}
+
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &Bucket{}
+		ms.buckets[key] = b
+	}
+
+	return b.Take(now, limit), nil
+}
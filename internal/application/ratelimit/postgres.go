@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// PostgresStore keeps the token buckets in the rate_limits table, so the limits are shared by all the replicas.
+// Every request takes a token within a short transaction which locks the bucket of the client.
+type PostgresStore struct {
+	Pool    *postgres.Pool // connection pool
+	IdleTTL time.Duration  // how long the idle buckets are kept
+}
+
+// NewPostgresStore is a constructor for PostgresStore struct.
+func NewPostgresStore(pool *postgres.Pool) *PostgresStore {
+	return &PostgresStore{Pool: pool, IdleTTL: DefaultIdleTTL}
+}
+
+// Take takes a token from the bucket of the key, the transaction is retried on the transient failures.
+// Returns an error if the bucket can't be locked or updated.
+func (ps *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var res Result
+
+	err := postgres.RetryTx(ctx, ps.Pool, func(tx pgx.Tx) error {
+		tokens, updatedAt, now, err := postgres.LockRateBucket(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		b := Bucket{Tokens: tokens, UpdatedAt: updatedAt}
+		res = b.Take(now, limit)
+
+		return postgres.SaveRateBucket(ctx, tx, key, b.Tokens, b.UpdatedAt)
+	})
+
+	return res, err
+}
+
+// Run deletes the idle buckets every IdleTTL until the context is canceled.
+func (ps *PostgresStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(ps.IdleTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := postgres.DeleteIdleRateBuckets(ctx, ps.Pool, ps.IdleTTL)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Delete idle rate limit buckets error")
+		}
+	}
+}
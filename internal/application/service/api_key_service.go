@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+
+	"github.com/pkg/errors"
+)
+
+// APIKeyTouchInterval is how often the last used time of the API key is updated.
+const APIKeyTouchInterval = time.Minute
+
+// APIKeys is the interface that describes methods for managing the API keys of the server-to-server clients.
+type APIKeys interface {
+	auth.APIKeyVerifier
+
+	CreateAPIKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	RotateAPIKey(ctx context.Context, id int) (*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+}
+
+// APIKeyService represents service layer of the API keys.
+// All the methods except VerifyAPIKey return ErrAccessDenied error unless the policy allows the caller to manage the API keys.
+type APIKeyService struct {
+	Pool *postgres.Pool // connection pool
+}
+
+// NewAPIKeyService is a constructor for APIKeyService struct.
+func NewAPIKeyService(pool *postgres.Pool) *APIKeyService {
+	return &APIKeyService{Pool: pool}
+}
+
+// ValidateAPIKey validates the name, the scopes, the roles and the expiry of the API key.
+// Returns ErrInvalidAPIKey error if the name is blank, a scope or a role is unknown
+// or the key expires in the past.
+func ValidateAPIKey(k *model.APIKey) error {
+	if strings.TrimSpace(k.Name) == "" {
+		return errors.Wrap(e.ErrInvalidAPIKey, "name must not be blank")
+	}
+
+	if len(k.Scopes) == 0 {
+		return errors.Wrap(e.ErrInvalidAPIKey, "at least one scope is required")
+	}
+
+	for _, scope := range k.Scopes {
+		if !auth.KnownScope(scope) {
+			return errors.Wrapf(e.ErrInvalidAPIKey, "unknown scope %q", scope)
+		}
+	}
+
+	for _, role := range k.Roles {
+		if !auth.KnownRole(role) {
+			return errors.Wrapf(e.ErrInvalidAPIKey, "unknown role %q", role)
+		}
+	}
+
+	if k.Expired(time.Now()) {
+		return errors.Wrap(e.ErrInvalidAPIKey, "expiry must be in the future")
+	}
+
+	return nil
+}
+
+// CreateAPIKey creates a new API key with a random key.
+// Returns a pointer to the API key model including the plain key which isn't stored.
+// Also it returns an error if the API key is invalid or a database error if it can't be inserted.
+func (ks APIKeyService) CreateAPIKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error) {
+	_, err := actorFrom(ctx, OpManageAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateAPIKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created := *k
+	if created.Roles == nil {
+		created.Roles = []string{}
+	}
+
+	created.Prefix = auth.APIKeyPrefix(key)
+
+	created.ID, err = postgres.InsertAPIKey(ctx, ks.Pool, &created, auth.HashAPIKey(key))
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	return ks.getAPIKey(ctx, created.ID, key)
+}
+
+// getAPIKey gets the API key with the ID and sets the plain key.
+func (ks APIKeyService) getAPIKey(ctx context.Context, id int, key string) (*model.APIKey, error) {
+	k, err := postgres.GetAPIKey(ctx, ks.Pool, id)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if k == nil {
+		return nil, e.ErrAPIKeyNotFound
+	}
+
+	k.Key = key
+
+	return k, nil
+}
+
+// ListAPIKeys lists all the API keys including the revoked ones, the plain keys aren't set.
+// Also it returns a database error if the API keys can't be selected.
+func (ks APIKeyService) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	_, err := actorFrom(ctx, OpManageAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := postgres.ListAPIKeys(ctx, ks.Pool)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	return keys, nil
+}
+
+// RotateAPIKey replaces the key of the API key keeping its name, scopes and expiry.
+// The previous key is rejected immediately.
+// Returns a pointer to the API key model including the new plain key.
+// Also it returns ErrAPIKeyNotFound error if the API key doesn't exist or is revoked.
+func (ks APIKeyService) RotateAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	_, err := actorFrom(ctx, OpManageAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := postgres.RotateAPIKey(ctx, ks.Pool, id, auth.APIKeyPrefix(key), auth.HashAPIKey(key))
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if !found {
+		return nil, e.ErrAPIKeyNotFound
+	}
+
+	return ks.getAPIKey(ctx, id, key)
+}
+
+// RevokeAPIKey revokes the API key, the revoked keys are kept for the audit.
+// Returns ErrAPIKeyNotFound error if the API key doesn't exist or is already revoked.
+func (ks APIKeyService) RevokeAPIKey(ctx context.Context, id int) error {
+	_, err := actorFrom(ctx, OpManageAPIKeys)
+	if err != nil {
+		return err
+	}
+
+	found, err := postgres.RevokeAPIKey(ctx, ks.Pool, id)
+	if err != nil {
+		return errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if !found {
+		return e.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// VerifyAPIKey authenticates the caller by the plain key.
+// Returns the principal with the ID of the key as the subject and the scopes and the roles of the key,
+// the names of the keys aren't unique.
+// The last used time of the key is updated at most once per APIKeyTouchInterval.
+// Also it returns ErrUnauthenticated error if the key is unknown, revoked or expired.
+func (ks APIKeyService) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	k, err := postgres.GetActiveAPIKeyByHash(ctx, ks.Pool, auth.HashAPIKey(key))
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if k == nil {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "unknown api key")
+	}
+
+	if k.Expired(time.Now()) {
+		return nil, errors.Wrap(e.ErrUnauthenticated, "api key is expired")
+	}
+
+	err = postgres.TouchAPIKey(ctx, ks.Pool, k.ID, APIKeyTouchInterval)
+	if err != nil {
+		logging.FromContext(ctx).Warn().Err(err).Int("api_key_id", k.ID).Msg("Update api key last used time error")
+	}
+
+	return &auth.Principal{Subject: "api-key:" + strconv.Itoa(k.ID), Scopes: k.Scopes, Roles: k.Roles, Method: auth.MethodAPIKey}, nil
+}
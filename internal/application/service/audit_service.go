@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+
+	"github.com/pkg/errors"
+)
+
+// Limits of the audit log page size.
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+// Audit is the interface that describes methods for querying the audit log.
+type Audit interface {
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error)
+}
+
+// AuditService represents service layer of the audit log.
+// It returns ErrAccessDenied error unless the policy allows the caller to read the audit log.
+type AuditService struct {
+	Pool *postgres.Pool // connection pool
+}
+
+// NewAuditService is a constructor for AuditService struct.
+func NewAuditService(pool *postgres.Pool) *AuditService {
+	return &AuditService{Pool: pool}
+}
+
+// auditCart is a state of the cart recorded in the audit log.
+type auditCart struct {
+	ID    int    `json:"id"`
+	Owner string `json:"owner,omitempty"`
+}
+
+// auditItem is a state of the item recorded in the audit log.
+type auditItem struct {
+	ID       int    `json:"id"`
+	CartID   int    `json:"cart_id"`
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+}
+
+// newAuditItem converts the item to its state recorded in the audit log.
+func newAuditItem(item *model.CartItem) *auditItem {
+	return &auditItem{ID: item.ID, CartID: item.CartID, Product: item.Product, Quantity: item.Quantity}
+}
+
+// recordAudit stores the audit entry of the mutation within the transaction.
+// The origin of the change is taken from the request metadata in the context.
+// Nil before or after is stored as null.
+func recordAudit(ctx context.Context, q postgres.Querier, action string, cartID int, before, after interface{}) error {
+	m := audit.MetadataFrom(ctx)
+	entry := model.AuditEntry{
+		CartID:    cartID,
+		Action:    action,
+		Actor:     m.ActorOrAnonymous(),
+		ClientIP:  m.ClientIP,
+		UserAgent: m.UserAgent,
+		RequestID: m.RequestID,
+	}
+
+	var err error
+
+	entry.Before, err = marshalState(before)
+	if err != nil {
+		return err
+	}
+
+	entry.After, err = marshalState(after)
+	if err != nil {
+		return err
+	}
+
+	return postgres.InsertAuditEntry(ctx, q, &entry)
+}
+
+// marshalState encodes the state as json, nil state is left empty.
+func marshalState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
+
+// ListAuditEntries lists the audit log entries matching the filter, newest first.
+// The limit is clamped to MaxAuditLimit, non-positive limit means DefaultAuditLimit.
+// Returns a page of entries and the cursor of the next page.
+// Also it returns an error if the cursor is invalid
+// or a database error if the entries can't be selected.
+func (as AuditService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error) {
+	_, err := actorFrom(ctx, OpReadAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultAuditLimit
+	}
+
+	if filter.Limit > MaxAuditLimit {
+		filter.Limit = MaxAuditLimit
+	}
+
+	if filter.Cursor != "" {
+		filter.BeforeID, err = decodeAuditCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	entries, err := postgres.ListAuditEntries(ctx, as.Pool, &filter)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	var page model.AuditPage
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = encodeAuditCursor(&entries[limit-1])
+	}
+
+	page.Entries = entries
+
+	return &page, nil
+}
@@ -2,71 +2,316 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/metrics"
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
 	"github.com/fedo3nik/cart-go-api/internal/domain/model"
 	e "github.com/fedo3nik/cart-go-api/internal/errors"
 	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
 
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 )
 
 // Cart is the interface that describes methods for the service layer.
 type Cart interface {
-	CreateCart(ctx context.Context) (*model.Cart, error)
+	CreateCart(ctx context.Context, owner string) (*model.Cart, error)
 	AddItem(ctx context.Context, product string, quantity, cartID int) (*model.CartItem, error)
 	RemoveItem(ctx context.Context, cartID, itemID int) error
 	GetCart(ctx context.Context, cartID int) (*model.Cart, error)
+	ListCarts(ctx context.Context, filter model.CartFilter) (*model.CartPage, error)
+	GetCartHistory(ctx context.Context, cartID int) ([]model.CartHistoryEvent, error)
+	GetCartAsOf(ctx context.Context, cartID int, asOf time.Time) (*model.Cart, error)
+	GetCartLimits(ctx context.Context, cartID int) (*model.CartLimitsReport, error)
 }
 
+// Limits of the carts list page size.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// SnapshotInterval is a number of the cart events after which a snapshot of the cart is taken.
+const SnapshotInterval = 50
+
 // CartService represents service layer.
 type CartService struct {
-	Pool *pgxpool.Pool // connection pool
+	Pool    *postgres.Pool   // connection pool
+	Events  pubsub.Publisher // publisher of the cart events, nil disables publishing
+	Limits  model.CartLimits // business limits of the carts, SetLimits replaces them at runtime
+	Metrics *metrics.Metrics // collectors of the cart metrics, nil disables them
+
+	reloaded *atomic.Value // limits set by SetLimits, shared by the copies of the service
+}
+
+// SetLimits replaces the limits of the carts while the service is serving the requests, e.g. on the config reload.
+func (c *CartService) SetLimits(limits model.CartLimits) {
+	if c.reloaded == nil {
+		c.reloaded = &atomic.Value{}
+	}
+
+	c.reloaded.Store(limits)
+}
+
+// limits returns the current limits of the carts.
+func (c CartService) limits() model.CartLimits {
+	if c.reloaded != nil {
+		if limits, ok := c.reloaded.Load().(model.CartLimits); ok {
+			return limits
+		}
+	}
+
+	return c.Limits
+}
+
+// publish publishes the event after the successful mutation.
+// Publishing errors are logged only since the mutation is already done.
+func (c CartService) publish(ctx context.Context, event *model.CartEvent) {
+	if c.Events == nil {
+		return
+	}
+
+	event.OccurredAt = time.Now().UTC()
+
+	err := c.Events.Publish(ctx, event)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", event.CartID).Msg("Publish cart event error")
+	}
+}
+
+// record stores the domain event in the outbox and appends it to the cart history within the transaction.
+// A snapshot of the cart is taken every SnapshotInterval events to keep the reconstruction fast.
+func (c CartService) record(ctx context.Context, tx pgx.Tx, event model.DomainEvent) error {
+	err := postgres.InsertOutboxEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	version, err := postgres.AppendCartEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	if version%SnapshotInterval != 0 {
+		return nil
+	}
+
+	return takeSnapshot(ctx, tx, event.EventCartID())
 }
 
-// CreateCart creates a new cart.
+// takeSnapshot folds the events since the latest snapshot and stores the new snapshot of the cart.
+func takeSnapshot(ctx context.Context, q postgres.Querier, cartID int) error {
+	snapshot, err := postgres.GetLatestSnapshot(ctx, q, cartID, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	after := 0
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+
+	events, err := postgres.ListCartEvents(ctx, q, cartID, after, time.Time{})
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	last := events[len(events)-1]
+
+	return postgres.InsertCartSnapshot(ctx, q, &model.CartSnapshot{
+		Version: last.Version,
+		TakenAt: last.OccurredAt,
+		Cart:    *model.ReplayCart(snapshot, events),
+	})
+}
+
+// CreateCart creates a new cart of the owner, blank owner creates anonymous cart.
+// Callers who may create only their own carts become the owner of the cart with blank owner.
 // Returns a pointer to the cart model.
-// Also it returns a database error if the used func InsertCart returns an error.
-func (c CartService) CreateCart(ctx context.Context) (*model.Cart, error) {
+// The CartCreated event and the audit entry are stored within the same transaction, which is retried on the transient failures.
+// Also it returns ErrAccessDenied error if the caller may not create the cart of the owner
+// or a database error if the cart or the event can't be inserted.
+func (c CartService) CreateCart(ctx context.Context, owner string) (*model.Cart, error) {
 	var cart model.Cart
 
-	id, err := postgres.InsertCart(ctx, c.Pool)
+	a, err := actorFrom(ctx, OpCreateCart)
 	if err != nil {
+		return nil, err
+	}
+
+	if owner == "" && a.access == AccessOwn {
+		owner = a.subject
+	}
+
+	err = a.check(OpCreateCart, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = postgres.RetryTx(ctx, c.Pool, func(tx pgx.Tx) error {
+		id, err := postgres.InsertCartTx(ctx, tx, owner)
+		if err != nil {
+			return err
+		}
+
+		cart.ID = id
+		cart.Owner = owner
+
+		err = c.record(ctx, tx, model.CartCreated{CartID: id, Owner: owner})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, model.AuditActionCreateCart, id, nil, &auditCart{ID: id, Owner: owner})
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Str("owner", owner).Msg("Create cart error")
+
 		return nil, errors.Wrap(e.ErrDB, err.Error())
 	}
 
-	cart.ID = id
+	c.Metrics.CartCreated()
 
 	return &cart, nil
 }
 
 // AddItem adds a new item to the cart.
 // Returns a pointer to the item model.
-// Also it returns an error if the item data is invalid or
-// the cart with the same id doesn't exist.
+// Also it returns an error if the item data is invalid, the item doesn't fit the limits of the cart,
+// the cart with the same id doesn't exist or the caller may not change the cart.
+// The cart is locked while its limits are checked, so the concurrent additions can't exceed them.
+// The ItemAdded event and the audit entry are stored within the same transaction, which is retried on the transient failures.
 func (c CartService) AddItem(ctx context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
 	err := c.ValidateItemData(product, quantity)
 	if err != nil {
-		return nil, errors.Wrap(err, err.Error())
+		return nil, err
+	}
+
+	err = c.authorizeCart(ctx, OpAddItem, cartID, e.ErrInvalidCartID)
+	if err != nil {
+		return nil, err
 	}
 
 	item := model.CartItem{Product: product, Quantity: quantity, CartID: cartID}
 
-	id, err := postgres.InsertItem(ctx, c.Pool, &item)
+	var (
+		limitErr error
+		size     int
+	)
+
+	err = postgres.RetryTx(ctx, c.Pool, func(tx pgx.Tx) error {
+		limitErr = nil
+
+		if limits := c.limits(); limits.Enforced() {
+			items, found, err := postgres.LockCartItemsTx(ctx, tx, cartID)
+			if err != nil {
+				return err
+			}
+
+			limitErr = e.ErrInvalidCartID
+			if found {
+				limitErr = c.checkCartLimits(items, product, quantity)
+			}
+
+			if limitErr != nil {
+				return limitErr
+			}
+		}
+
+		var err error
+
+		item.ID, err = postgres.InsertItemTx(ctx, tx, &item)
+		if err != nil {
+			return err
+		}
+
+		err = c.record(ctx, tx, model.ItemAdded{CartID: cartID, ItemID: item.ID, Product: product, Quantity: quantity})
+		if err != nil {
+			return err
+		}
+
+		if c.Metrics != nil {
+			size, err = postgres.CountItemsTx(ctx, tx, cartID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, tx, model.AuditActionAddItem, cartID, nil, newAuditItem(&item))
+	})
+	if limitErr != nil {
+		return nil, limitErr
+	}
+
+	// The cart is deleted or never existed if the item can't reference it.
+	if postgres.IsForeignKeyViolation(err) {
+		return nil, e.ErrInvalidCartID
+	}
+
 	if err != nil {
-		return nil, errors.Wrap(e.ErrInvalidCartID, err.Error())
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Str("product", product).Msg("Add item error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
 	}
 
-	item.ID = id
+	c.Metrics.ItemAdded(size)
+	c.publish(ctx, &model.CartEvent{Type: model.CartEventItemAdded, CartID: cartID, Item: item})
 
 	return &item, nil
 }
 
 // RemoveItem removes item from the cart.
-// Returns an error if cart or item with the received IDs doesn't exist.
+// The ItemRemoved event and the audit entry with the removed item are stored within the same transaction,
+// which is retried on the transient failures.
+// Returns an error if cart or item with the received IDs doesn't exist or the caller may not change the cart.
 func (c CartService) RemoveItem(ctx context.Context, cartID, itemID int) error {
-	flag, err := postgres.DeleteItem(ctx, c.Pool, cartID, itemID)
+	var (
+		flag bool
+		size int
+	)
+
+	err := c.authorizeCart(ctx, OpRemoveItem, cartID, e.ErrRemove)
+	if err != nil {
+		return err
+	}
+
+	err = postgres.RetryTx(ctx, c.Pool, func(tx pgx.Tx) error {
+		before, err := postgres.GetItemTx(ctx, tx, cartID, itemID)
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			flag = true
+
+			return nil
+		}
+
+		flag, err = postgres.DeleteItemTx(ctx, tx, cartID, itemID)
+		if err != nil || flag {
+			return err
+		}
+
+		err = c.record(ctx, tx, model.ItemRemoved{CartID: cartID, ItemID: itemID})
+		if err != nil {
+			return err
+		}
+
+		if c.Metrics != nil {
+			size, err = postgres.CountItemsTx(ctx, tx, cartID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, tx, model.AuditActionRemoveItem, cartID, newAuditItem(before), nil)
+	})
 	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Int("item_id", itemID).Msg("Remove item error")
+
 		return errors.Wrap(e.ErrDB, err.Error())
 	}
 
@@ -74,15 +319,26 @@ func (c CartService) RemoveItem(ctx context.Context, cartID, itemID int) error {
 		return e.ErrRemove
 	}
 
+	c.Metrics.ItemRemoved(size)
+
+	c.publish(ctx, &model.CartEvent{Type: model.CartEventItemRemoved, CartID: cartID, Item: model.CartItem{ID: itemID, CartID: cartID}})
+
 	return nil
 }
 
 // GetCart gets the data about the cart with the ID == cartID.
 // Returns a pointer to the cart model.
-// Also it returns an error if the cart with the same ID doesn't exist.
+// Also it returns an error if the cart with the same ID doesn't exist or the caller may not view it.
 func (c CartService) GetCart(ctx context.Context, cartID int) (*model.Cart, error) {
+	err := c.authorizeCart(ctx, OpGetCart, cartID, e.ErrInvalidCartID)
+	if err != nil {
+		return nil, err
+	}
+
 	cart, err := postgres.GetCart(ctx, c.Pool, cartID)
 	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Msg("Get cart error")
+
 		return nil, errors.Wrap(e.ErrDB, err.Error())
 	}
 
@@ -93,7 +349,167 @@ func (c CartService) GetCart(ctx context.Context, cartID int) (*model.Cart, erro
 	return cart, nil
 }
 
+// ListCarts lists the carts matching the filter with their items.
+// Callers who may view only their own carts get only their carts.
+// Returns a page of carts and the cursor of the next page.
+// Also it returns an error if the sort field or the cursor is invalid,
+// the filter asks for the carts of someone else the caller may not view
+// or a database error if the carts can't be selected.
+func (c CartService) ListCarts(ctx context.Context, filter model.CartFilter) (*model.CartPage, error) {
+	var after *model.CartKey
+
+	a, err := actorFrom(ctx, OpListCarts)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.access == AccessOwn {
+		if filter.Owner == "" {
+			filter.Owner = a.subject
+		}
+
+		err = a.check(OpListCarts, filter.Owner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = model.CartSortID
+	}
+
+	if filter.Sort != model.CartSortID && filter.Sort != model.CartSortCreatedAt && filter.Sort != model.CartSortUpdatedAt {
+		return nil, e.ErrInvalidFilter
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+
+	if filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+
+	if filter.Cursor != "" {
+		key, err := decodeCursor(&filter, filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		after = key
+	}
+
+	carts, err := postgres.ListCarts(ctx, c.Pool, &filter, after, filter.Limit+1)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("List carts error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	var page model.CartPage
+
+	if len(carts) > filter.Limit {
+		carts = carts[:filter.Limit]
+		page.NextCursor = encodeCursor(&filter, &carts[len(carts)-1])
+	}
+
+	ids := make([]int, 0, len(carts))
+	for i := range carts {
+		ids = append(ids, carts[i].ID)
+	}
+
+	items, err := postgres.GetItemsByCartIDs(ctx, c.Pool, ids)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Ints("cart_ids", ids).Msg("List cart items error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	for i := range carts {
+		carts[i].Items = items[carts[i].ID]
+		if carts[i].Items == nil {
+			carts[i].Items = []model.CartItem{}
+		}
+	}
+
+	page.Carts = carts
+
+	return &page, nil
+}
+
+// GetCartHistory gets the events of the cart ordered by version.
+// Also it returns an error if the cart with the same ID doesn't exist or the caller may not view it.
+func (c CartService) GetCartHistory(ctx context.Context, cartID int) ([]model.CartHistoryEvent, error) {
+	err := c.authorizeCart(ctx, OpGetCartHistory, cartID, e.ErrInvalidCartID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := postgres.ListCartEvents(ctx, c.Pool, cartID, 0, time.Time{})
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Msg("Get cart history error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if len(events) == 0 {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return events, nil
+}
+
+// GetCartAsOf rebuilds the cart as it was at the time asOf by folding its events
+// on top of the latest snapshot taken before that time.
+// Returns a pointer to the cart model.
+// Also it returns an error if the cart with the same ID didn't exist at that time or the caller may not view it.
+func (c CartService) GetCartAsOf(ctx context.Context, cartID int, asOf time.Time) (*model.Cart, error) {
+	err := c.authorizeCart(ctx, OpGetCartHistory, cartID, e.ErrInvalidCartID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := postgres.GetLatestSnapshot(ctx, c.Pool, cartID, asOf)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Time("as_of", asOf).Msg("Get cart snapshot error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	after := 0
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+
+	events, err := postgres.ListCartEvents(ctx, c.Pool, cartID, after, asOf)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Time("as_of", asOf).Msg("Get cart history error")
+
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	cart := model.ReplayCart(snapshot, events)
+	if cart == nil {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return cart, nil
+}
+
+// GetCartLimits reports the usage of the limits by the cart so the clients can show the remaining capacity.
+// Also it returns an error if the cart with the same ID doesn't exist or the caller may not view it.
+func (c CartService) GetCartLimits(ctx context.Context, cartID int) (*model.CartLimitsReport, error) {
+	cart, err := c.GetCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := c.limits()
+
+	return limits.Report(cart), nil
+}
+
 // NewCartService is a constructor for CartService struct.
-func NewCartService(pool *pgxpool.Pool) *CartService {
-	return &CartService{Pool: pool}
+func NewCartService(pool *postgres.Pool) *CartService {
+	return &CartService{Pool: pool, reloaded: &atomic.Value{}}
 }
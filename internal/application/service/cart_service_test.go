@@ -6,6 +6,7 @@ import (
 
 	"github.com/fedo3nik/cart-go-api/internal/config"
 	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -50,9 +51,11 @@ func TestCartService_AddItem(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	cs := NewCartService(pool)
 
 	for _, tc := range tt {
@@ -94,9 +97,11 @@ func TestCartService_RemoveItem(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	cs := NewCartService(pool)
 
 	for _, tc := range tt {
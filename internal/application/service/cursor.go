@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+)
+
+// auditCursorSort is the sort of the cursors of the audit log pages, the entries are listed by ID descending.
+const auditCursorSort = "audit"
+
+// pageCursor is a payload of the opaque pagination cursor.
+// Sort and order are kept to reject cursors reused with another sorting or another list.
+type pageCursor struct {
+	Sort string    `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	ID   int64     `json:"id"`
+	Time time.Time `json:"t,omitempty"`
+}
+
+// encode encodes the cursor as an opaque string.
+func (c *pageCursor) encode() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseCursor decodes the cursor issued for the sort and the order.
+// Returns ErrInvalidCursor if the cursor is malformed or was issued for another sorting.
+func parseCursor(cursor, sort string, desc bool) (*pageCursor, error) {
+	var c pageCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, e.ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, e.ErrInvalidCursor
+	}
+
+	if c.Sort != sort || c.Desc != desc {
+		return nil, e.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// encodeCursor encodes the position of the cart in the list sorted as described by the filter.
+func encodeCursor(f *model.CartFilter, cart *model.Cart) string {
+	c := pageCursor{Sort: f.Sort, Desc: f.Desc, ID: int64(cart.ID)}
+
+	switch f.Sort {
+	case model.CartSortCreatedAt:
+		c.Time = cart.CreatedAt
+	case model.CartSortUpdatedAt:
+		c.Time = cart.UpdatedAt
+	}
+
+	return c.encode()
+}
+
+// decodeCursor decodes the cursor into the key of the last cart of the previous page.
+// Returns ErrInvalidCursor if the cursor is malformed or was issued for another sorting.
+func decodeCursor(f *model.CartFilter, cursor string) (*model.CartKey, error) {
+	c, err := parseCursor(cursor, f.Sort, f.Desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CartKey{ID: int(c.ID), Time: c.Time}, nil
+}
+
+// encodeAuditCursor encodes the position of the entry in the audit log.
+func encodeAuditCursor(entry *model.AuditEntry) string {
+	c := pageCursor{Sort: auditCursorSort, Desc: true, ID: entry.ID}
+
+	return c.encode()
+}
+
+// decodeAuditCursor decodes the cursor into the ID of the last entry of the previous page.
+// Returns ErrInvalidCursor if the cursor is malformed or was issued for another list.
+func decodeAuditCursor(cursor string) (int64, error) {
+	c, err := parseCursor(cursor, auditCursorSort, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.ID <= 0 {
+		return 0, e.ErrInvalidCursor
+	}
+
+	return c.ID, nil
+}
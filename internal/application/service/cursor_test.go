@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	updated := time.Date(2021, time.May, 3, 14, 3, 0, 0, time.UTC)
+	cart := model.Cart{ID: 7, UpdatedAt: updated}
+
+	tt := []struct {
+		name          string
+		issuedFor     model.CartFilter
+		usedFor       model.CartFilter
+		expectedKey   *model.CartKey
+		expectedError error
+	}{
+		{
+			name:        "Sort by ID",
+			issuedFor:   model.CartFilter{Sort: model.CartSortID},
+			usedFor:     model.CartFilter{Sort: model.CartSortID},
+			expectedKey: &model.CartKey{ID: 7},
+		},
+		{
+			name:        "Sort by update time descending",
+			issuedFor:   model.CartFilter{Sort: model.CartSortUpdatedAt, Desc: true},
+			usedFor:     model.CartFilter{Sort: model.CartSortUpdatedAt, Desc: true},
+			expectedKey: &model.CartKey{ID: 7, Time: updated},
+		},
+		{
+			name:          "Cursor reused with another sorting",
+			issuedFor:     model.CartFilter{Sort: model.CartSortUpdatedAt},
+			usedFor:       model.CartFilter{Sort: model.CartSortCreatedAt},
+			expectedError: e.ErrInvalidCursor,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := encodeCursor(&tc.issuedFor, &cart)
+			require.NotEmpty(t, cursor)
+
+			key, err := decodeCursor(&tc.usedFor, cursor)
+			if err != nil {
+				assert.Equal(t, tc.expectedError, err)
+				return
+			}
+
+			assert.Equal(t, tc.expectedKey.ID, key.ID)
+			assert.True(t, tc.expectedKey.Time.Equal(key.Time))
+		})
+	}
+
+	_, err := decodeCursor(&model.CartFilter{}, "not a cursor")
+	assert.Equal(t, e.ErrInvalidCursor, err)
+}
+
+func TestAuditCursor(t *testing.T) {
+	cursor := encodeAuditCursor(&model.AuditEntry{ID: 12})
+	require.NotEmpty(t, cursor)
+
+	id, err := decodeAuditCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), id)
+
+	tt := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "Raw ID", cursor: "12"},
+		{name: "Cart cursor", cursor: encodeCursor(&model.CartFilter{Sort: model.CartSortID, Desc: true}, &model.Cart{ID: 12})},
+		{name: "Without ID", cursor: (&pageCursor{Sort: auditCursorSort, Desc: true}).encode()},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decodeAuditCursor(tc.cursor)
+			assert.Equal(t, e.ErrInvalidCursor, err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+
+	"github.com/pkg/errors"
+)
+
+// Operations of the services evaluated by the policy.
+const (
+	OpCreateCart     = "create_cart"
+	OpAddItem        = "add_item"
+	OpRemoveItem     = "remove_item"
+	OpGetCart        = "get_cart"
+	OpListCarts      = "list_carts"
+	OpGetCartHistory = "get_cart_history"
+	OpReadAudit      = "read_audit"
+	OpManageAPIKeys  = "manage_api_keys"
+	OpManageWebhooks = "manage_webhooks"
+)
+
+// Access describes on which carts the role may perform the operation.
+type Access int
+
+// Levels of the access, the greater level includes the lower ones.
+const (
+	AccessNone Access = iota // the operation is denied
+	AccessOwn                // only the carts owned by the caller
+	AccessAny                // any cart
+)
+
+// Policy is a declarative table that maps the operations to the access of the roles.
+// Roles missing from the row of the operation are denied.
+var Policy = map[string]map[string]Access{
+	OpCreateCart:     {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpAddItem:        {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpRemoveItem:     {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpGetCart:        {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpListCarts:      {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpGetCartHistory: {auth.RoleShopper: AccessOwn, auth.RoleSupport: AccessAny, auth.RoleAdmin: AccessAny},
+	OpReadAudit:      {auth.RoleAdmin: AccessAny},
+	OpManageAPIKeys:  {auth.RoleAdmin: AccessAny},
+	OpManageWebhooks: {auth.RoleAdmin: AccessAny},
+}
+
+// actor is the caller of the operation evaluated by the policy.
+type actor struct {
+	subject string // Subject of the principal, empty if the policy isn't enforced
+	access  Access // The greatest access of the roles of the caller
+}
+
+// owns reports whether the cart of the owner belongs to the actor, anonymous carts belong to nobody.
+func (a actor) owns(owner string) bool {
+	return owner != "" && owner == a.subject
+}
+
+// actorFrom evaluates the access of the caller from the context to the operation.
+// Callers without roles are shoppers. All the operations are allowed if the authentication isn't enforced.
+// Returns ErrUnauthenticated error if the caller is anonymous and
+// ErrAccessDenied error if none of the roles allow the operation.
+func actorFrom(ctx context.Context, op string) (actor, error) {
+	if !auth.Enforced(ctx) {
+		return actor{access: AccessAny}, nil
+	}
+
+	p := auth.PrincipalFrom(ctx)
+	if p == nil {
+		return actor{}, e.ErrUnauthenticated
+	}
+
+	roles := p.Roles
+	if len(roles) == 0 {
+		roles = []string{auth.RoleShopper}
+	}
+
+	a := actor{subject: p.Subject}
+
+	for _, role := range roles {
+		if access := Policy[op][role]; access > a.access {
+			a.access = access
+		}
+	}
+
+	if a.access == AccessNone {
+		return actor{}, errors.Wrapf(e.ErrAccessDenied, "%s is not allowed to %s", p.Subject, op)
+	}
+
+	return a, nil
+}
+
+// check checks that the actor may perform the operation on the cart of the owner.
+// Returns ErrAccessDenied error if the actor may perform the operation only on their own carts
+// and the cart belongs to someone else.
+func (a actor) check(op, owner string) error {
+	if a.access == AccessOwn && !a.owns(owner) {
+		return errors.Wrapf(e.ErrAccessDenied, "%s is allowed to %s only on their own carts", a.subject, op)
+	}
+
+	return nil
+}
+
+// authorize checks that the caller from the context may perform the operation on the cart of the owner.
+func authorize(ctx context.Context, op, owner string) error {
+	a, err := actorFrom(ctx, op)
+	if err != nil {
+		return err
+	}
+
+	return a.check(op, owner)
+}
+
+// authorizeCart checks that the caller from the context may perform the operation on the existing cart.
+// The owner of the cart is selected only if the policy is enforced.
+// Returns the notFound error if the cart doesn't exist.
+func (c CartService) authorizeCart(ctx context.Context, op string, cartID int, notFound error) error {
+	if !auth.Enforced(ctx) {
+		return nil
+	}
+
+	owner, found, err := postgres.GetCartOwner(ctx, c.Pool, cartID)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Int("cart_id", cartID).Msg("Get cart owner error")
+
+		return errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if !found {
+		return notFound
+	}
+
+	return authorize(ctx, op, owner)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	withPrincipal := func(roles ...string) context.Context {
+		return auth.WithAuthentication(context.Background(), &auth.Principal{Subject: "alice", Roles: roles})
+	}
+
+	tt := []struct {
+		name          string
+		ctx           context.Context
+		op            string
+		owner         string
+		expectedError error
+	}{
+		{name: "Not enforced", ctx: context.Background(), op: OpAddItem, owner: "bob"},
+		{
+			name:          "Anonymous",
+			ctx:           auth.WithAuthentication(context.Background(), nil),
+			op:            OpGetCart,
+			owner:         "alice",
+			expectedError: e.ErrUnauthenticated,
+		},
+		{name: "Shopper by default on own cart", ctx: withPrincipal(), op: OpAddItem, owner: "alice"},
+		{name: "Shopper on foreign cart", ctx: withPrincipal(), op: OpGetCart, owner: "bob", expectedError: e.ErrAccessDenied},
+		{
+			name:          "Shopper on anonymous cart",
+			ctx:           withPrincipal(auth.RoleShopper),
+			op:            OpRemoveItem,
+			expectedError: e.ErrAccessDenied,
+		},
+		{name: "Support views foreign cart", ctx: withPrincipal(auth.RoleSupport), op: OpGetCartHistory, owner: "bob"},
+		{name: "Support removes from foreign cart", ctx: withPrincipal(auth.RoleSupport), op: OpRemoveItem, owner: "bob"},
+		{name: "Support adds to foreign cart", ctx: withPrincipal(auth.RoleSupport), op: OpAddItem, owner: "bob"},
+		{name: "Support adds to anonymous cart", ctx: withPrincipal(auth.RoleSupport), op: OpAddItem},
+		{name: "Greatest role wins", ctx: withPrincipal(auth.RoleShopper, auth.RoleSupport), op: OpGetCart, owner: "bob"},
+		{name: "Support reads audit", ctx: withPrincipal(auth.RoleSupport), op: OpReadAudit, expectedError: e.ErrAccessDenied},
+		{name: "Support manages API keys", ctx: withPrincipal(auth.RoleSupport), op: OpManageAPIKeys, expectedError: e.ErrAccessDenied},
+		{name: "Shopper manages webhooks", ctx: withPrincipal(), op: OpManageWebhooks, expectedError: e.ErrAccessDenied},
+		{name: "Admin manages webhooks", ctx: withPrincipal(auth.RoleAdmin), op: OpManageWebhooks},
+		{name: "Unknown role", ctx: withPrincipal("guest"), op: OpGetCart, owner: "alice", expectedError: e.ErrAccessDenied},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := authorize(tc.ctx, tc.op, tc.owner)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedError)
+			}
+		})
+	}
+}
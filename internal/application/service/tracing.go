@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of the cart spans.
+const (
+	attrCartID = attribute.Key("cart.id")
+	attrItemID = attribute.Key("cart.item.id")
+)
+
+// TracedCart wraps the Cart service so every call is traced as a span named after the method.
+type TracedCart struct {
+	next Cart
+}
+
+// NewTracedCart is a constructor for TracedCart struct.
+func NewTracedCart(next Cart) *TracedCart {
+	return &TracedCart{next: next}
+}
+
+// start starts the span of the method of the service.
+func (tc TracedCart) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "CartService."+method, trace.WithAttributes(attrs...))
+}
+
+// CreateCart is a method to trace CreateCart of the service.
+func (tc TracedCart) CreateCart(ctx context.Context, owner string) (*model.Cart, error) {
+	ctx, span := tc.start(ctx, "CreateCart")
+
+	cart, err := tc.next.CreateCart(ctx, owner)
+	if err == nil {
+		span.SetAttributes(attrCartID.Int(cart.ID))
+	}
+
+	tracing.End(span, err)
+
+	return cart, err
+}
+
+// AddItem is a method to trace AddItem of the service.
+func (tc TracedCart) AddItem(ctx context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
+	ctx, span := tc.start(ctx, "AddItem", attrCartID.Int(cartID))
+
+	item, err := tc.next.AddItem(ctx, product, quantity, cartID)
+	if err == nil {
+		span.SetAttributes(attrItemID.Int(item.ID))
+	}
+
+	tracing.End(span, err)
+
+	return item, err
+}
+
+// RemoveItem is a method to trace RemoveItem of the service.
+func (tc TracedCart) RemoveItem(ctx context.Context, cartID, itemID int) error {
+	ctx, span := tc.start(ctx, "RemoveItem", attrCartID.Int(cartID), attrItemID.Int(itemID))
+
+	err := tc.next.RemoveItem(ctx, cartID, itemID)
+	tracing.End(span, err)
+
+	return err
+}
+
+// GetCart is a method to trace GetCart of the service.
+func (tc TracedCart) GetCart(ctx context.Context, cartID int) (*model.Cart, error) {
+	ctx, span := tc.start(ctx, "GetCart", attrCartID.Int(cartID))
+
+	cart, err := tc.next.GetCart(ctx, cartID)
+	tracing.End(span, err)
+
+	return cart, err
+}
+
+// ListCarts is a method to trace ListCarts of the service.
+func (tc TracedCart) ListCarts(ctx context.Context, filter model.CartFilter) (*model.CartPage, error) {
+	ctx, span := tc.start(ctx, "ListCarts")
+
+	page, err := tc.next.ListCarts(ctx, filter)
+	tracing.End(span, err)
+
+	return page, err
+}
+
+// GetCartHistory is a method to trace GetCartHistory of the service.
+func (tc TracedCart) GetCartHistory(ctx context.Context, cartID int) ([]model.CartHistoryEvent, error) {
+	ctx, span := tc.start(ctx, "GetCartHistory", attrCartID.Int(cartID))
+
+	events, err := tc.next.GetCartHistory(ctx, cartID)
+	tracing.End(span, err)
+
+	return events, err
+}
+
+// GetCartAsOf is a method to trace GetCartAsOf of the service.
+func (tc TracedCart) GetCartAsOf(ctx context.Context, cartID int, asOf time.Time) (*model.Cart, error) {
+	ctx, span := tc.start(ctx, "GetCartAsOf", attrCartID.Int(cartID))
+
+	cart, err := tc.next.GetCartAsOf(ctx, cartID, asOf)
+	tracing.End(span, err)
+
+	return cart, err
+}
+
+// GetCartLimits is a method to trace GetCartLimits of the service.
+func (tc TracedCart) GetCartLimits(ctx context.Context, cartID int) (*model.CartLimitsReport, error) {
+	ctx, span := tc.start(ctx, "GetCartLimits", attrCartID.Int(cartID))
+
+	report, err := tc.next.GetCartLimits(ctx, cartID)
+	tracing.End(span, err)
+
+	return report, err
+}
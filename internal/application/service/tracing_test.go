@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeCart implements the methods of the Cart used by the tracing tests.
+type fakeCart struct {
+	Cart
+}
+
+func (fakeCart) AddItem(ctx context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil, e.ErrDB
+	}
+
+	return &model.CartItem{ID: 3, CartID: cartID, Product: product, Quantity: quantity}, nil
+}
+
+func (fakeCart) RemoveItem(_ context.Context, _, _ int) error {
+	return e.ErrRemove
+}
+
+func TestTracedCart(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	tc := NewTracedCart(fakeCart{})
+
+	_, err := tc.AddItem(context.Background(), "Hat", 1, 2)
+	require.NoError(t, err)
+
+	err = tc.RemoveItem(context.Background(), 2, 5)
+	require.ErrorIs(t, err, e.ErrRemove)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "CartService.AddItem", spans[0].Name)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Contains(t, spans[0].Attributes, attribute.Int("cart.id", 2))
+	assert.Contains(t, spans[0].Attributes, attribute.Int("cart.item.id", 3))
+
+	assert.Equal(t, "CartService.RemoveItem", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Contains(t, spans[1].Attributes, attribute.Int("cart.item.id", 5))
+	require.Len(t, spans[1].Events, 1)
+	assert.Equal(t, "exception", spans[1].Events[0].Name)
+}
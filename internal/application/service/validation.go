@@ -1,10 +1,19 @@
 package service
 
-import e "github.com/fedo3nik/cart-go-api/internal/errors"
+import (
+	"unicode/utf8"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/pkg/errors"
+)
 
 // ValidateItemData validate product tittle and quantity of products in a new item.
-// Returns ErrInvalidProduct error if product title is blank and
-// returns ErrInvalidQuantity error if quantity of products less than 1.
+// Returns ErrInvalidProduct error if product title is blank,
+// returns ErrInvalidQuantity error if quantity of products less than 1,
+// returns ErrProductNameTooLong error if product title is longer than MaxProductLength and
+// returns ErrLineQuantityExceeded error if quantity of products is greater than MaxLineQuantity.
 // In other cases returns nil.
 func (c CartService) ValidateItemData(product string, quantity int) error {
 	if product == "" {
@@ -15,5 +24,40 @@ func (c CartService) ValidateItemData(product string, quantity int) error {
 		return e.ErrInvalidQuantity
 	}
 
+	limits := c.limits()
+
+	if limits.MaxProductLength > 0 && utf8.RuneCountInString(product) > limits.MaxProductLength {
+		return errors.Wrapf(e.ErrProductNameTooLong, "at most %d characters are allowed", limits.MaxProductLength)
+	}
+
+	if limits.MaxLineQuantity > 0 && quantity > limits.MaxLineQuantity {
+		return errors.Wrapf(e.ErrLineQuantityExceeded, "at most %d products are allowed", limits.MaxLineQuantity)
+	}
+
+	return nil
+}
+
+// checkCartLimits checks that a new item of the product fits the limits of the cart with the items.
+// Returns ErrTooManyLines error if the cart has MaxLines items,
+// returns ErrCartUnitsExceeded error if the total quantity of the cart would exceed MaxUnits and
+// returns ErrProductLimitExceeded error if the quantity of the product in the cart would exceed its purchase limit.
+func (c CartService) checkCartLimits(items []model.CartItem, product string, quantity int) error {
+	limits := c.limits()
+	report := limits.Report(&model.Cart{Items: items})
+
+	if remaining := report.Lines.Remaining(); remaining == 0 {
+		return errors.Wrapf(e.ErrTooManyLines, "at most %d items are allowed", report.Lines.Limit)
+	}
+
+	if remaining := report.Units.Remaining(); remaining >= 0 && quantity > remaining {
+		return errors.Wrapf(e.ErrCartUnitsExceeded, "%d more products are allowed", remaining)
+	}
+
+	for _, p := range report.Products {
+		if p.Product == product && quantity > p.Remaining() {
+			return errors.Wrapf(e.ErrProductLimitExceeded, "%d more %s are allowed", p.Remaining(), product)
+		}
+	}
+
 	return nil
 }
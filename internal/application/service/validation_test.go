@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartService_ValidateItemData(t *testing.T) {
+	c := CartService{Limits: model.CartLimits{MaxLineQuantity: 5, MaxProductLength: 4}}
+
+	tt := []struct {
+		name          string
+		product       string
+		quantity      int
+		expectedError error
+	}{
+		{name: "Valid", product: "Hat", quantity: 5},
+		{name: "Multibyte product", product: "Шарф", quantity: 1},
+		{name: "Blank product", product: "", quantity: 1, expectedError: e.ErrInvalidProduct},
+		{name: "Zero quantity", product: "Hat", quantity: 0, expectedError: e.ErrInvalidQuantity},
+		{name: "Long product", product: "Shoes", quantity: 1, expectedError: e.ErrProductNameTooLong},
+		{name: "Line quantity", product: "Hat", quantity: 6, expectedError: e.ErrLineQuantityExceeded},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := c.ValidateItemData(tc.product, tc.quantity)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestCartService_SetLimits(t *testing.T) {
+	c := NewCartService(nil)
+	c.Limits = model.CartLimits{MaxLineQuantity: 5}
+
+	// The copies of the service, e.g. made by the value receivers, see the replaced limits.
+	service := *c
+
+	assert.NoError(t, service.ValidateItemData("Hat", 5))
+
+	c.SetLimits(model.CartLimits{MaxLineQuantity: 2})
+	assert.ErrorIs(t, service.ValidateItemData("Hat", 5), e.ErrLineQuantityExceeded)
+}
+
+func TestCartService_checkCartLimits(t *testing.T) {
+	items := []model.CartItem{{ID: 1, Product: "Hat", Quantity: 1}, {ID: 2, Product: "Shoes", Quantity: 3}}
+
+	tt := []struct {
+		name          string
+		limits        model.CartLimits
+		product       string
+		quantity      int
+		expectedError error
+	}{
+		{name: "No limits", product: "Hat", quantity: 100},
+		{name: "Lines left", limits: model.CartLimits{MaxLines: 3}, product: "Socks", quantity: 1},
+		{name: "Too many lines", limits: model.CartLimits{MaxLines: 2}, product: "Socks", quantity: 1, expectedError: e.ErrTooManyLines},
+		{name: "Units left", limits: model.CartLimits{MaxUnits: 6}, product: "Socks", quantity: 2},
+		{
+			name:          "Units exceeded",
+			limits:        model.CartLimits{MaxUnits: 6},
+			product:       "Socks",
+			quantity:      3,
+			expectedError: e.ErrCartUnitsExceeded,
+		},
+		{name: "Product left", limits: model.CartLimits{ProductLimits: map[string]int{"Hat": 2}}, product: "Hat", quantity: 1},
+		{
+			name:          "Product exceeded",
+			limits:        model.CartLimits{ProductLimits: map[string]int{"Hat": 2}},
+			product:       "Hat",
+			quantity:      2,
+			expectedError: e.ErrProductLimitExceeded,
+		},
+		{name: "Other product", limits: model.CartLimits{ProductLimits: map[string]int{"Hat": 1}}, product: "Shoes", quantity: 9},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := CartService{Limits: tc.limits}
+
+			err := c.checkCartLimits(items, tc.product, tc.quantity)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/webhook"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+
+	"github.com/pkg/errors"
+)
+
+// Limits of the webhook deliveries log page size.
+const (
+	DefaultDeliveriesLimit = 50
+	MaxDeliveriesLimit     = 500
+)
+
+// Webhooks is the interface that describes methods for managing the webhook subscriptions.
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id int) (*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, webhookID, limit int) ([]model.WebhookDelivery, error)
+}
+
+// WebhookService represents service layer of the webhook subscriptions.
+// All the methods return ErrAccessDenied error unless the policy allows the caller to manage the webhooks.
+type WebhookService struct {
+	Pool *postgres.Pool // connection pool
+}
+
+// NewWebhookService is a constructor for WebhookService struct.
+func NewWebhookService(pool *postgres.Pool) *WebhookService {
+	return &WebhookService{Pool: pool}
+}
+
+// knownEvents is a set of the domain event types which can be used in the webhook filter.
+var knownEvents = map[string]struct{}{
+	model.EventCartCreated: {},
+	model.EventItemAdded:   {},
+	model.EventItemRemoved: {},
+}
+
+// ValidateWebhook validates the URL and the event filter of the webhook.
+// Returns ErrInvalidWebhook error if the URL isn't an absolute http(s) URL of a public host
+// or the filter contains an unknown event type.
+func ValidateWebhook(w *model.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrap(e.ErrInvalidWebhook, "url must be an absolute http or https URL")
+	}
+
+	if !webhook.IsPublicHost(u.Hostname()) {
+		return errors.Wrap(e.ErrInvalidWebhook, "url must point to a public host")
+	}
+
+	for _, event := range w.Events {
+		if _, ok := knownEvents[event]; !ok {
+			return errors.Wrapf(e.ErrInvalidWebhook, "unknown event %q", event)
+		}
+	}
+
+	return nil
+}
+
+// CreateWebhook subscribes a new webhook to the domain events.
+// A random secret is generated if the secret is blank.
+// Returns a pointer to the webhook model including the secret.
+// Also it returns an error if the webhook is invalid or a database error if it can't be inserted.
+func (ws WebhookService) CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateWebhook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	created := *w
+	if created.Events == nil {
+		created.Events = []string{}
+	}
+
+	if created.Secret == "" {
+		created.Secret, err = webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created.ID, err = postgres.InsertWebhook(ctx, ws.Pool, &created)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	return ws.GetWebhook(ctx, created.ID)
+}
+
+// ListWebhooks lists all the webhooks.
+// Also it returns a database error if the webhooks can't be selected.
+func (ws WebhookService) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := postgres.ListWebhooks(ctx, ws.Pool)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook gets the webhook with the ID.
+// Returns ErrWebhookNotFound error if the webhook doesn't exist.
+func (ws WebhookService) GetWebhook(ctx context.Context, id int) (*model.Webhook, error) {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := postgres.GetWebhook(ctx, ws.Pool, id)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if w == nil {
+		return nil, e.ErrWebhookNotFound
+	}
+
+	return w, nil
+}
+
+// UpdateWebhook replaces the URL, the events and the active flag of the webhook.
+// The secret is rotated only if a new one is received.
+// Returns ErrInvalidWebhook error if the webhook is invalid and
+// ErrWebhookNotFound error if the webhook doesn't exist.
+func (ws WebhookService) UpdateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateWebhook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := ws.GetWebhook(ctx, w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *w
+	if updated.Events == nil {
+		updated.Events = []string{}
+	}
+
+	if updated.Secret == "" {
+		updated.Secret = current.Secret
+	}
+
+	found, err := postgres.UpdateWebhook(ctx, ws.Pool, &updated)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if !found {
+		return nil, e.ErrWebhookNotFound
+	}
+
+	updated.CreatedAt = current.CreatedAt
+
+	return &updated, nil
+}
+
+// DeleteWebhook unsubscribes the webhook and drops its deliveries.
+// Returns ErrWebhookNotFound error if the webhook doesn't exist.
+func (ws WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return err
+	}
+
+	found, err := postgres.DeleteWebhook(ctx, ws.Pool, id)
+	if err != nil {
+		return errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	if !found {
+		return e.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries lists the latest deliveries of the webhook, newest first.
+// The limit is clamped to MaxDeliveriesLimit, non-positive limit means DefaultDeliveriesLimit.
+// Returns ErrWebhookNotFound error if the webhook doesn't exist.
+func (ws WebhookService) ListDeliveries(ctx context.Context, webhookID, limit int) ([]model.WebhookDelivery, error) {
+	_, err := actorFrom(ctx, OpManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDeliveriesLimit
+	}
+
+	if limit > MaxDeliveriesLimit {
+		limit = MaxDeliveriesLimit
+	}
+
+	_, err = ws.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := postgres.ListWebhookDeliveries(ctx, ws.Pool, webhookID, limit)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrDB, err.Error())
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// privateNetworks are the networks which aren't reachable from the internet:
+// the private, shared and unspecified IPv4 ranges and the unique local IPv6 addresses.
+var privateNetworks = mustParseCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16",
+	"fc00::/7")
+
+// mustParseCIDRs parses the networks.
+// It panics if any of them is invalid.
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// IsPublicIP reports whether the webhooks may be delivered to the IP address.
+// The loopback, private, link-local, multicast and unspecified addresses are not public,
+// so the webhooks can't reach the internal services of the deployment.
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsPublicHost reports whether the host of the webhook URL may be public.
+// The IP addresses are checked by IsPublicIP and localhost names are rejected.
+// Other names are checked when the resolved address is dialed, because they may resolve differently later.
+func IsPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if ip := net.ParseIP(host); ip != nil {
+		return IsPublicIP(ip)
+	}
+
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// checkAddress rejects the connections to the addresses which aren't public.
+// It's called by the dialer after the host is resolved, so the rebound names are rejected too.
+func checkAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+
+	return nil
+}
+
+// NewClient returns an HTTP client for the webhook deliveries with the timeout.
+// The client connects only to the public addresses and doesn't use the proxy from the environment,
+// because the proxy would hide the address of the webhook from the check.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkAddress}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicHost(t *testing.T) {
+	tt := []struct {
+		name     string
+		host     string
+		expected bool
+	}{
+		{name: "Public IPv4", host: "93.184.216.34", expected: true},
+		{name: "Public IPv6", host: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{name: "Name", host: "partner.example.com", expected: true},
+		{name: "Loopback", host: "127.0.0.1", expected: false},
+		{name: "IPv6 loopback", host: "::1", expected: false},
+		{name: "Mapped loopback", host: "::ffff:127.0.0.1", expected: false},
+		{name: "Private", host: "10.1.2.3", expected: false},
+		{name: "Private 172", host: "172.20.0.5", expected: false},
+		{name: "Private 192", host: "192.168.1.1", expected: false},
+		{name: "Shared", host: "100.64.0.1", expected: false},
+		{name: "Link-local", host: "169.254.169.254", expected: false},
+		{name: "IPv6 link-local", host: "fe80::1", expected: false},
+		{name: "Unique local", host: "fd00::1", expected: false},
+		{name: "Unspecified", host: "0.0.0.0", expected: false},
+		{name: "Localhost", host: "localhost", expected: false},
+		{name: "Localhost with dot", host: "LOCALHOST.", expected: false},
+		{name: "Localhost subdomain", host: "api.localhost", expected: false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsPublicHost(tc.host))
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	called := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil)
+
+	status, err := d.send(context.Background(), &model.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not public")
+	assert.Equal(t, 0, status)
+	assert.False(t, called)
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/outbox"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default settings of the Dispatcher.
+const (
+	DefaultBatchSize    = 20
+	DefaultPollInterval = time.Second
+	DefaultMaxBackoff   = time.Hour
+	DefaultMaxAttempts  = 8
+	DefaultTimeout      = 10 * time.Second
+	DefaultLease        = time.Minute
+)
+
+// maxErrorBody limits the part of the response body stored as the delivery error.
+const maxErrorBody = 512
+
+// Dispatcher posts the scheduled deliveries to the webhooks.
+// The deliveries of the batch are made concurrently outside of any transaction.
+// Failed deliveries are retried with exponential backoff and dead-lettered after MaxAttempts failures.
+type Dispatcher struct {
+	Pool         *postgres.Pool    // connection pool
+	Client       *http.Client      // HTTP client used for the delivery
+	BatchSize    int               // maximum number of deliveries claimed and made at once
+	PollInterval time.Duration     // how often the deliveries are polled when there are no due ones
+	MaxBackoff   time.Duration     // upper bound of the delay between the delivery attempts
+	MaxAttempts  int               // number of failed attempts after which the delivery is dead-lettered
+	Lease        time.Duration     // how long the claimed batch is hidden from other dispatchers while it's delivered
+	Heartbeat    *health.Heartbeat // records the liveness of the worker, optional
+}
+
+// NewDispatcher is a constructor for Dispatcher struct with the default settings.
+func NewDispatcher(pool *postgres.Pool) *Dispatcher {
+	return &Dispatcher{
+		Pool:         pool,
+		Client:       NewClient(DefaultTimeout),
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		MaxBackoff:   DefaultMaxBackoff,
+		MaxAttempts:  DefaultMaxAttempts,
+		Lease:        DefaultLease,
+	}
+}
+
+// Run dispatches the deliveries until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		d.Heartbeat.Beat()
+
+		n, err := d.DispatchBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Dispatch webhooks batch error")
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.PollInterval):
+		}
+	}
+}
+
+// sendResult is the result of the single delivery attempt.
+type sendResult struct {
+	status int
+	err    error
+}
+
+// DispatchBatch claims a single batch of the due deliveries, makes them and stores the results.
+// The batch is claimed and the results are stored in the short transactions,
+// the deliveries are made concurrently outside of them within the lease.
+// Returns the number of the claimed deliveries.
+// Also it returns an error if the batch can't be claimed or the delivery results can't be stored.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := postgres.ClaimDueDeliveries(ctx, d.Pool, d.BatchSize, time.Now().Add(d.Lease))
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, d.Lease)
+	defer cancel()
+
+	results := make([]sendResult, len(deliveries))
+
+	var wg sync.WaitGroup
+
+	for i := range deliveries {
+		wg.Add(1)
+
+		go func(delivery *model.WebhookDelivery, res *sendResult) {
+			defer wg.Done()
+
+			res.status, res.err = d.send(sendCtx, delivery)
+		}(&deliveries[i], &results[i])
+	}
+
+	wg.Wait()
+
+	err = postgres.RetryTx(ctx, d.Pool, func(tx pgx.Tx) error {
+		for i := range deliveries {
+			err := d.store(ctx, tx, &deliveries[i], results[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return len(deliveries), err
+}
+
+// store stores the result of the delivery attempt.
+func (d *Dispatcher) store(ctx context.Context, tx pgx.Tx, delivery *model.WebhookDelivery, res sendResult) error {
+	if res.err == nil {
+		return postgres.MarkDeliveryDelivered(ctx, tx, delivery.ID, res.status)
+	}
+
+	attempts := delivery.Attempts + 1
+	dead := attempts >= d.MaxAttempts
+
+	if dead {
+		logging.FromContext(ctx).Warn().Err(res.err).
+			Int("webhook_id", delivery.WebhookID).
+			Int64("delivery_id", delivery.ID).
+			Int("attempts", attempts).
+			Msg("Webhook delivery is dead")
+	}
+
+	return postgres.MarkDeliveryFailed(ctx, tx, delivery.ID, res.status, res.err.Error(),
+		time.Now().Add(outbox.Backoff(delivery.Attempts, d.MaxBackoff)), dead)
+}
+
+// send posts the signed payload of the delivery to the webhook URL.
+// The request is traced as a client span continuing the trace of the request which caused the event,
+// and the trace context is sent in the traceparent header.
+// Returns the response status code, 0 if the request failed.
+// Also it returns an error if the webhook doesn't respond with 2xx status.
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery) (status int, err error) {
+	ctx, span := tracing.Tracer().Start(tracing.WithTraceParent(ctx, delivery.TraceParent), "webhook deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("webhook.id", delivery.WebhookID),
+			attribute.Int64("webhook.delivery.id", delivery.ID),
+			attribute.String("webhook.event.type", delivery.EventType),
+		))
+	defer func() { tracing.End(span, err) }()
+
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	return resp.StatusCode, nil
+}
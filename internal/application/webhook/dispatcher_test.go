@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", 1600000000, body)
+
+	tt := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		valid     bool
+	}{
+		{name: "Valid", secret: "secret", timestamp: 1600000000, body: body, signature: signature, valid: true},
+		{name: "Wrong secret", secret: "other", timestamp: 1600000000, body: body, signature: signature},
+		{name: "Replayed timestamp", secret: "secret", timestamp: 1600000001, body: body, signature: signature},
+		{name: "Tampered body", secret: "secret", timestamp: 1600000000, body: []byte(`{"id":2}`), signature: signature},
+		{name: "Missing prefix", secret: "secret", timestamp: 1600000000, body: body, signature: signature[len("sha256="):]},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, Verify(tc.secret, tc.timestamp, tc.body, tc.signature))
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	s1, err := NewSecret()
+	require.NoError(t, err)
+
+	s2, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, s1, s2)
+}
+
+func TestDispatcher_send(t *testing.T) {
+	tt := []struct {
+		name      string
+		status    int
+		expectErr bool
+	}{
+		{name: "Accepted", status: http.StatusNoContent},
+		{name: "Rejected", status: http.StatusInternalServerError, expectErr: true},
+		{name: "Redirected", status: http.StatusFound, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			delivery := model.WebhookDelivery{
+				ID:        7,
+				EventID:   42,
+				EventType: model.EventItemAdded,
+				Payload:   json.RawMessage(`{"id":42,"type":"ItemAdded"}`),
+				Secret:    "secret",
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+
+				timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+				assert.NoError(t, err)
+
+				assert.Equal(t, "42", r.Header.Get(HeaderID))
+				assert.Equal(t, model.EventItemAdded, r.Header.Get(HeaderEvent))
+				assert.True(t, Verify("secret", timestamp, body, r.Header.Get(HeaderSignature)))
+				assert.JSONEq(t, string(delivery.Payload), string(body))
+
+				w.Header().Set("Location", "/elsewhere")
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			delivery.URL = server.URL
+
+			d := NewDispatcher(nil)
+			d.Client = server.Client()
+			d.Client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+			status, err := d.send(context.Background(), &delivery)
+			assert.Equal(t, tc.status, status)
+
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_sendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	d := NewDispatcher(nil)
+	d.Client = server.Client()
+
+	status, err := d.send(context.Background(), &model.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, 0, status)
+}
+
+func TestDispatcher_sendTraceParent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var traceParent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := model.WebhookDelivery{
+		ID:          7,
+		URL:         server.URL,
+		Payload:     json.RawMessage(`{}`),
+		TraceParent: "00-" + traceID + "-00f067aa0ba902b7-01",
+	}
+
+	d := NewDispatcher(nil)
+	d.Client = server.Client()
+
+	_, err := d.send(context.Background(), &delivery)
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "webhook deliver", spans[0].Name)
+	assert.Equal(t, traceID, spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, "00-"+traceID+"-"+spans[0].SpanContext.SpanID().String()+"-01", traceParent)
+}
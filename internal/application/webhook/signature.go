@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Headers of the webhook requests.
+const (
+	HeaderID        = "Webhook-Id"        // ID of the domain event, receivers should drop duplicates by it
+	HeaderEvent     = "Webhook-Event"     // Type of the domain event
+	HeaderTimestamp = "Webhook-Timestamp" // Unix time when the request was signed
+	HeaderSignature = "Webhook-Signature" // HMAC-SHA256 signature of the timestamp and the body
+)
+
+// signaturePrefix is a scheme prefix of the signature header value.
+const signaturePrefix = "sha256="
+
+// Sign signs the body sent at the timestamp with the secret.
+// The signature is HMAC-SHA256 of the "<timestamp>.<body>" string encoded as hex with the sha256= prefix.
+// Including the timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the body sent at the timestamp.
+// It is the reference implementation for the receivers.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// NewSecret generates a random secret for signing the payloads.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/database/postgres"
+)
+
+// Sink schedules the outbox messages for the delivery to the subscribed webhooks.
+// It implements outbox.Sink, the deliveries themselves are made by the Dispatcher.
+type Sink struct {
+	Pool *postgres.Pool // connection pool
+}
+
+// NewSink is a constructor for Sink struct.
+func NewSink(pool *postgres.Pool) *Sink {
+	return &Sink{Pool: pool}
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+// Deliver schedules the message for all the active webhooks subscribed to its type.
+// Repeated calls for the same message don't create duplicate deliveries.
+func (s *Sink) Deliver(ctx context.Context, msg *model.OutboxMessage) error {
+	return postgres.EnqueueWebhookDeliveries(ctx, s.Pool, msg)
+}
@@ -1,24 +1,162 @@
 package config
 
 import (
-	"github.com/kelseyhightower/envconfig"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Config represents envs from the config.env file.
+// Config represents the settings of the application.
+// The key of a setting in the config file is its env name in lower case without the CART_ prefix,
+// e.g. log_level, and the flag is the key with dashes, e.g. --log-level.
+// The settings tagged reload are applied on SIGHUP without a restart and the ones tagged secret
+// may be read from the file of the env with the _FILE suffix, e.g. POSTGRES_PASSWORD_FILE.
 type Config struct {
-	PostgresURL string `envconfig:"POSTGRES_URL"` // PostgresURL is database connection string
-	Host        string `envconfig:"CART_HOST"`    // Host is an application IP address
-	Port        string `envconfig:"CART_PORT"`    // Port is an application port
+	PostgresURL         string            `env:"POSTGRES_URL" secret:"true"`                       // PostgresURL is database connection string, assembled from the POSTGRES_* fields if empty
+	PostgresHost        string            `env:"POSTGRES_HOST"`                                    // PostgresHost is a host of the database
+	PostgresPort        int               `env:"POSTGRES_PORT" default:"5432"`                     // PostgresPort is a port of the database
+	PostgresUser        string            `env:"POSTGRES_USER"`                                    // PostgresUser is a user of the database
+	PostgresPassword    string            `env:"POSTGRES_PASSWORD" secret:"true"`                  // PostgresPassword is a password of the database user
+	PostgresDB          string            `env:"POSTGRES_DB"`                                      // PostgresDB is a name of the database
+	PostgresSSLMode     string            `env:"POSTGRES_SSLMODE" default:"prefer"`                // PostgresSSLMode is an SSL mode: disable, allow, prefer, require, verify-ca or verify-full
+	DBMaxConns          int               `env:"CART_DB_MAX_CONNS" default:"10"`                   // DBMaxConns is a maximum number of the connections in the pool
+	DBMinConns          int               `env:"CART_DB_MIN_CONNS"`                                // DBMinConns is a number of the connections kept open even if they are idle
+	DBMaxConnLifetime   time.Duration     `env:"CART_DB_MAX_CONN_LIFETIME" default:"1h"`           // DBMaxConnLifetime is a time after which the connection is closed and replaced
+	DBMaxConnIdleTime   time.Duration     `env:"CART_DB_MAX_CONN_IDLE_TIME" default:"30m"`         // DBMaxConnIdleTime is a time after which the idle connection is closed
+	DBHealthCheckPeriod time.Duration     `env:"CART_DB_HEALTH_CHECK_PERIOD" default:"1m"`         // DBHealthCheckPeriod is how often the idle connections are checked
+	DBConnectTimeout    time.Duration     `env:"CART_DB_CONNECT_TIMEOUT" default:"5s"`             // DBConnectTimeout is a timeout of establishing a single connection
+	DBStartupTimeout    time.Duration     `env:"CART_DB_STARTUP_TIMEOUT" default:"1m"`             // DBStartupTimeout is how long the unavailable database is awaited at startup, 0 fails at once
+	DBQueryTimeout      time.Duration     `env:"CART_DB_QUERY_TIMEOUT" default:"5s" reload:"true"` // DBQueryTimeout is a timeout of every SQL statement, 0 disables it
+	DBRetryAttempts     int               `env:"CART_DB_RETRY_ATTEMPTS" default:"3" reload:"true"` // DBRetryAttempts is a number of the attempts of the idempotent operations on the transient errors
+	Host                string            `env:"CART_HOST"`                                        // Host is an application IP address, all the interfaces if empty
+	Port                Port              `env:"CART_PORT" default:"3000"`                         // Port is an application port, e.g. 3000 or :3000
+	V1Sunset            time.Time         `env:"CART_V1_SUNSET"`                                   // V1Sunset is an RFC 3339 date after which the v1 API is removed
+	AdminPort           Port              `env:"CART_ADMIN_PORT"`                                  // AdminPort is a port of the admin server with the metrics, 0 disables it
+	GRPCPort            Port              `env:"CART_GRPC_PORT"`                                   // GRPCPort is a port of the gRPC server, 0 disables it
+	EventsFanout        bool              `env:"CART_EVENTS_FANOUT"`                               // EventsFanout delivers cart events to all replicas via Postgres LISTEN/NOTIFY
+	OutboxSinks         []string          `env:"CART_OUTBOX_SINKS"`                                // OutboxSinks is a list of the domain events sinks: log, file, http
+	OutboxFile          string            `env:"CART_OUTBOX_FILE"`                                 // OutboxFile is a path of the file sink
+	OutboxURL           string            `env:"CART_OUTBOX_URL"`                                  // OutboxURL is a webhook URL of the http sink
+	OutboxRetention     time.Duration     `env:"CART_OUTBOX_RETENTION" default:"168h"`             // OutboxRetention is how long the delivered events are kept in the outbox, 0 keeps them forever
+	TrustedProxies      int               `env:"CART_TRUSTED_PROXIES"`                             // TrustedProxies is a number of the reverse proxies which append the client IP to the X-Forwarded-For header
+	WebhookMaxAttempts  int               `env:"CART_WEBHOOK_MAX_ATTEMPTS" default:"8"`            // WebhookMaxAttempts is a number of failed attempts after which the delivery is dead-lettered
+	JWTSecret           string            `env:"CART_JWT_HS256_SECRET" secret:"true"`              // JWTSecret is a secret of the HS256 bearer tokens
+	JWKS                string            `env:"CART_JWT_JWKS"`                                    // JWKS is a path or URL of the key set of the RS256 bearer tokens
+	JWTIssuer           string            `env:"CART_JWT_ISSUER"`                                  // JWTIssuer is a required iss claim of the bearer tokens
+	JWTAudience         string            `env:"CART_JWT_AUDIENCE"`                                // JWTAudience is a required aud claim of the bearer tokens
+	APIKeys             bool              `env:"CART_API_KEYS"`                                    // APIKeys enables the authentication with the X-API-Key header
+	RateLimits          map[string]string `env:"CART_RATE_LIMITS"`                                 // RateLimits maps the route names to the limits, e.g. createCart:10/1m,default:100/1m
+	RateLimitStore      string            `env:"CART_RATE_LIMIT_STORE" default:"memory"`           // RateLimitStore keeps the token buckets: memory or postgres
+	AuthFailureLimit    string            `env:"CART_AUTH_FAILURE_LIMIT" default:"10/1m"`          // AuthFailureLimit is a number of the failed authentications allowed per client IP, empty disables it
+	MaxCartLines        int               `env:"CART_MAX_CART_LINES" reload:"true"`                // MaxCartLines is a maximum number of the items in the cart, 0 means no limit
+	MaxLineQuantity     int               `env:"CART_MAX_LINE_QUANTITY" reload:"true"`             // MaxLineQuantity is a maximum quantity of the single item, 0 means no limit
+	MaxCartUnits        int               `env:"CART_MAX_CART_UNITS" reload:"true"`                // MaxCartUnits is a maximum total quantity of the items in the cart, 0 means no limit
+	MaxProductLength    int               `env:"CART_MAX_PRODUCT_LENGTH" reload:"true"`            // MaxProductLength is a maximum length of the product name, 0 means no limit
+	ProductLimits       map[string]int    `env:"CART_PRODUCT_LIMITS" reload:"true"`                // ProductLimits maps the products to their purchase limits, e.g. Hat:2,Shoes:1
+	LogLevel            string            `env:"CART_LOG_LEVEL" default:"info" reload:"true"`      // LogLevel is a minimal level of the logs: debug, info, warn or error
+	LogFormat           string            `env:"CART_LOG_FORMAT" default:"json"`                   // LogFormat is a format of the logs: json or console
+	TraceExporter       string            `env:"CART_TRACE_EXPORTER" default:"none"`               // TraceExporter is an exporter of the spans: otlp, stdout or none
+	TraceEndpoint       string            `env:"CART_TRACE_ENDPOINT"`                              // TraceEndpoint is a host:port of the OTLP/HTTP collector, defaults to OTEL_EXPORTER_OTLP_ENDPOINT
+	ReadTimeout         time.Duration     `env:"CART_READ_TIMEOUT" default:"15s"`                  // ReadTimeout is a maximum duration of reading the request including the body
+	ReadHeaderTimeout   time.Duration     `env:"CART_READ_HEADER_TIMEOUT" default:"5s"`            // ReadHeaderTimeout is a maximum duration of reading the request headers
+	WriteTimeout        time.Duration     `env:"CART_WRITE_TIMEOUT" default:"30s"`                 // WriteTimeout is a maximum duration of writing the response, the event streams extend their own deadline
+	IdleTimeout         time.Duration     `env:"CART_IDLE_TIMEOUT" default:"2m"`                   // IdleTimeout is a maximum time to wait for the next request on a keep-alive connection
+	ShutdownTimeout     time.Duration     `env:"CART_SHUTDOWN_TIMEOUT" default:"30s"`              // ShutdownTimeout is a deadline of finishing the in-flight requests and stopping the workers on SIGTERM
+	TLSCertFile         string            `env:"CART_TLS_CERT_FILE"`                               // TLSCertFile is a path of the PEM certificate chain, empty serves plaintext
+	TLSKeyFile          string            `env:"CART_TLS_KEY_FILE"`                                // TLSKeyFile is a path of the PEM private key
+	TLSMinVersion       string            `env:"CART_TLS_MIN_VERSION" default:"1.2"`               // TLSMinVersion is a minimal version of TLS: 1.2 or 1.3
+	TLSCipherSuites     []string          `env:"CART_TLS_CIPHER_SUITES"`                           // TLSCipherSuites is a list of the TLS 1.2 cipher suites, the Go defaults if empty
+	TLSClientAuth       string            `env:"CART_TLS_CLIENT_AUTH" default:"none"`              // TLSClientAuth verifies the client certificates: none, optional or require
+	TLSClientCAFile     string            `env:"CART_TLS_CLIENT_CA_FILE"`                          // TLSClientCAFile is a path of the PEM bundle of the CAs which issue the client certificates
+	TLSClientScopes     []string          `env:"CART_TLS_CLIENT_SCOPES"`                           // TLSClientScopes is a list of the scopes granted to the clients with a verified certificate
+	TLSReloadInterval   time.Duration     `env:"CART_TLS_RELOAD_INTERVAL" default:"30s"`           // TLSReloadInterval is how often the certificate files are checked for changes
+	ShutdownDrain       time.Duration     `env:"CART_SHUTDOWN_DRAIN" default:"5s"`                 // ShutdownDrain is a time between failing the readiness and stopping the server on SIGTERM
+	WorkerMaxAge        time.Duration     `env:"CART_HEALTH_WORKER_MAX_AGE" default:"1m"`          // WorkerMaxAge is a time since the last heartbeat after which the background worker is unhealthy
 }
 
-// NewConfig is a constructor for Config struct.
+// NewConfig is a constructor for Config struct loaded from the defaults, the file of CART_CONFIG and the environment.
+// Returns an error if a setting can't be parsed or the config is invalid.
 func NewConfig() (*Config, error) {
-	var c Config
+	l := Loader{LookupEnv: os.LookupEnv}
 
-	err := envconfig.Process("cart", &c)
+	return l.Load()
+}
+
+// Port represents a TCP port which is parsed from a number or the :port syntax.
+type Port int
+
+// UnmarshalText parses the port, e.g. 3000 or :3000.
+func (p *Port) UnmarshalText(text []byte) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(string(text), ":"))
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid port %q", text)
+	}
+
+	*p = Port(n)
+
+	return nil
+}
+
+// MarshalText formats the port as a number.
+func (p Port) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(int(p))), nil
+}
+
+// DatabaseURL returns the connection string of the database: POSTGRES_URL if set,
+// otherwise the URL assembled from the host, port, user, password, database and SSL mode.
+func (c *Config) DatabaseURL() string {
+	if c.PostgresURL != "" {
+		return c.PostgresURL
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     net.JoinHostPort(c.PostgresHost, strconv.Itoa(c.PostgresPort)),
+		Path:     "/" + c.PostgresDB,
+		RawQuery: url.Values{"sslmode": {c.PostgresSSLMode}}.Encode(),
+	}
+
+	switch {
+	case c.PostgresPassword != "":
+		u.User = url.UserPassword(c.PostgresUser, c.PostgresPassword)
+	case c.PostgresUser != "":
+		u.User = url.User(c.PostgresUser)
+	}
+
+	return u.String()
+}
+
+// Secrets returns the values of the secret settings which must never be logged.
+func (c *Config) Secrets() []string {
+	v := reflect.ValueOf(c).Elem()
+
+	var secrets []string
+
+	for _, f := range fields {
+		if s := v.Field(f.index).String(); f.secret && s != "" {
+			secrets = append(secrets, s)
+		}
 	}
 
-	return &c, nil
+	if u, err := url.Parse(c.PostgresURL); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			secrets = append(secrets, password)
+		}
+	}
+
+	if m := dsnPassword.FindStringSubmatch(c.PostgresURL); m != nil {
+		secrets = append(secrets, strings.Trim(m[2], "'"))
+	}
+
+	return secrets
+}
+
+// Addr returns the address of the port on the host, e.g. 0.0.0.0:3000.
+func (c *Config) Addr(port Port) string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(int(port)))
 }
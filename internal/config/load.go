@@ -0,0 +1,414 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is an env of the path of the config file, the --config flag overrides it.
+const FileEnv = "CART_CONFIG"
+
+// field describes the setting of the config.
+type field struct {
+	index  int    // index of the field in the Config struct
+	env    string // env name, e.g. CART_LOG_LEVEL
+	key    string // key in the config file, e.g. log_level
+	flag   string // name of the flag, e.g. log-level
+	def    string // default value
+	secret bool   // value is redacted when printed
+	reload bool   // value is applied on SIGHUP without a restart
+}
+
+// fields describes the settings of the Config struct in the order of declaration.
+var fields = describeFields()
+
+// describeFields reads the settings from the tags of the Config struct.
+func describeFields() []field {
+	t := reflect.TypeOf(Config{})
+	fs := make([]field, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+
+		env := tag.Get("env")
+		key := strings.ToLower(strings.TrimPrefix(env, "CART_"))
+
+		fs = append(fs, field{
+			index:  i,
+			env:    env,
+			key:    key,
+			flag:   strings.ReplaceAll(key, "_", "-"),
+			def:    tag.Get("default"),
+			secret: tag.Get("secret") == "true",
+			reload: tag.Get("reload") == "true",
+		})
+	}
+
+	return fs
+}
+
+// Loader loads the config from the layers in which the later ones override the earlier ones:
+// the defaults, the YAML or TOML config file, the environment and the command line flags.
+type Loader struct {
+	Args      []string                        // command line arguments without the program name
+	LookupEnv func(key string) (string, bool) // lookup of the environment, e.g. os.LookupEnv
+
+	File        string // path of the config file, set by Load from --config or CART_CONFIG
+	PrintConfig bool   // whether --print-config is set, set by Load
+}
+
+// Load loads and validates the config, it may be called again to reload the config.
+// Returns an error if the flags, the file or a setting can't be parsed or the config is invalid.
+func (l *Loader) Load() (*Config, error) {
+	flags, err := l.parseFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	env := l.LookupEnv
+	if env == nil {
+		env = func(string) (string, bool) { return "", false }
+	}
+
+	if l.File == "" {
+		l.File, _ = env(FileEnv)
+	}
+
+	file := map[string]string{}
+
+	if l.File != "" {
+		file, err = readFile(l.File)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var c Config
+
+	v := reflect.ValueOf(&c).Elem()
+
+	for _, f := range fields {
+		value := f.def
+
+		if s, ok := file[f.key]; ok {
+			value = s
+		}
+
+		s, ok, err := lookupSecret(env, f)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			value = s
+		}
+
+		if s, ok := flags[f.flag]; ok {
+			value = s
+		}
+
+		err = setValue(v.Field(f.index), value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.env, err)
+		}
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// FileSuffix is a suffix of the env of the path of the file which contains the secret, e.g. POSTGRES_PASSWORD_FILE.
+const FileSuffix = "_FILE"
+
+// lookupSecret looks up the setting in the environment, the secret settings may be read from the file
+// of the env with FileSuffix, e.g. the Docker or Kubernetes secret. The trailing newline of the file is trimmed.
+// Returns an error if both envs are set or the file can't be read.
+func lookupSecret(env func(string) (string, bool), f field) (string, bool, error) {
+	value, ok := env(f.env)
+	if !f.secret {
+		return value, ok, nil
+	}
+
+	name, fromFile := env(f.env + FileSuffix)
+	if !fromFile {
+		return value, ok, nil
+	}
+
+	if ok {
+		return "", false, fmt.Errorf("set either %s or %s%s", f.env, f.env, FileSuffix)
+	}
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", false, fmt.Errorf("%s%s: %v", f.env, FileSuffix, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), true, nil
+}
+
+// parseFlags parses the command line flags of the settings, --config and --print-config.
+// Returns the values of the settings set explicitly by their flag names.
+func (l *Loader) parseFlags() (map[string]string, error) {
+	fs := flag.NewFlagSet("cart", flag.ContinueOnError)
+	fs.StringVar(&l.File, "config", "", "path of the YAML or TOML config file, overrides "+FileEnv)
+	fs.BoolVar(&l.PrintConfig, "print-config", false, "print the effective config with the secrets redacted and exit")
+
+	for _, f := range fields {
+		fs.String(f.flag, f.def, "overrides "+f.env)
+	}
+
+	err := fs.Parse(l.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	flags := make(map[string]string)
+
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	return flags, nil
+}
+
+// readFile reads the config file, the format is chosen by the extension: .yaml, .yml or .toml.
+// Returns the values of the settings by their keys.
+// Also it returns an error if the file can't be parsed or has an unknown key.
+func readFile(name string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &raw)
+	case ".toml":
+		err = toml.Unmarshal(b, &raw)
+	default:
+		return nil, fmt.Errorf("unknown format of the config file %s, use .yaml or .toml", name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", name, err)
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.key] = true
+	}
+
+	values := make(map[string]string, len(raw))
+
+	for key, value := range raw {
+		if !known[key] {
+			return nil, fmt.Errorf("parse %s: unknown setting %q", name, key)
+		}
+
+		values[key] = fileValue(value)
+	}
+
+	return values, nil
+}
+
+// fileValue formats the value of the config file in the syntax of the env:
+// the lists are joined with commas and the maps are joined as key:value pairs.
+func fileValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fileValue(item))
+		}
+
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for k, item := range v {
+			pairs = append(pairs, k+":"+fileValue(item))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	}
+
+	return fmt.Sprint(value)
+}
+
+// setValue parses the string into the value of the setting.
+// Returns an error if the type of the setting isn't supported or the string can't be parsed.
+func setValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if s == "" {
+			return nil
+		}
+
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Interface().(type) {
+	case time.Duration:
+		if s == "" || s == "0" {
+			return nil
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			return nil
+		}
+
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int:
+		if s == "" {
+			return nil
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		items := splitList(s)
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+
+		for i, item := range items {
+			err := setValue(v.Index(i), item)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+
+		for _, pair := range splitList(s) {
+			kv := strings.SplitN(pair, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid pair %q, expected key:value", pair)
+			}
+
+			key := reflect.New(v.Type().Key()).Elem()
+			value := reflect.New(v.Type().Elem()).Elem()
+
+			err := setValue(key, strings.TrimSpace(kv[0]))
+			if err != nil {
+				return err
+			}
+
+			err = setValue(value, strings.TrimSpace(kv[1]))
+			if err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, value)
+		}
+
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// splitList splits the comma separated list skipping the blank items.
+func splitList(s string) []string {
+	var items []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// formatValue formats the value of the setting in the syntax of the env.
+func formatValue(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+
+		return value.Format(time.RFC3339)
+	case encoding.TextMarshaler:
+		b, err := value.MarshalText()
+		if err != nil {
+			return ""
+		}
+
+		return string(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, formatValue(v.Index(i)))
+		}
+
+		return strings.Join(items, ",")
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, formatValue(iter.Key())+":"+formatValue(iter.Value()))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
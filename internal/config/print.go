@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces the secrets in the printed config.
+const Redacted = "REDACTED"
+
+// dsnPassword matches the password of the key=value connection string.
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// Print writes the config to the writer in the YAML format of the config file with the secrets redacted,
+// so the effective config can be inspected or used as the config file.
+func (c *Config) Print(w io.Writer) error {
+	v := reflect.ValueOf(c).Elem()
+	doc := yaml.Node{Kind: yaml.MappingNode}
+
+	for _, f := range fields {
+		value := formatValue(v.Field(f.index))
+		if f.secret {
+			value = Redact(value)
+		}
+
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: f.key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: yaml.DoubleQuotedStyle},
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+
+	return enc.Encode(&doc)
+}
+
+// Redact hides the secret: only the password of the connection URL or string is hidden,
+// so the rest stays readable, and any other value is replaced completely.
+func Redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(secret); err == nil && u.Scheme != "" && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+		}
+
+		return u.String()
+	}
+
+	if dsnPassword.MatchString(secret) {
+		return dsnPassword.ReplaceAllString(secret, "${1}"+Redacted)
+	}
+
+	return Redacted
+}
@@ -0,0 +1,42 @@
+package config
+
+import "reflect"
+
+// Change represents the setting which differs between two configs.
+type Change struct {
+	Env    string // env name of the setting
+	Reload bool   // whether the setting is applied without a restart
+}
+
+// Diff returns the settings which differ between the configs in the order of declaration.
+func Diff(old, updated *Config) []Change {
+	ov := reflect.ValueOf(old).Elem()
+	uv := reflect.ValueOf(updated).Elem()
+
+	var changes []Change
+
+	for _, f := range fields {
+		if formatValue(ov.Field(f.index)) != formatValue(uv.Field(f.index)) {
+			changes = append(changes, Change{Env: f.env, Reload: f.reload})
+		}
+	}
+
+	return changes
+}
+
+// WithReloaded returns a copy of the config with the settings which are applied without a restart
+// taken from the updated config.
+func (c *Config) WithReloaded(updated *Config) *Config {
+	next := *c
+
+	nv := reflect.ValueOf(&next).Elem()
+	uv := reflect.ValueOf(updated).Elem()
+
+	for _, f := range fields {
+		if f.reload {
+			nv.Field(f.index).Set(uv.Field(f.index))
+		}
+	}
+
+	return &next
+}
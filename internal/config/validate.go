@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError is a custom error that returns if the settings of the config are invalid.
+// It lists all the problems, so they are fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems of the config.
+func (ve *ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(ve.Problems, "\n  ")
+}
+
+// validator collects the problems of the config.
+type validator struct {
+	problems []string
+}
+
+// check records the problem of the setting unless ok.
+func (v *validator) check(ok bool, env, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, env+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+// oneOf records the problem of the setting unless the value is one of the allowed ones.
+func (v *validator) oneOf(value, env string, allowed ...string) {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return
+		}
+	}
+
+	v.check(false, env, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// port records the problem of the port which isn't valid, 0 is valid only if the port is optional.
+func (v *validator) port(p Port, env string, optional bool) {
+	min := 1
+	if optional {
+		min = 0
+	}
+
+	v.check(int(p) >= min && p <= 65535, env, "must be between %d and 65535, got %d", min, p)
+}
+
+// httpURL records the problem of the URL which isn't an absolute http or https URL.
+func (v *validator) httpURL(value, env string) {
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", env,
+		"must be an absolute http or https URL")
+}
+
+// Validate checks the settings of the config.
+// Returns ValidationError with all the problems if the config is invalid.
+func (c *Config) Validate() error {
+	var v validator
+
+	v.check(c.PostgresURL != "" || c.PostgresHost != "", "POSTGRES_URL", "is required unless POSTGRES_HOST is set")
+	v.check(c.PostgresURL == "" || c.PostgresHost == "", "POSTGRES_URL", "must not be set together with POSTGRES_HOST")
+	v.check(c.PostgresPort > 0 && c.PostgresPort <= 65535, "POSTGRES_PORT", "must be between 1 and 65535, got %d", c.PostgresPort)
+	v.oneOf(c.PostgresSSLMode, "POSTGRES_SSLMODE", "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+
+	if c.PostgresHost != "" {
+		v.check(c.PostgresUser != "", "POSTGRES_USER", "is required by POSTGRES_HOST")
+		v.check(c.PostgresDB != "", "POSTGRES_DB", "is required by POSTGRES_HOST")
+	}
+
+	v.check(c.DBMaxConns > 0, "CART_DB_MAX_CONNS", "must be positive")
+	v.check(c.DBMinConns >= 0 && c.DBMinConns <= c.DBMaxConns, "CART_DB_MIN_CONNS", "must be between 0 and %d, got %d",
+		c.DBMaxConns, c.DBMinConns)
+	v.check(c.DBRetryAttempts > 0, "CART_DB_RETRY_ATTEMPTS", "must be positive")
+
+	v.port(c.Port, "CART_PORT", false)
+	v.port(c.AdminPort, "CART_ADMIN_PORT", true)
+	v.port(c.GRPCPort, "CART_GRPC_PORT", true)
+	v.check(c.AdminPort == 0 || c.AdminPort != c.Port, "CART_ADMIN_PORT", "must differ from CART_PORT")
+	v.check(c.GRPCPort == 0 || (c.GRPCPort != c.Port && c.GRPCPort != c.AdminPort), "CART_GRPC_PORT",
+		"must differ from CART_PORT and CART_ADMIN_PORT")
+
+	for _, sink := range c.OutboxSinks {
+		v.oneOf(sink, "CART_OUTBOX_SINKS", "log", "file", "http")
+
+		switch strings.ToLower(sink) {
+		case "file":
+			v.check(c.OutboxFile != "", "CART_OUTBOX_FILE", "is required by the file sink")
+		case "http":
+			v.httpURL(c.OutboxURL, "CART_OUTBOX_URL")
+		}
+	}
+
+	if strings.HasPrefix(c.JWKS, "http://") || strings.HasPrefix(c.JWKS, "https://") {
+		v.httpURL(c.JWKS, "CART_JWT_JWKS")
+	}
+
+	v.check(c.TrustedProxies >= 0, "CART_TRUSTED_PROXIES", "must not be negative")
+	v.check(c.WebhookMaxAttempts > 0, "CART_WEBHOOK_MAX_ATTEMPTS", "must be positive")
+	v.oneOf(c.RateLimitStore, "CART_RATE_LIMIT_STORE", "memory", "postgres")
+
+	for env, limit := range map[string]int{
+		"CART_MAX_CART_LINES":     c.MaxCartLines,
+		"CART_MAX_LINE_QUANTITY":  c.MaxLineQuantity,
+		"CART_MAX_CART_UNITS":     c.MaxCartUnits,
+		"CART_MAX_PRODUCT_LENGTH": c.MaxProductLength,
+	} {
+		v.check(limit >= 0, env, "must not be negative")
+	}
+
+	for product, limit := range c.ProductLimits {
+		v.check(limit > 0, "CART_PRODUCT_LIMITS", "limit of %s must be positive", product)
+	}
+
+	v.oneOf(c.LogLevel, "CART_LOG_LEVEL", "trace", "debug", "info", "warn", "error")
+	v.oneOf(c.LogFormat, "CART_LOG_FORMAT", "json", "console")
+	v.oneOf(c.TraceExporter, "CART_TRACE_EXPORTER", "none", "stdout", "otlp")
+
+	for env, d := range map[string]time.Duration{
+		"CART_READ_TIMEOUT":        c.ReadTimeout,
+		"CART_READ_HEADER_TIMEOUT": c.ReadHeaderTimeout,
+		"CART_WRITE_TIMEOUT":       c.WriteTimeout,
+		"CART_IDLE_TIMEOUT":        c.IdleTimeout,
+		"CART_SHUTDOWN_DRAIN":      c.ShutdownDrain,
+		"CART_DB_STARTUP_TIMEOUT":  c.DBStartupTimeout,
+		"CART_DB_QUERY_TIMEOUT":    c.DBQueryTimeout,
+		"CART_OUTBOX_RETENTION":    c.OutboxRetention,
+	} {
+		v.check(d >= 0, env, "must not be negative")
+	}
+
+	for env, d := range map[string]time.Duration{
+		"CART_SHUTDOWN_TIMEOUT":       c.ShutdownTimeout,
+		"CART_TLS_RELOAD_INTERVAL":    c.TLSReloadInterval,
+		"CART_HEALTH_WORKER_MAX_AGE":  c.WorkerMaxAge,
+		"CART_DB_MAX_CONN_LIFETIME":   c.DBMaxConnLifetime,
+		"CART_DB_MAX_CONN_IDLE_TIME":  c.DBMaxConnIdleTime,
+		"CART_DB_HEALTH_CHECK_PERIOD": c.DBHealthCheckPeriod,
+		"CART_DB_CONNECT_TIMEOUT":     c.DBConnectTimeout,
+	} {
+		v.check(d > 0, env, "must be positive")
+	}
+
+	v.check((c.TLSCertFile == "") == (c.TLSKeyFile == ""), "CART_TLS_CERT_FILE", "must be set together with CART_TLS_KEY_FILE")
+	v.oneOf(c.TLSMinVersion, "CART_TLS_MIN_VERSION", "1.2", "1.3")
+	v.oneOf(c.TLSClientAuth, "CART_TLS_CLIENT_AUTH", "none", "optional", "require")
+
+	if !strings.EqualFold(c.TLSClientAuth, "none") {
+		v.check(c.TLSCertFile != "", "CART_TLS_CLIENT_AUTH", "requires CART_TLS_CERT_FILE")
+		v.check(c.TLSClientCAFile != "", "CART_TLS_CLIENT_CA_FILE", "is required by CART_TLS_CLIENT_AUTH")
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(v.problems)
+
+	return &ValidationError{Problems: v.problems}
+}
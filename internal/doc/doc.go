@@ -12,10 +12,28 @@
 //	Produces:
 //	- application/json
 //
+//	SecurityDefinitions:
+//	bearer:
+//	  type: apiKey
+//	  name: Authorization
+//	  in: header
+//	apiKey:
+//	  type: apiKey
+//	  name: X-API-Key
+//	  in: header
+//
+//	Security:
+//	- bearer: []
+//	- apiKey: []
+//
 // swagger:meta
 package doc
 
-import "github.com/fedo3nik/cart-go-api/internal/domain/model"
+import (
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
 
 // swagger:parameters addItemParams addItem
 type addItemParams struct {
@@ -40,13 +58,132 @@ type removeItemParams struct {
 	ItemID int
 }
 
-// swagger:parameters getCartParams getCart
+// swagger:parameters getCartParams getCart getCartV2 cartEvents cartEventsWS cartHistory cartLimits
 type getCartParams struct {
 	// in: path
 	// example: 1
 	CartID int
 }
 
+// swagger:parameters getCartAsOfParams getCart getCartV2
+type getCartAsOfParams struct {
+	// Time at which the state of the cart is returned
+	// in: query
+	// format: date-time
+	AsOf string `json:"as_of"`
+}
+
+// swagger:parameters listCartsParams listCarts
+type listCartsParams struct {
+	// in: query
+	Owner string `json:"owner"`
+	// in: query
+	// example: Hat
+	Product string `json:"product"`
+	// in: query
+	NonEmpty bool `json:"non_empty"`
+	// in: query
+	// format: date-time
+	CreatedAfter string `json:"created_after"`
+	// in: query
+	// format: date-time
+	CreatedBefore string `json:"created_before"`
+	// in: query
+	// format: date-time
+	UpdatedAfter string `json:"updated_after"`
+	// in: query
+	// format: date-time
+	UpdatedBefore string `json:"updated_before"`
+	// Sort field, prefix "-" sorts in the descending order
+	// in: query
+	// enum: id,-id,created_at,-created_at,updated_at,-updated_at
+	Sort string `json:"sort"`
+	// in: query
+	// example: 20
+	Limit int `json:"limit"`
+	// Opaque cursor from the next_cursor field or the Link header
+	// in: query
+	Cursor string `json:"cursor"`
+}
+
+// swagger:parameters webhookParams getWebhook deleteWebhook listDeliveries updateWebhook
+type webhookParams struct {
+	// in: path
+	// example: 1
+	WebhookID int
+}
+
+// swagger:parameters webhookBodyParams createWebhook updateWebhook
+type webhookBodyParams struct {
+	// in: body
+	// example: https://partner.example.com/hooks/cart
+	URL string `json:"url"`
+	// Types of the domain events to deliver, empty means all the events
+	// in: body
+	// example: ["ItemAdded","ItemRemoved"]
+	Events []string `json:"events"`
+	// Secret used to sign the payloads, generated if blank
+	// in: body
+	Secret string `json:"secret"`
+	// in: body
+	// example: true
+	Active bool `json:"active"`
+}
+
+// swagger:parameters apiKeyParams rotateAPIKey revokeAPIKey
+type apiKeyParams struct {
+	// in: path
+	// example: 1
+	KeyID int
+}
+
+// swagger:parameters apiKeyBodyParams createAPIKey
+type apiKeyBodyParams struct {
+	// Name of the client which uses the key
+	// in: body
+	// example: billing
+	Name string `json:"name"`
+	// in: body
+	// example: ["cart:read","cart:write"]
+	Scopes []string `json:"scopes"`
+	// Roles of the client, shopper by default
+	// in: body
+	// example: ["support"]
+	Roles []string `json:"roles"`
+	// Time after which the key is rejected, never by default
+	// in: body
+	// format: date-time
+	ExpiresAt string `json:"expires_at"`
+}
+
+// swagger:parameters listDeliveriesParams listDeliveries
+type listDeliveriesParams struct {
+	// in: query
+	// example: 50
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters listAuditParams listAudit
+type listAuditParams struct {
+	// in: query
+	// example: 1
+	CartID int `json:"cart_id"`
+	// in: query
+	Actor string `json:"actor"`
+	// in: query
+	// format: date-time
+	From string `json:"from"`
+	// in: query
+	// format: date-time
+	To string `json:"to"`
+	// in: query
+	// example: 50
+	Limit int `json:"limit"`
+	// Opaque cursor from the next_cursor field
+	// in: query
+	Cursor string `json:"cursor"`
+}
+
 // New cart created successfully
 // swagger:response createCartResponse
 type createCartResponse struct {
@@ -83,9 +220,198 @@ type getCartResponse struct {
 	Items []model.CartItem `json:"items"`
 }
 
-// Error caused
+// Page of the carts, the Link header contains the first and the next page URLs
+// swagger:response listCartsResponse
+type listCartsResponse struct {
+	// Carts of the page with their items
+	Carts []model.Cart `json:"carts"`
+	// Cursor of the next page, absent on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// Stream of the cart events, every event has the type item_added or item_removed
+// swagger:response cartEventsResponse
+type cartEventsResponse struct {
+	// Type of the event
+	Type string `json:"type"`
+	// ID of the changed cart
+	CartID int `json:"cart_id"`
+	// Changed item
+	Item model.CartItem `json:"item"`
+	// Time when the change happened
+	OccurredAt string `json:"occurred_at"`
+}
+
+// The webhook subscription, the secret is returned only when the webhook is created
+// swagger:response webhookResponse
+type webhookResponse struct {
+	// ID of the webhook
+	ID int `json:"id"`
+	// URL to which the events are posted
+	URL string `json:"url"`
+	// Types of the domain events to deliver
+	Events []string `json:"events"`
+	// Secret used to sign the payloads
+	Secret string `json:"secret"`
+	// Inactive webhooks don't receive new events
+	Active bool `json:"active"`
+	// Time when the webhook was created
+	CreatedAt string `json:"created_at"`
+}
+
+// All the webhook subscriptions
+// swagger:response listWebhooksResponse
+type listWebhooksResponse struct {
+	// in: body
+	Body []model.Webhook
+}
+
+// Webhook deleted successfully
+// swagger:response deleteWebhookResponse
+type deleteWebhookResponse struct {
+}
+
+// Latest deliveries of the webhook, newest first
+// swagger:response listDeliveriesResponse
+type listDeliveriesResponse struct {
+	// Deliveries with their status: pending, delivered or dead
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+}
+
+// Usage of a limit of the cart
+type limitUsage struct {
+	// Limit, null if there is no limit
+	Limit *int `json:"limit"`
+	// Used capacity
+	Used int `json:"used"`
+	// Remaining capacity, null if there is no limit
+	Remaining *int `json:"remaining"`
+}
+
+// Limits of the cart and their remaining capacity
+// swagger:response cartLimitsResponse
+type cartLimitsResponse struct {
+	// ID of the cart
+	CartID int `json:"cart_id"`
+	// Number of the items
+	Lines limitUsage `json:"lines"`
+	// Total quantity of the items
+	Units limitUsage `json:"units"`
+	// Maximum quantity of the single item, null if there is no limit
+	MaxLineQuantity *int `json:"max_line_quantity"`
+	// Maximum length of the product name, null if there is no limit
+	MaxProductLength *int `json:"max_product_length"`
+	// Purchase limits of the products ordered by name
+	Products []struct {
+		// Name of the product
+		Product string `json:"product"`
+		// Maximum quantity of the product in the cart
+		Limit int `json:"limit"`
+		// Quantity of the product in the cart
+		Used int `json:"used"`
+		// Quantity of the product which may be added
+		Remaining int `json:"remaining"`
+	} `json:"products"`
+}
+
+// All the events of the cart ordered by version
+// swagger:response cartHistoryResponse
+type cartHistoryResponse struct {
+	// ID of the cart
+	CartID int `json:"cart_id"`
+	// Events of the cart
+	Events []struct {
+		// Version of the cart after the event
+		Version int `json:"version"`
+		// Type of the domain event: CartCreated, ItemAdded or ItemRemoved
+		Type string `json:"type"`
+		// Payload of the domain event
+		Data map[string]interface{} `json:"data"`
+		// Time when the event was recorded
+		OccurredAt string `json:"occurred_at"`
+	} `json:"events"`
+}
+
+// Page of the audit log entries, newest first
+// swagger:response auditLogResponse
+type auditLogResponse struct {
+	// Entries of the page
+	Entries []model.AuditEntry `json:"entries"`
+	// Cursor of the next page, absent on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// The API key, the key itself is returned only when the API key is created or rotated
+// swagger:response apiKeyResponse
+type apiKeyResponse struct {
+	// ID of the API key
+	ID int `json:"id"`
+	// Name of the client which uses the key
+	Name string `json:"name"`
+	// First characters of the key
+	Prefix string `json:"prefix"`
+	// Scopes granted to the client
+	Scopes []string `json:"scopes"`
+	// Roles of the client, empty means shopper
+	Roles []string `json:"roles"`
+	// Plain key, store it since it is not shown again
+	Key string `json:"key"`
+	// Time after which the key is rejected
+	ExpiresAt string `json:"expires_at"`
+	// Time when the key was last used
+	LastUsedAt string `json:"last_used_at"`
+	// Time when the key was created
+	CreatedAt string `json:"created_at"`
+	// Time when the key was revoked
+	RevokedAt string `json:"revoked_at"`
+}
+
+// All the API keys including the revoked ones
+// swagger:response listAPIKeysResponse
+type listAPIKeysResponse struct {
+	// in: body
+	Body []model.APIKey
+}
+
+// API key revoked successfully
+// swagger:response revokeAPIKeyResponse
+type revokeAPIKeyResponse struct {
+}
+
+// Health of the application, the checks are returned only by /health/details
+// swagger:response healthResponse
+type healthResponse struct {
+	// Status of the application: ok, failing or draining
+	Status string `json:"status"`
+	// Time when the application was started
+	StartedAt time.Time `json:"started_at"`
+	// Time since the application was started
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	// Results of the checks: database, migrations, outbox_relay and webhook_dispatcher
+	Checks []struct {
+		// Name of the check
+		Name string `json:"name"`
+		// Status of the check: ok or failing
+		Status string `json:"status"`
+		// Error of the failing check
+		Error string `json:"error"`
+		// Duration of the check in milliseconds
+		DurationMS float64 `json:"duration_ms"`
+	} `json:"checks"`
+}
+
+// Error caused, returned as application/problem+json
 // swagger:response errorResponse
 type errorResponse struct {
-	// Error message
-	Message string `json:"message"`
+	// URI reference that identifies the problem type
+	Type string `json:"type"`
+	// Short summary of the problem type
+	Title string `json:"title"`
+	// HTTP status code
+	Status int `json:"status"`
+	// Explanation of the problem type, the underlying error is logged instead
+	Detail string `json:"detail"`
+	// Machine-readable error code
+	// example: cart_not_found
+	Code string `json:"code"`
 }
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// APIKey represents a credential of the server-to-server client.
+// Only the hash of the key is stored, the key itself is known only after it is created or rotated.
+type APIKey struct {
+	ID         int       // ID of the key
+	Name       string    // Name of the client which uses the key
+	Prefix     string    // First characters of the key to recognize it
+	Scopes     []string  // Scopes granted to the client
+	Roles      []string  // Roles of the client evaluated by the policy, no roles means shopper
+	ExpiresAt  time.Time // Time after which the key is rejected, zero means never
+	LastUsedAt time.Time // Time when the key was last used, zero if it was never used
+	CreatedAt  time.Time // Time when the key was created
+	RevokedAt  time.Time // Time when the key was revoked, zero for the active keys
+	Key        string    // Plain key, set only when the key is created or rotated
+}
+
+// Expired reports whether the key is expired at the time.
+func (k *APIKey) Expired(at time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !at.Before(k.ExpiresAt)
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditActionCreateCart = "create_cart"
+	AuditActionAddItem    = "add_item"
+	AuditActionRemoveItem = "remove_item"
+)
+
+// AuditEntry represents a record of the audit log about who changed the cart and how.
+type AuditEntry struct {
+	ID         int64           // ID of the entry
+	CartID     int             // ID of the changed cart
+	Action     string          // Action, one of the AuditAction constants
+	Actor      string          // Authenticated user or client that made the change
+	ClientIP   string          // IP address of the client
+	UserAgent  string          // User agent of the client
+	RequestID  string          // ID of the request that made the change
+	Before     json.RawMessage // State of the changed entity before the change, null if it didn't exist
+	After      json.RawMessage // State of the changed entity after the change, null if it was removed
+	OccurredAt time.Time       // Time of the change
+}
+
+// AuditFilter represents the filters of the audit log query.
+type AuditFilter struct {
+	CartID   int       // Only entries of the cart, 0 means any cart
+	Actor    string    // Only entries of the actor, empty means any actor
+	From     time.Time // Only entries which occurred at or after the time, zero means no lower bound
+	To       time.Time // Only entries which occurred before the time, zero means no upper bound
+	BeforeID int64     // Only entries older than the entry with the ID, 0 means from the latest entry
+	Limit    int       // Maximum number of the entries
+	Cursor   string    // Opaque cursor of the page returned by the previous call
+}
+
+// AuditPage represents a page of the audit log, newest entries first.
+type AuditPage struct {
+	Entries    []AuditEntry // Entries of the page
+	NextCursor string       // Cursor of the next page, empty on the last page
+}
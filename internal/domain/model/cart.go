@@ -1,7 +1,12 @@
 package model
 
+import "time"
+
 // Cart represents shopping cart in the online store.
 type Cart struct {
-	ID    int // ID of the cart
-	Items []CartItem
+	ID        int       // ID of the cart
+	Owner     string    // Owner of the cart, empty if the cart is anonymous
+	CreatedAt time.Time // Time when the cart was created
+	UpdatedAt time.Time // Time when the cart or its items were changed last time
+	Items     []CartItem
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Types of the cart events.
+const (
+	CartEventItemAdded   = "item_added"
+	CartEventItemRemoved = "item_removed"
+)
+
+// CartEvent represents a change of the cart items.
+type CartEvent struct {
+	Type       string    // Type of the event, one of the CartEvent constants
+	CartID     int       // ID of the changed cart
+	Item       CartItem  // Item which was changed, only ID and CartID are set for the removed items
+	OccurredAt time.Time // Time when the change happened
+}
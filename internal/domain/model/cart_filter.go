@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Fields by which the list of carts can be sorted.
+const (
+	CartSortID        = "id"
+	CartSortCreatedAt = "created_at"
+	CartSortUpdatedAt = "updated_at"
+)
+
+// CartFilter represents the criteria for listing carts.
+// Zero values of the fields mean that the criterion is not applied.
+type CartFilter struct {
+	IDs           []int     // IDs of the carts
+	Owner         string    // Owner of the carts
+	CreatedAfter  time.Time // Lower bound of the creation time, inclusive
+	CreatedBefore time.Time // Upper bound of the creation time, exclusive
+	UpdatedAfter  time.Time // Lower bound of the last update time, inclusive
+	UpdatedBefore time.Time // Upper bound of the last update time, exclusive
+	NonEmpty      bool      // Only carts with at least one item
+	Product       string    // Only carts which contain an item with this product
+	Sort          string    // Field by which carts are sorted, one of the CartSort constants
+	Desc          bool      // Sort in the descending order
+	Limit         int       // Maximum number of carts in the page
+	Cursor        string    // Opaque cursor of the page returned by the previous call
+}
+
+// CartKey represents the position of the cart in the sorted list.
+// It is used for keyset pagination.
+type CartKey struct {
+	ID   int       // ID of the cart
+	Time time.Time // Value of the sort field if carts are sorted by time
+}
+
+// CartPage represents a single page of the carts list.
+type CartPage struct {
+	Carts      []Cart
+	NextCursor string // Opaque cursor of the next page, empty if it is the last page
+}
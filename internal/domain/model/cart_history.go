@@ -0,0 +1,117 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CartHistoryEvent represents a domain event in the append-only history of the cart.
+type CartHistoryEvent struct {
+	CartID     int         // ID of the changed cart
+	Version    int         // Version of the cart after the event, starts from 1 for CartCreated
+	Event      DomainEvent // The domain event
+	OccurredAt time.Time   // Time when the event was recorded
+}
+
+// CartSnapshot represents the state of the cart folded from the history up to the version.
+type CartSnapshot struct {
+	Version int       // Version of the last folded event
+	TakenAt time.Time // Time of the last folded event
+	Cart    Cart      // State of the cart
+}
+
+// DecodeDomainEvent decodes the json payload of the domain event of the type.
+// Returns an error if the type is unknown or the payload can't be decoded.
+func DecodeDomainEvent(eventType string, payload []byte) (DomainEvent, error) {
+	var (
+		event DomainEvent
+		err   error
+	)
+
+	switch eventType {
+	case EventCartCreated:
+		var ev CartCreated
+		err = json.Unmarshal(payload, &ev)
+		event = ev
+	case EventItemAdded:
+		var ev ItemAdded
+		err = json.Unmarshal(payload, &ev)
+		event = ev
+	case EventItemRemoved:
+		var ev ItemRemoved
+		err = json.Unmarshal(payload, &ev)
+		event = ev
+	default:
+		return nil, fmt.Errorf("unknown domain event type %q", eventType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// Apply folds the history event into the cart.
+// Events are expected in the version order, events of other carts are ignored.
+func (c *Cart) Apply(he *CartHistoryEvent) {
+	if c.ID != 0 && c.ID != he.CartID {
+		return
+	}
+
+	switch ev := he.Event.(type) {
+	case CartCreated:
+		c.ID = ev.CartID
+		c.Owner = ev.Owner
+		c.CreatedAt = he.OccurredAt
+		c.Items = []CartItem{}
+	case ItemAdded:
+		c.Items = append(c.Items, CartItem{ID: ev.ItemID, CartID: ev.CartID, Product: ev.Product, Quantity: ev.Quantity})
+	case ItemRemoved:
+		for i := range c.Items {
+			if c.Items[i].ID == ev.ItemID {
+				c.Items = append(c.Items[:i:i], c.Items[i+1:]...)
+
+				break
+			}
+		}
+	}
+
+	c.UpdatedAt = he.OccurredAt
+}
+
+// ReplayCart rebuilds the cart by folding the events on top of the snapshot.
+// The snapshot may be nil, then the events should start from CartCreated.
+// Events with versions not greater than the snapshot version are skipped.
+// Returns nil if there is no snapshot and no events.
+func ReplayCart(snapshot *CartSnapshot, events []CartHistoryEvent) *Cart {
+	var (
+		cart    *Cart
+		version int
+	)
+
+	if snapshot != nil {
+		c := snapshot.Cart
+		c.Items = append([]CartItem{}, snapshot.Cart.Items...)
+		cart, version = &c, snapshot.Version
+	}
+
+	for i := range events {
+		if events[i].Version <= version {
+			continue
+		}
+
+		if cart == nil {
+			cart = &Cart{}
+		}
+
+		cart.Apply(&events[i])
+	}
+
+	if cart != nil && cart.Items == nil {
+		cart.Items = []CartItem{}
+	}
+
+	return cart
+}
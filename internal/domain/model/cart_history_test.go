@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeDomainEvent(t *testing.T) {
+	events := []DomainEvent{
+		CartCreated{CartID: 1, Owner: "alice"},
+		ItemAdded{CartID: 1, ItemID: 2, Product: "Hat", Quantity: 3},
+		ItemRemoved{CartID: 1, ItemID: 2},
+	}
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		decoded, err := DecodeDomainEvent(event.EventType(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, event, decoded)
+	}
+
+	_, err := DecodeDomainEvent("Unknown", []byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestReplayCart(t *testing.T) {
+	t0 := time.Date(2021, 5, 3, 14, 0, 0, 0, time.UTC)
+	events := []CartHistoryEvent{
+		{CartID: 1, Version: 1, Event: CartCreated{CartID: 1, Owner: "alice"}, OccurredAt: t0},
+		{CartID: 1, Version: 2, Event: ItemAdded{CartID: 1, ItemID: 1, Product: "Hat", Quantity: 1}, OccurredAt: t0.Add(time.Minute)},
+		{CartID: 1, Version: 3, Event: ItemAdded{CartID: 1, ItemID: 2, Product: "Shoes", Quantity: 2}, OccurredAt: t0.Add(2 * time.Minute)},
+		{CartID: 1, Version: 4, Event: ItemAdded{CartID: 1, ItemID: 3, Product: "Socks", Quantity: 5}, OccurredAt: t0.Add(3 * time.Minute)},
+		{CartID: 1, Version: 5, Event: ItemRemoved{CartID: 1, ItemID: 1}, OccurredAt: t0.Add(4 * time.Minute)},
+	}
+
+	full := ReplayCart(nil, events)
+
+	tt := []struct {
+		name     string
+		snapshot *CartSnapshot
+		events   []CartHistoryEvent
+		expected *Cart
+	}{
+		{
+			name:     "No events",
+			expected: nil,
+		},
+		{
+			name:     "Created",
+			events:   events[:1],
+			expected: &Cart{ID: 1, Owner: "alice", CreatedAt: t0, UpdatedAt: t0, Items: []CartItem{}},
+		},
+		{
+			name:   "All events",
+			events: events,
+			expected: &Cart{
+				ID: 1, Owner: "alice", CreatedAt: t0, UpdatedAt: t0.Add(4 * time.Minute),
+				Items: []CartItem{{ID: 2, CartID: 1, Product: "Shoes", Quantity: 2}, {ID: 3, CartID: 1, Product: "Socks", Quantity: 5}},
+			},
+		},
+		{
+			name:     "Snapshot and the following events",
+			snapshot: &CartSnapshot{Version: 3, TakenAt: t0.Add(2 * time.Minute), Cart: *ReplayCart(nil, events[:3])},
+			events:   events,
+			expected: full,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ReplayCart(tc.snapshot, tc.events))
+		})
+	}
+}
+
+func TestReplayCart_doesNotMutateSnapshot(t *testing.T) {
+	snapshot := CartSnapshot{Version: 1, Cart: Cart{ID: 1, Items: []CartItem{{ID: 1, CartID: 1, Quantity: 1}, {ID: 2, CartID: 1, Quantity: 2}}}}
+
+	ReplayCart(&snapshot, []CartHistoryEvent{
+		{CartID: 1, Version: 2, Event: ItemRemoved{CartID: 1, ItemID: 1}},
+	})
+
+	assert.Equal(t, []CartItem{{ID: 1, CartID: 1, Quantity: 1}, {ID: 2, CartID: 1, Quantity: 2}}, snapshot.Cart.Items)
+}
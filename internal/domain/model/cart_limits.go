@@ -0,0 +1,82 @@
+package model
+
+import "sort"
+
+// CartLimits represents the business limits of the carts, zero value of a limit means no limit.
+type CartLimits struct {
+	MaxLines         int            // Maximum number of the items in the cart
+	MaxLineQuantity  int            // Maximum quantity of the single item
+	MaxUnits         int            // Maximum total quantity of all the items in the cart
+	MaxProductLength int            // Maximum length of the product name in characters
+	ProductLimits    map[string]int // Maximum total quantity of the product in the cart by the product name
+}
+
+// Enforced reports whether any of the limits depending on the content of the cart is set.
+func (l *CartLimits) Enforced() bool {
+	return l.MaxLines > 0 || l.MaxUnits > 0 || len(l.ProductLimits) > 0
+}
+
+// LimitUsage represents the usage of the single limit.
+type LimitUsage struct {
+	Limit int // Limit, zero means no limit
+	Used  int // Used capacity
+}
+
+// Remaining returns the remaining capacity, -1 if there is no limit.
+func (u LimitUsage) Remaining() int {
+	if u.Limit <= 0 {
+		return -1
+	}
+
+	if u.Used >= u.Limit {
+		return 0
+	}
+
+	return u.Limit - u.Used
+}
+
+// ProductLimitUsage represents the usage of the purchase limit of the product.
+type ProductLimitUsage struct {
+	Product string // Name of the product
+	LimitUsage
+}
+
+// CartLimitsReport represents the usage of the limits by the cart.
+type CartLimitsReport struct {
+	CartID           int                 // ID of the cart
+	Lines            LimitUsage          // Number of the items
+	Units            LimitUsage          // Total quantity of the items
+	MaxLineQuantity  int                 // Maximum quantity of the single item, zero means no limit
+	MaxProductLength int                 // Maximum length of the product name, zero means no limit
+	Products         []ProductLimitUsage // Purchase limits of the products ordered by the product name
+}
+
+// Report reports the usage of the limits by the items of the cart.
+func (l *CartLimits) Report(cart *Cart) *CartLimitsReport {
+	r := CartLimitsReport{
+		CartID:           cart.ID,
+		Lines:            LimitUsage{Limit: l.MaxLines, Used: len(cart.Items)},
+		Units:            LimitUsage{Limit: l.MaxUnits},
+		MaxLineQuantity:  l.MaxLineQuantity,
+		MaxProductLength: l.MaxProductLength,
+		Products:         make([]ProductLimitUsage, 0, len(l.ProductLimits)),
+	}
+
+	units := map[string]int{}
+
+	for _, item := range cart.Items {
+		r.Units.Used += item.Quantity
+		units[item.Product] += item.Quantity
+	}
+
+	for product, limit := range l.ProductLimits {
+		r.Products = append(r.Products, ProductLimitUsage{
+			Product:    product,
+			LimitUsage: LimitUsage{Limit: limit, Used: units[product]},
+		})
+	}
+
+	sort.Slice(r.Products, func(i, j int) bool { return r.Products[i].Product < r.Products[j].Product })
+
+	return &r
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of the domain events.
+const (
+	EventCartCreated = "CartCreated"
+	EventItemAdded   = "ItemAdded"
+	EventItemRemoved = "ItemRemoved"
+)
+
+// DomainEvent is the interface that describes a fact about the cart change for the downstream systems.
+type DomainEvent interface {
+	EventType() string // Type of the event, one of the Event constants
+	EventCartID() int  // ID of the cart which was changed
+}
+
+// CartCreated is a domain event of the cart creation.
+type CartCreated struct {
+	CartID int    `json:"cart_id"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+// EventType returns EventCartCreated.
+func (CartCreated) EventType() string { return EventCartCreated }
+
+// EventCartID returns ID of the created cart.
+func (ev CartCreated) EventCartID() int { return ev.CartID }
+
+// ItemAdded is a domain event of adding an item to the cart.
+type ItemAdded struct {
+	CartID   int    `json:"cart_id"`
+	ItemID   int    `json:"item_id"`
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+}
+
+// EventType returns EventItemAdded.
+func (ItemAdded) EventType() string { return EventItemAdded }
+
+// EventCartID returns ID of the cart to which the item was added.
+func (ev ItemAdded) EventCartID() int { return ev.CartID }
+
+// ItemRemoved is a domain event of removing an item from the cart.
+type ItemRemoved struct {
+	CartID int `json:"cart_id"`
+	ItemID int `json:"item_id"`
+}
+
+// EventType returns EventItemRemoved.
+func (ItemRemoved) EventType() string { return EventItemRemoved }
+
+// EventCartID returns ID of the cart from which the item was removed.
+func (ev ItemRemoved) EventCartID() int { return ev.CartID }
+
+// OutboxMessage represents a domain event stored in the outbox for the delivery to the sinks.
+type OutboxMessage struct {
+	ID             int64           `json:"id"`          // ID of the message, sinks can use it to drop duplicates
+	Type           string          `json:"type"`        // Type of the domain event
+	CartID         int             `json:"cart_id"`     // ID of the changed cart
+	Payload        json.RawMessage `json:"payload"`     // Domain event encoded as json
+	OccurredAt     time.Time       `json:"occurred_at"` // Time when the event was recorded
+	Attempts       int             `json:"attempts"`    // Number of the failed delivery attempts
+	TraceParent    string          `json:"-"`           // W3C traceparent of the request which caused the event, blank if it wasn't traced
+	DeliveredSinks []string        `json:"-"`           // Names of the sinks which already accepted the message
+}
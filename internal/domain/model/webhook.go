@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Statuses of the webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook represents a partner subscription to the domain events.
+type Webhook struct {
+	ID        int       // ID of the webhook
+	URL       string    // URL to which the events are posted
+	Events    []string  // Types of the domain events to deliver, empty means all the events
+	Secret    string    // Secret used to sign the payloads
+	Active    bool      // Inactive webhooks don't receive new events
+	CreatedAt time.Time // Time when the webhook was created
+}
+
+// WebhookDelivery represents a single domain event scheduled for the delivery to the webhook.
+type WebhookDelivery struct {
+	ID             int64           // ID of the delivery
+	WebhookID      int             // ID of the webhook
+	EventID        int64           // ID of the outbox message
+	EventType      string          // Type of the domain event
+	Payload        json.RawMessage // Body posted to the webhook
+	Status         string          // Status of the delivery, one of the Delivery constants
+	Attempts       int             // Number of the failed attempts
+	LastStatusCode int             // HTTP status of the last attempt, 0 if the request failed
+	LastError      string          // Error of the last attempt
+	NextAttemptAt  time.Time       // Time of the next attempt
+	CreatedAt      time.Time       // Time when the delivery was scheduled
+	DeliveredAt    time.Time       // Time when the webhook accepted the event
+	URL            string          // URL of the webhook, set for the deliveries locked for dispatching
+	Secret         string          // Secret of the webhook, set for the deliveries locked for dispatching
+	TraceParent    string          // W3C traceparent of the request which caused the event, set for the deliveries locked for dispatching
+}
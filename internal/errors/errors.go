@@ -16,3 +16,54 @@ var ErrInvalidCartID = errors.New("cart with the same ID does not exist")
 
 // ErrRemove is a custom error that returns if user try to remove non-existent item or from non-existing cart.
 var ErrRemove = errors.New("cart or item with these IDs does not exist")
+
+// ErrInvalidRequest is a custom error that returns if the request path parameters or body can't be parsed.
+var ErrInvalidRequest = errors.New("request is malformed")
+
+// ErrMethodNotAllowed is a custom error that returns if the route doesn't support the request method.
+var ErrMethodNotAllowed = errors.New("method is not allowed for this resource")
+
+// ErrInvalidCursor is a custom error that returns if the pagination cursor can't be decoded or doesn't match the query.
+var ErrInvalidCursor = errors.New("pagination cursor is invalid")
+
+// ErrInvalidFilter is a custom error that returns if the filter or sort parameters of the list are invalid.
+var ErrInvalidFilter = errors.New("list filter is invalid")
+
+// ErrInvalidWebhook is a custom error that returns if the webhook URL or event filter is invalid.
+var ErrInvalidWebhook = errors.New("webhook URL or events are invalid")
+
+// ErrWebhookNotFound is a custom error that returns if webhook with the same ID doesn't exist.
+var ErrWebhookNotFound = errors.New("webhook with the same ID does not exist")
+
+// ErrUnauthenticated is a custom error that returns if the credentials are missing or invalid.
+var ErrUnauthenticated = errors.New("authentication is required")
+
+// ErrForbidden is a custom error that returns if the caller lacks the scope required by the operation.
+var ErrForbidden = errors.New("caller is not allowed to perform this operation")
+
+// ErrInvalidAPIKey is a custom error that returns if the name, scopes or expiry of the API key are invalid.
+var ErrInvalidAPIKey = errors.New("api key name, scopes or expiry are invalid")
+
+// ErrAPIKeyNotFound is a custom error that returns if active API key with the same ID doesn't exist.
+var ErrAPIKeyNotFound = errors.New("active api key with the same ID does not exist")
+
+// ErrAccessDenied is a custom error that returns if the roles of the caller don't allow the operation on the cart.
+var ErrAccessDenied = errors.New("caller's role does not allow this operation on the cart")
+
+// ErrRateLimited is a custom error that returns if the client exceeded the rate limit of the route.
+var ErrRateLimited = errors.New("rate limit is exceeded, retry later")
+
+// ErrProductNameTooLong is a custom error that returns if product title is longer than the limit.
+var ErrProductNameTooLong = errors.New("products name is too long")
+
+// ErrLineQuantityExceeded is a custom error that returns if quantity of the item exceeds the limit.
+var ErrLineQuantityExceeded = errors.New("products quantity exceeds the limit of the item")
+
+// ErrTooManyLines is a custom error that returns if the cart already has the maximum number of items.
+var ErrTooManyLines = errors.New("cart has the maximum number of items")
+
+// ErrCartUnitsExceeded is a custom error that returns if total quantity of the items in the cart exceeds the limit.
+var ErrCartUnitsExceeded = errors.New("total quantity of the items in the cart exceeds the limit")
+
+// ErrProductLimitExceeded is a custom error that returns if quantity of the product in the cart exceeds its purchase limit.
+var ErrProductLimitExceeded = errors.New("quantity of the product in the cart exceeds its purchase limit")
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS items_touch_cart ON Items;
+DROP FUNCTION IF EXISTS touch_cart();
+
+DROP INDEX IF EXISTS idx_items_product_name;
+DROP INDEX IF EXISTS idx_items_cart_id;
+DROP INDEX IF EXISTS idx_carts_updated_at_id;
+DROP INDEX IF EXISTS idx_carts_created_at_id;
+DROP INDEX IF EXISTS idx_carts_owner_id;
+
+ALTER TABLE Carts DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE Carts DROP COLUMN IF EXISTS created_at;
+ALTER TABLE Carts DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,25 @@
+ALTER TABLE Carts ADD COLUMN IF NOT EXISTS owner varchar(255);
+ALTER TABLE Carts ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE Carts ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_carts_owner_id ON Carts(owner, ID);
+CREATE INDEX IF NOT EXISTS idx_carts_created_at_id ON Carts(created_at, ID);
+CREATE INDEX IF NOT EXISTS idx_carts_updated_at_id ON Carts(updated_at, ID);
+CREATE INDEX IF NOT EXISTS idx_items_cart_id ON Items(cartID);
+CREATE INDEX IF NOT EXISTS idx_items_product_name ON Items(product_name);
+
+CREATE OR REPLACE FUNCTION touch_cart() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    UPDATE Carts SET updated_at = now() WHERE ID = OLD.cartID;
+    RETURN OLD;
+  END IF;
+
+  UPDATE Carts SET updated_at = now() WHERE ID = NEW.cartID;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER items_touch_cart
+  AFTER INSERT OR UPDATE OR DELETE ON Items
+  FOR EACH ROW EXECUTE PROCEDURE touch_cart();
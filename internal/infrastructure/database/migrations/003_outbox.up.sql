@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS Outbox(
+  ID bigserial PRIMARY KEY,
+  event_type varchar(64) NOT NULL,
+  cartID integer NOT NULL,
+  payload jsonb NOT NULL,
+  occurred_at timestamptz NOT NULL DEFAULT now(),
+  delivered_at timestamptz,
+  attempts integer NOT NULL DEFAULT 0,
+  last_error text,
+  next_attempt_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON Outbox(next_attempt_at, ID) WHERE delivered_at IS NULL;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS Webhooks(
+  ID serial PRIMARY KEY,
+  url text NOT NULL,
+  events text[] NOT NULL DEFAULT '{}',
+  secret text NOT NULL,
+  active boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS Webhook_deliveries(
+  ID bigserial PRIMARY KEY,
+  webhookID integer NOT NULL,
+  event_id bigint NOT NULL,
+  event_type varchar(64) NOT NULL,
+  payload jsonb NOT NULL,
+  status varchar(16) NOT NULL DEFAULT 'pending',
+  attempts integer NOT NULL DEFAULT 0,
+  last_status_code integer,
+  last_error text,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  delivered_at timestamptz,
+  CONSTRAINT fk_webhook FOREIGN KEY(webhookID) REFERENCES Webhooks(ID) ON DELETE CASCADE,
+  CONSTRAINT uq_webhook_event UNIQUE (webhookID, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON Webhook_deliveries(next_attempt_at, ID) WHERE status = 'pending';
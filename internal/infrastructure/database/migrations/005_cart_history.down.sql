@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS Cart_snapshots;
+DROP TABLE IF EXISTS Cart_events;
+
+ALTER TABLE Carts DROP COLUMN IF EXISTS version;
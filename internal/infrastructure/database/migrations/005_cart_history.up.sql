@@ -0,0 +1,36 @@
+ALTER TABLE Carts ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS Cart_events(
+  cartID integer NOT NULL,
+  version integer NOT NULL,
+  event_type varchar(64) NOT NULL,
+  payload jsonb NOT NULL,
+  occurred_at timestamptz NOT NULL DEFAULT clock_timestamp(),
+  PRIMARY KEY (cartID, version),
+  CONSTRAINT fk_cart FOREIGN KEY(cartID) REFERENCES Carts(ID)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_events_occurred_at ON Cart_events(cartID, occurred_at);
+
+CREATE TABLE IF NOT EXISTS Cart_snapshots(
+  cartID integer NOT NULL,
+  version integer NOT NULL,
+  state jsonb NOT NULL,
+  taken_at timestamptz NOT NULL,
+  PRIMARY KEY (cartID, version),
+  CONSTRAINT fk_cart FOREIGN KEY(cartID) REFERENCES Carts(ID)
+);
+
+-- Existing carts get the synthetic history of their current state.
+INSERT INTO Cart_events (cartID, version, event_type, payload, occurred_at)
+SELECT c.ID, 1, 'CartCreated', jsonb_strip_nulls(jsonb_build_object('cart_id', c.ID, 'owner', c.owner)), c.created_at
+FROM Carts c
+WHERE c.version = 0;
+
+INSERT INTO Cart_events (cartID, version, event_type, payload, occurred_at)
+SELECT i.cartID, 1 + row_number() OVER (PARTITION BY i.cartID ORDER BY i.ID), 'ItemAdded',
+  jsonb_build_object('cart_id', i.cartID, 'item_id', i.ID, 'product', i.product_name, 'quantity', i.quantity), c.updated_at
+FROM Items i JOIN Carts c ON c.ID = i.cartID
+WHERE c.version = 0;
+
+UPDATE Carts c SET version = (SELECT MAX(e.version) FROM Cart_events e WHERE e.cartID = c.ID) WHERE c.version = 0;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS Audit_log(
+  ID bigserial PRIMARY KEY,
+  cartID integer NOT NULL,
+  action varchar(32) NOT NULL,
+  actor varchar(255) NOT NULL,
+  client_ip varchar(64) NOT NULL DEFAULT '',
+  user_agent text NOT NULL DEFAULT '',
+  request_id varchar(128) NOT NULL DEFAULT '',
+  before jsonb,
+  after jsonb,
+  occurred_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_cart_id ON Audit_log(cartID, ID);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON Audit_log(actor, ID);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON Audit_log(occurred_at);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS Api_keys(
+  ID serial PRIMARY KEY,
+  name varchar(255) NOT NULL,
+  prefix varchar(16) NOT NULL,
+  key_hash char(64) NOT NULL UNIQUE,
+  scopes text[] NOT NULL DEFAULT '{}',
+  expires_at timestamptz,
+  last_used_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  revoked_at timestamptz
+);
@@ -0,0 +1 @@
+ALTER TABLE Api_keys DROP COLUMN IF EXISTS roles;
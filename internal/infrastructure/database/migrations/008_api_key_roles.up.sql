@@ -0,0 +1 @@
+ALTER TABLE Api_keys ADD COLUMN IF NOT EXISTS roles text[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS Rate_limits(
+  key varchar(512) PRIMARY KEY,
+  tokens double precision NOT NULL DEFAULT 0,
+  updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_updated_at ON Rate_limits(updated_at);
@@ -0,0 +1 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS delivered_sinks text[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,38 @@
+// Package migrations embeds the SQL migrations of the database schema
+// so the application knows the schema version it expects.
+package migrations
+
+import (
+	"embed"
+	"strconv"
+	"strings"
+)
+
+// FS contains the up and down migrations named <version>_<title>.<direction>.sql.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns the version of the latest migration.
+// Also it returns an error if the migrations can't be read or a file name has no version.
+func Latest() (int, error) {
+	entries, err := FS.ReadDir(".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int
+
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			return 0, err
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	latest, err := Latest()
+	require.NoError(t, err)
+
+	entries, err := FS.ReadDir(".")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2*latest, len(entries), "every version must have an up and a down migration")
+
+	matches, err := fs.Glob(FS, fmt.Sprintf("%03d_*.up.sql", latest))
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// apiKeyColumns is a list of the selected API key columns in the order of scanAPIKey.
+const apiKeyColumns = "id, name, prefix, scopes, roles, expires_at, last_used_at, created_at, revoked_at"
+
+// scanAPIKey scans the row selected with apiKeyColumns.
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var (
+		k                                model.APIKey
+		expiresAt, lastUsedAt, revokedAt *time.Time
+	)
+
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.Roles, &expiresAt, &lastUsedAt, &k.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt != nil {
+		k.ExpiresAt = *expiresAt
+	}
+
+	if lastUsedAt != nil {
+		k.LastUsedAt = *lastUsedAt
+	}
+
+	if revokedAt != nil {
+		k.RevokedAt = *revokedAt
+	}
+
+	return &k, nil
+}
+
+// nullTime converts the zero time to NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// InsertAPIKey inserts a new APIKey with the hash of the key in the DB.
+// Returns the ID of a new API key.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if a new API key doesn't inserted in the table.
+func InsertAPIKey(ctx context.Context, p *Pool, k *model.APIKey, hash string) (int, error) {
+	var id int
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer conn.Release()
+
+	err = p.traced(conn).QueryRow(ctx, `INSERT INTO api_keys (name, prefix, key_hash, scopes, roles, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, k.Name, k.Prefix, hash, k.Scopes, k.Roles, nullTime(k.ExpiresAt)).
+		Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAPIKey selects the APIKey from the DB.
+// Returns nil if the API key with the ID doesn't exist.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the API key can't be selected.
+// The transient failures are retried.
+func GetAPIKey(ctx context.Context, p *Pool, id int) (*model.APIKey, error) {
+	var res *model.APIKey
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = getAPIKey(ctx, p, id)
+
+		return err
+	})
+
+	return res, err
+}
+
+// getAPIKey is a single attempt of GetAPIKey.
+func getAPIKey(ctx context.Context, p *Pool, id int) (*model.APIKey, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	k, err := scanAPIKey(p.traced(conn).QueryRow(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return k, err
+}
+
+// GetActiveAPIKeyByHash selects the APIKey which isn't revoked by the hash of the key.
+// Returns nil if there is no such API key.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the API key can't be selected.
+// The transient failures are retried.
+func GetActiveAPIKeyByHash(ctx context.Context, p *Pool, hash string) (*model.APIKey, error) {
+	var res *model.APIKey
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = getActiveAPIKeyByHash(ctx, p, hash)
+
+		return err
+	})
+
+	return res, err
+}
+
+// getActiveAPIKeyByHash is a single attempt of GetActiveAPIKeyByHash.
+func getActiveAPIKeyByHash(ctx context.Context, p *Pool, hash string) (*model.APIKey, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	k, err := scanAPIKey(p.traced(conn).QueryRow(ctx, "SELECT "+apiKeyColumns+
+		" FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return k, err
+}
+
+// ListAPIKeys selects all the APIKeys from the DB ordered by ID.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the error occurred while reading rows.
+// The transient failures are retried.
+func ListAPIKeys(ctx context.Context, p *Pool) ([]model.APIKey, error) {
+	var res []model.APIKey
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = listAPIKeys(ctx, p)
+
+		return err
+	})
+
+	return res, err
+}
+
+// listAPIKeys is a single attempt of ListAPIKeys.
+func listAPIKeys(ctx context.Context, p *Pool) ([]model.APIKey, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := p.traced(conn).Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *k)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return keys, nil
+}
+
+// RotateAPIKey replaces the prefix and the hash of the APIKey which isn't revoked.
+// Returns false if there is no such API key.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the API key can't be updated.
+func RotateAPIKey(ctx context.Context, p *Pool, id int, prefix, hash string) (bool, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Release()
+
+	ct, err := p.traced(conn).Exec(ctx, "UPDATE api_keys SET prefix = $2, key_hash = $3 WHERE id = $1 AND revoked_at IS NULL",
+		id, prefix, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return ct.RowsAffected() == 1, nil
+}
+
+// RevokeAPIKey marks the APIKey as revoked.
+// Returns false if the API key with the ID doesn't exist or is already revoked.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the API key can't be updated.
+func RevokeAPIKey(ctx context.Context, p *Pool, id int) (bool, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Release()
+
+	ct, err := p.traced(conn).Exec(ctx, "UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return false, err
+	}
+
+	return ct.RowsAffected() == 1, nil
+}
+
+// TouchAPIKey updates the last used time of the APIKey.
+// The time is updated at most once per the interval to not write on every request.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the API key can't be updated.
+// The transient failures are retried.
+func TouchAPIKey(ctx context.Context, p *Pool, id int, interval time.Duration) error {
+	return p.Settings.Retry(ctx, func() error {
+		return touchAPIKey(ctx, p, id, interval)
+	})
+}
+
+// touchAPIKey is a single attempt of TouchAPIKey.
+func touchAPIKey(ctx context.Context, p *Pool, id int, interval time.Duration) error {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = p.traced(conn).Exec(ctx, `UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - $2::interval)`, id, interval)
+
+	return err
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
+
+// InsertAuditEntry stores the entry in the audit log using the Querier.
+// It should be called within the transaction of the mutation so the entry is stored only if the mutation is.
+// Returns an error if the entry can't be inserted in the table.
+func InsertAuditEntry(ctx context.Context, q Querier, entry *model.AuditEntry) error {
+	q = traced(q)
+
+	_, err := q.Exec(ctx, `INSERT INTO audit_log (cartID, action, actor, client_ip, user_agent, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		entry.CartID, entry.Action, entry.Actor, entry.ClientIP, entry.UserAgent, entry.RequestID,
+		nullJSON(entry.Before), nullJSON(entry.After))
+
+	return err
+}
+
+// nullJSON converts the empty json to SQL NULL.
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+
+	return b
+}
+
+// ListAuditEntries selects at most f.Limit entries of the audit log matching the filter, newest first.
+// Returns an error if the error occurred while reading rows.
+func ListAuditEntries(ctx context.Context, q Querier, f *model.AuditFilter) ([]model.AuditEntry, error) {
+	q = traced(q)
+
+	var fq filterQuery
+
+	if f.CartID != 0 {
+		fq.add("cartID = $", f.CartID)
+	}
+
+	if f.Actor != "" {
+		fq.add("actor = $", f.Actor)
+	}
+
+	if !f.From.IsZero() {
+		fq.add("occurred_at >= $", f.From)
+	}
+
+	if !f.To.IsZero() {
+		fq.add("occurred_at < $", f.To)
+	}
+
+	if f.BeforeID != 0 {
+		fq.add("id < $", f.BeforeID)
+	}
+
+	sql := `SELECT id, cartID, action, actor, client_ip, user_agent, request_id,
+		COALESCE(before, 'null'::jsonb), COALESCE(after, 'null'::jsonb), occurred_at FROM audit_log`
+	if len(fq.conditions) > 0 {
+		sql += " WHERE " + strings.Join(fq.conditions, " AND ")
+	}
+
+	fq.args = append(fq.args, f.Limit)
+	sql += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(fq.args))
+
+	rows, err := q.Query(ctx, sql, fq.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+
+	for rows.Next() {
+		var entry model.AuditEntry
+
+		err = rows.Scan(&entry.ID, &entry.CartID, &entry.Action, &entry.Actor, &entry.ClientIP, &entry.UserAgent,
+			&entry.RequestID, &entry.Before, &entry.After, &entry.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// AppendCartEvent appends the domain event to the history of the cart using the Querier.
+// It should be called within the transaction of the mutation. The cart row is locked
+// until the end of the transaction, so the versions of the cart events are gapless.
+// Returns the version of the cart after the event.
+// Also it returns an error if the cart doesn't exist or the event can't be inserted.
+func AppendCartEvent(ctx context.Context, q Querier, event model.DomainEvent) (int, error) {
+	q = traced(q)
+
+	var version int
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	err = q.QueryRow(ctx, "UPDATE carts SET version = version + 1 WHERE id = $1 RETURNING version",
+		event.EventCartID()).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.Exec(ctx, "INSERT INTO cart_events (cartID, version, event_type, payload) VALUES ($1, $2, $3, $4)",
+		event.EventCartID(), version, event.EventType(), payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// ListCartEvents selects the events of the cart with versions greater than afterVersion
+// which occurred not later than until, the zero until means no upper bound.
+// Events are ordered by version.
+// Returns an error if the error occurred while reading rows or an event can't be decoded.
+func ListCartEvents(ctx context.Context, q Querier, cartID, afterVersion int, until time.Time) ([]model.CartHistoryEvent, error) {
+	q = traced(q)
+
+	var untilArg interface{}
+	if !until.IsZero() {
+		untilArg = until
+	}
+
+	rows, err := q.Query(ctx, `SELECT version, event_type, payload, occurred_at FROM cart_events
+		WHERE cartID = $1 AND version > $2 AND ($3::timestamptz IS NULL OR occurred_at <= $3)
+		ORDER BY version`, cartID, afterVersion, untilArg)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := []model.CartHistoryEvent{}
+
+	for rows.Next() {
+		var (
+			he        = model.CartHistoryEvent{CartID: cartID}
+			eventType string
+			payload   []byte
+		)
+
+		err = rows.Scan(&he.Version, &eventType, &payload, &he.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		he.Event, err = model.DecodeDomainEvent(eventType, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, he)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return events, nil
+}
+
+// GetLatestSnapshot selects the latest snapshot of the cart taken not later than until,
+// the zero until means no upper bound.
+// Returns nil if there is no such snapshot.
+// Also it returns an error if the snapshot can't be selected or decoded.
+func GetLatestSnapshot(ctx context.Context, q Querier, cartID int, until time.Time) (*model.CartSnapshot, error) {
+	q = traced(q)
+
+	var (
+		s        model.CartSnapshot
+		state    []byte
+		untilArg interface{}
+	)
+
+	if !until.IsZero() {
+		untilArg = until
+	}
+
+	err := q.QueryRow(ctx, `SELECT version, state, taken_at FROM cart_snapshots
+		WHERE cartID = $1 AND ($2::timestamptz IS NULL OR taken_at <= $2)
+		ORDER BY version DESC LIMIT 1`, cartID, untilArg).Scan(&s.Version, &state, &s.TakenAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(state, &s.Cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// InsertCartSnapshot stores the snapshot of the cart using the Querier.
+// Snapshot of the same version is stored only once.
+// Returns an error if the snapshot can't be encoded or inserted.
+func InsertCartSnapshot(ctx context.Context, q Querier, s *model.CartSnapshot) error {
+	q = traced(q)
+
+	state, err := json.Marshal(&s.Cart)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `INSERT INTO cart_snapshots (cartID, version, state, taken_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cartID, version) DO NOTHING`, s.Cart.ID, s.Version, state, s.TakenAt)
+
+	return err
+}
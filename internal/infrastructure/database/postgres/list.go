@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
+
+// sortColumns maps the sort fields of the CartFilter to the table columns.
+var sortColumns = map[string]string{
+	model.CartSortID:        "c.id",
+	model.CartSortCreatedAt: "c.created_at",
+	model.CartSortUpdatedAt: "c.updated_at",
+}
+
+// filterQuery accumulates conditions and arguments of the filtered list queries.
+type filterQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends the condition with a single argument referenced as $ in the condition.
+func (q *filterQuery) add(condition string, arg interface{}) {
+	q.args = append(q.args, arg)
+	q.conditions = append(q.conditions, strings.ReplaceAll(condition, "$", "$"+strconv.Itoa(len(q.args))))
+}
+
+// ListCarts selects carts matching the filter from the DB ordered by the filter sort field and ID.
+// Carts are returned without items. If after isn't nil only the carts positioned after this key are selected.
+// At most limit carts are returned.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the error occurred while reading rows.
+// The transient failures are retried.
+func ListCarts(ctx context.Context, p *Pool, f *model.CartFilter, after *model.CartKey, limit int) ([]model.Cart, error) {
+	var res []model.Cart
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = listCarts(ctx, p, f, after, limit)
+
+		return err
+	})
+
+	return res, err
+}
+
+// listCarts is a single attempt of ListCarts.
+func listCarts(ctx context.Context, p *Pool, f *model.CartFilter, after *model.CartKey, limit int) ([]model.Cart, error) {
+	var q filterQuery
+
+	if len(f.IDs) > 0 {
+		q.add("c.id = ANY($)", f.IDs)
+	}
+
+	if f.Owner != "" {
+		q.add("c.owner = $", f.Owner)
+	}
+
+	if !f.CreatedAfter.IsZero() {
+		q.add("c.created_at >= $", f.CreatedAfter)
+	}
+
+	if !f.CreatedBefore.IsZero() {
+		q.add("c.created_at < $", f.CreatedBefore)
+	}
+
+	if !f.UpdatedAfter.IsZero() {
+		q.add("c.updated_at >= $", f.UpdatedAfter)
+	}
+
+	if !f.UpdatedBefore.IsZero() {
+		q.add("c.updated_at < $", f.UpdatedBefore)
+	}
+
+	if f.NonEmpty {
+		q.conditions = append(q.conditions, "EXISTS (SELECT 1 FROM items i WHERE i.cartID = c.id)")
+	}
+
+	if f.Product != "" {
+		q.add("EXISTS (SELECT 1 FROM items i WHERE i.cartID = c.id AND i.product_name = $)", f.Product)
+	}
+
+	column, ok := sortColumns[f.Sort]
+	if !ok {
+		column = sortColumns[model.CartSortID]
+	}
+
+	cmp, order := ">", "ASC"
+	if f.Desc {
+		cmp, order = "<", "DESC"
+	}
+
+	if after != nil {
+		if column == sortColumns[model.CartSortID] {
+			q.add("c.id "+cmp+" $", after.ID)
+		} else {
+			q.args = append(q.args, after.Time, after.ID)
+			n := len(q.args)
+			q.conditions = append(q.conditions,
+				"("+column+", c.id) "+cmp+" ($"+strconv.Itoa(n-1)+", $"+strconv.Itoa(n)+")")
+		}
+	}
+
+	sql := "SELECT c.id, COALESCE(c.owner, ''), c.created_at, c.updated_at FROM carts c"
+	if len(q.conditions) > 0 {
+		sql += " WHERE " + strings.Join(q.conditions, " AND ")
+	}
+
+	q.args = append(q.args, limit)
+	sql += " ORDER BY " + column + " " + order + ", c.id " + order + " LIMIT $" + strconv.Itoa(len(q.args))
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := p.traced(conn).Query(ctx, sql, q.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	carts := []model.Cart{}
+
+	for rows.Next() {
+		var cart model.Cart
+
+		err = rows.Scan(&cart.ID, &cart.Owner, &cart.CreatedAt, &cart.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		carts = append(carts, cart)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return carts, nil
+}
+
+// GetItemsByCartIDs selects the items of all the carts with the received IDs in a single query.
+// Returns the map from the cart ID to its items, carts without items are absent in the map.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the error occurred while reading rows.
+// The transient failures are retried.
+func GetItemsByCartIDs(ctx context.Context, p *Pool, cartIDs []int) (map[int][]model.CartItem, error) {
+	var res map[int][]model.CartItem
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = getItemsByCartIDs(ctx, p, cartIDs)
+
+		return err
+	})
+
+	return res, err
+}
+
+// getItemsByCartIDs is a single attempt of GetItemsByCartIDs.
+func getItemsByCartIDs(ctx context.Context, p *Pool, cartIDs []int) (map[int][]model.CartItem, error) {
+	items := map[int][]model.CartItem{}
+
+	if len(cartIDs) == 0 {
+		return items, nil
+	}
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := p.traced(conn).Query(ctx,
+		"SELECT id, cartId, product_name, quantity FROM items WHERE cartID = ANY($1) ORDER BY id", cartIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.CartItem
+
+		err = rows.Scan(&item.ID, &item.CartID, &item.Product, &item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		items[item.CartID] = append(items[item.CartID], item)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return items, nil
+}
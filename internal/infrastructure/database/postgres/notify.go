@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// unlistenTimeout is a deadline of stopping listening before the connection is returned to the pool.
+const unlistenTimeout = 5 * time.Second
+
+// EventsChannel is a name of the LISTEN/NOTIFY channel for the cart events.
+const EventsChannel = "cart_events"
+
+// NotifyEvent sends the cart event to all the listeners of the EventsChannel.
+// Returns an error if the connection from the connection pool doesn't acquire or
+// if the notification isn't sent.
+func NotifyEvent(ctx context.Context, p *Pool, event *model.CartEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = p.traced(conn).Exec(ctx, "SELECT pg_notify($1, $2)", EventsChannel, string(payload))
+
+	return err
+}
+
+// ListenEvents listens to the EventsChannel and calls handle for every received cart event.
+// It holds a connection from the pool until the context is canceled, the connection stops listening
+// before it's returned to the pool.
+// Returns an error if the connection from the connection pool doesn't acquire or
+// if the connection is broken while waiting for the notifications.
+func ListenEvents(ctx context.Context, p *Pool, handle func(event *model.CartEvent)) error {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release(conn)
+
+	_, err = conn.Exec(ctx, "LISTEN "+EventsChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event model.CartEvent
+
+		err = json.Unmarshal([]byte(n.Payload), &event)
+		if err != nil {
+			continue
+		}
+
+		handle(&event)
+	}
+}
+
+// release stops listening on the connection and returns it to the pool.
+// The connection which can't stop listening is closed, so the pool replaces it.
+func release(conn *pgxpool.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), unlistenTimeout)
+	defer cancel()
+
+	_, err := conn.Exec(ctx, "UNLISTEN *")
+	if err != nil {
+		_ = conn.Conn().Close(ctx)
+	}
+
+	conn.Release()
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+)
+
+// InsertOutboxEvent stores the domain event in the outbox using the Querier.
+// It should be called within the transaction of the mutation so the event is stored only if the mutation is.
+// The trace context of the request is stored with the event, so its deliveries continue the trace.
+// Returns an error if the event can't be encoded or inserted in the table.
+func InsertOutboxEvent(ctx context.Context, q Querier, event model.DomainEvent) error {
+	q = traced(q)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, "INSERT INTO outbox (event_type, cartID, payload, trace_parent) VALUES ($1, $2, $3, $4)",
+		event.EventType(), event.EventCartID(), payload, tracing.TraceParent(ctx))
+
+	return err
+}
+
+// ClaimPendingOutbox selects at most limit undelivered messages which are due for the delivery
+// and postpones their next attempt until the lease ends, so other relays skip them meanwhile.
+// The claim is committed by itself, the messages are delivered outside of any transaction.
+// Returns an error if the error occurred while reading rows.
+func ClaimPendingOutbox(ctx context.Context, q Querier, limit int, leaseEnd time.Time) ([]model.OutboxMessage, error) {
+	q = traced(q)
+
+	rows, err := q.Query(ctx, `WITH claimed AS (
+			UPDATE outbox SET next_attempt_at = $2 WHERE id IN (
+				SELECT id FROM outbox WHERE delivered_at IS NULL AND next_attempt_at <= now()
+				ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+			RETURNING id, event_type, cartID, payload, occurred_at, attempts, trace_parent, delivered_sinks)
+		SELECT * FROM claimed ORDER BY id`, limit, leaseEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var messages []model.OutboxMessage
+
+	for rows.Next() {
+		var m model.OutboxMessage
+
+		err = rows.Scan(&m.ID, &m.Type, &m.CartID, &m.Payload, &m.OccurredAt, &m.Attempts, &m.TraceParent, &m.DeliveredSinks)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxDelivered marks the message as delivered to all the sinks.
+// Returns an error if the message can't be updated.
+func MarkOutboxDelivered(ctx context.Context, q Querier, id int64, sinks []string) error {
+	q = traced(q)
+
+	_, err := q.Exec(ctx, `UPDATE outbox SET delivered_at = now(), delivered_sinks = COALESCE($2::text[], '{}'), last_error = NULL
+		WHERE id = $1`, id, sinks)
+
+	return err
+}
+
+// MarkOutboxFailed records the failed delivery attempt and schedules the next one.
+// The sinks which accepted the message are stored, so the next attempt skips them.
+// Returns an error if the message can't be updated.
+func MarkOutboxFailed(ctx context.Context, q Querier, id int64, sinks []string, deliveryErr string, nextAttempt time.Time) error {
+	q = traced(q)
+
+	_, err := q.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, delivered_sinks = COALESCE($2::text[], '{}'),
+		last_error = $3, next_attempt_at = $4 WHERE id = $1`, id, sinks, deliveryErr, nextAttempt)
+
+	return err
+}
+
+// DeleteDeliveredOutbox deletes the messages which were delivered to all the sinks longer than retention ago.
+// The pending and failed messages are kept.
+// Returns the number of the deleted messages.
+// Also it returns an error if the messages can't be deleted.
+// The transient failures are retried.
+func DeleteDeliveredOutbox(ctx context.Context, p *Pool, retention time.Duration) (int64, error) {
+	var res int64
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = deleteDeliveredOutbox(ctx, p, retention)
+
+		return err
+	})
+
+	return res, err
+}
+
+// deleteDeliveredOutbox is a single attempt of DeleteDeliveredOutbox.
+func deleteDeliveredOutbox(ctx context.Context, p *Pool, retention time.Duration) (int64, error) {
+	ct, err := traced(p).Exec(ctx, "DELETE FROM outbox WHERE delivered_at < now() - $1::interval", retention)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
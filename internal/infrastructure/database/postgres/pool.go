@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// The delays between the attempts to connect to the database at startup.
+const (
+	connectBackoff    = 250 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
+)
+
+// PoolOptions are the settings of the connection pool, the zero values keep the pgx defaults.
+type PoolOptions struct {
+	MaxConns          int32         // maximum number of the connections in the pool
+	MinConns          int32         // number of the connections kept open even if they are idle
+	MaxConnLifetime   time.Duration // time after which the connection is closed and replaced
+	MaxConnIdleTime   time.Duration // time after which the idle connection is closed
+	HealthCheckPeriod time.Duration // how often the idle connections are checked
+	ConnectTimeout    time.Duration // timeout of establishing a single connection
+}
+
+// Pool is a connection pool with the settings of its operations.
+type Pool struct {
+	*pgxpool.Pool
+	Settings *Settings // query timeout and retries of the operations, nil uses the defaults
+}
+
+// NewPool is a constructor for Pool struct.
+func NewPool(pool *pgxpool.Pool, settings *Settings) *Pool {
+	return &Pool{Pool: pool, Settings: settings}
+}
+
+// settings returns the settings of the pool.
+func (p *Pool) settings() *Settings {
+	return p.Settings
+}
+
+// traced wraps the connection of the pool so its SQL calls are traced and bounded by the query timeout of the pool.
+func (p *Pool) traced(conn *pgxpool.Conn) Querier {
+	return tracedQuerier{q: conn, settings: p.Settings}
+}
+
+// ParseConfig parses the connection string and applies the options to the config of the pool.
+// Returns an error if the connection string is invalid.
+func ParseConfig(connString string, opts PoolOptions) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxConns > 0 {
+		cfg.MaxConns = opts.MaxConns
+	}
+
+	if opts.MinConns > 0 {
+		cfg.MinConns = opts.MinConns
+	}
+
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+
+	if opts.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+
+	if opts.ConnectTimeout > 0 {
+		cfg.ConnConfig.ConnectTimeout = opts.ConnectTimeout
+	}
+
+	return cfg, nil
+}
+
+// Connect opens the pool with the settings and checks the connection to the database.
+// The transient failures, e.g. the database which isn't started yet, are retried with exponential backoff
+// until the wait elapses, so the service and the database may be started together. Zero wait disables retries.
+// Returns an error of the last attempt if the database isn't available in time or the error isn't transient,
+// e.g. the password is wrong.
+func Connect(ctx context.Context, cfg *pgxpool.Config, wait time.Duration, settings *Settings) (*Pool, error) {
+	deadline := time.Now().Add(wait)
+	delay := connectBackoff
+
+	for attempt := 1; ; attempt++ {
+		pool, err := pgxpool.ConnectConfig(ctx, cfg)
+		if err == nil {
+			return NewPool(pool, settings), nil
+		}
+
+		remaining := time.Until(deadline)
+		if !IsTransient(err) || remaining <= 0 {
+			return nil, err
+		}
+
+		d := delay
+		if d > remaining {
+			d = remaining
+		}
+
+		logging.FromContext(ctx).Warn().Err(err).Int("attempt", attempt).Dur("retry_in", d).
+			Msg("Connect to database error")
+
+		err = sleep(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		delay *= 2
+		if delay > connectMaxBackoff {
+			delay = connectMaxBackoff
+		}
+	}
+}
+
+// sleep waits for the duration.
+// Returns an error of the context if it's canceled before.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
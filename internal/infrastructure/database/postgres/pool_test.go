@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	const connString = "postgres://cart@localhost:5432/cart"
+
+	defaults, err := ParseConfig(connString, PoolOptions{})
+	require.NoError(t, err)
+
+	cfg, err := ParseConfig(connString, PoolOptions{
+		MaxConns:          20,
+		MinConns:          2,
+		MaxConnLifetime:   time.Hour,
+		MaxConnIdleTime:   10 * time.Minute,
+		HealthCheckPeriod: 30 * time.Second,
+		ConnectTimeout:    3 * time.Second,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(20), cfg.MaxConns)
+	assert.Equal(t, int32(2), cfg.MinConns)
+	assert.Equal(t, time.Hour, cfg.MaxConnLifetime)
+	assert.Equal(t, 10*time.Minute, cfg.MaxConnIdleTime)
+	assert.Equal(t, 30*time.Second, cfg.HealthCheckPeriod)
+	assert.Equal(t, 3*time.Second, cfg.ConnConfig.ConnectTimeout)
+
+	assert.Greater(t, defaults.MaxConns, int32(0))
+	assert.Greater(t, defaults.HealthCheckPeriod, time.Duration(0))
+
+	_, err = ParseConfig("postgres://cart@localhost:port/cart", PoolOptions{})
+	assert.Error(t, err)
+}
@@ -2,19 +2,71 @@ package postgres
 
 import (
 	"context"
+	"errors"
 
 	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
 
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// InsertCart inserts a new Cart in the DB.
+// Querier is the interface implemented by both the pool connections and the transactions.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// settingsTx is a transaction which carries the settings of its pool to the statements.
+type settingsTx struct {
+	pgx.Tx
+	s *Settings
+}
+
+// settings returns the settings of the pool of the transaction.
+func (tx settingsTx) settings() *Settings {
+	return tx.s
+}
+
+// WithTx runs fn within a transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+// The transaction is traced as a span from the begin to the commit or the rollback.
+// Returns an error of fn or an error if the transaction can't be started or committed.
+// The transaction isn't retried, use RetryTx for the idempotent ones.
+// The statements of the transaction are bounded by the query timeout of the pool.
+func WithTx(ctx context.Context, p *Pool, fn func(tx pgx.Tx) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "postgres transaction", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := p.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = fn(settingsTx{Tx: tx, s: p.Settings})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return &commitError{err: err}
+	}
+
+	return nil
+}
+
+// InsertCart inserts a new Cart of the owner in the DB, blank owner means anonymous cart.
 // Returns the ID of a new cart.
 // Also it returns an error if the connection from the connection pool doesn't acquired or
 // if a new cart doesn't inserted in the table.
-func InsertCart(ctx context.Context, p *pgxpool.Pool) (int, error) {
-	var id int
-
+func InsertCart(ctx context.Context, p *Pool, owner string) (int, error) {
 	conn, err := p.Acquire(ctx)
 	if err != nil {
 		return 0, err
@@ -22,9 +74,20 @@ func InsertCart(ctx context.Context, p *pgxpool.Pool) (int, error) {
 
 	defer conn.Release()
 
-	row := conn.QueryRow(ctx, "INSERT INTO carts DEFAULT VALUES RETURNING id")
+	return InsertCartTx(ctx, conn, owner)
+}
+
+// InsertCartTx inserts a new Cart of the owner using the Querier, e.g. within the transaction.
+// Returns the ID of a new cart.
+// Also it returns an error if a new cart doesn't inserted in the table.
+func InsertCartTx(ctx context.Context, q Querier, owner string) (int, error) {
+	q = traced(q)
+
+	var id int
+
+	row := q.QueryRow(ctx, "INSERT INTO carts (owner) VALUES (NULLIF($1, '')) RETURNING id", owner)
 
-	err = row.Scan(&id)
+	err := row.Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -36,9 +99,7 @@ func InsertCart(ctx context.Context, p *pgxpool.Pool) (int, error) {
 // Returns the ID of a new item.
 // Also it returns an error if the connection from the connection pool doesn't acquire or
 // if a new item doesn't inserted in the table.
-func InsertItem(ctx context.Context, p *pgxpool.Pool, item *model.CartItem) (int, error) {
-	var id int
-
+func InsertItem(ctx context.Context, p *Pool, item *model.CartItem) (int, error) {
 	conn, err := p.Acquire(ctx)
 	if err != nil {
 		return 0, err
@@ -46,10 +107,21 @@ func InsertItem(ctx context.Context, p *pgxpool.Pool, item *model.CartItem) (int
 
 	defer conn.Release()
 
-	row := conn.QueryRow(ctx, "INSERT INTO items (cartID, product_name, quantity) VALUES ($1, $2, $3) RETURNING id",
+	return InsertItemTx(ctx, conn, item)
+}
+
+// InsertItemTx inserts a new CartItem using the Querier, e.g. within the transaction.
+// Returns the ID of a new item.
+// Also it returns an error if a new item doesn't inserted in the table.
+func InsertItemTx(ctx context.Context, q Querier, item *model.CartItem) (int, error) {
+	q = traced(q)
+
+	var id int
+
+	row := q.QueryRow(ctx, "INSERT INTO items (cartID, product_name, quantity) VALUES ($1, $2, $3) RETURNING id",
 		item.CartID, item.Product, item.Quantity)
 
-	err = row.Scan(&id)
+	err := row.Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -57,11 +129,97 @@ func InsertItem(ctx context.Context, p *pgxpool.Pool, item *model.CartItem) (int
 	return id, nil
 }
 
+// LockCartItemsTx locks the Cart until the end of the transaction and selects its items using the Querier,
+// so the concurrent changes of the cart which check its content are serialized.
+// Returns false if the cart doesn't exist.
+// Also it returns an error if the cart can't be locked or the items can't be selected.
+func LockCartItemsTx(ctx context.Context, q Querier, cartID int) ([]model.CartItem, bool, error) {
+	q = traced(q)
+
+	var id int
+
+	err := q.QueryRow(ctx, "SELECT id FROM carts WHERE ID=$1 FOR UPDATE", cartID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows, err := q.Query(ctx, "SELECT id, cartID, product_name, quantity FROM items WHERE cartID=$1", cartID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer rows.Close()
+
+	items := []model.CartItem{}
+
+	for rows.Next() {
+		var item model.CartItem
+
+		err = rows.Scan(&item.ID, &item.CartID, &item.Product, &item.Quantity)
+		if err != nil {
+			return nil, false, err
+		}
+
+		items = append(items, item)
+	}
+
+	if rows.Err() != nil {
+		return nil, false, rows.Err()
+	}
+
+	return items, true, nil
+}
+
+// GetCartOwner selects the owner of the Cart using the Querier, blank owner means anonymous cart.
+// Returns false if the cart doesn't exist.
+// Also it returns an error if the cart can't be selected.
+func GetCartOwner(ctx context.Context, q Querier, cartID int) (string, bool, error) {
+	q = traced(q)
+
+	var owner string
+
+	err := q.QueryRow(ctx, "SELECT COALESCE(owner, '') FROM carts WHERE ID=$1", cartID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return owner, true, nil
+}
+
+// GetItemTx selects the CartItem of the cart using the Querier and locks it until the end of the transaction.
+// Returns nil if the cart or the item doesn't exist.
+// Also it returns an error if the item can't be selected.
+func GetItemTx(ctx context.Context, q Querier, cartID, itemID int) (*model.CartItem, error) {
+	q = traced(q)
+
+	var item model.CartItem
+
+	err := q.QueryRow(ctx, "SELECT id, cartID, product_name, quantity FROM items WHERE ID=$1 AND cartID=$2 FOR UPDATE",
+		itemID, cartID).Scan(&item.ID, &item.CartID, &item.Product, &item.Quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
 // DeleteItem deletes a CartItem from the cart in the DB.
 // Returns the bool value that flagged item was deleted or no.
 // Also it returns an error if the connection from the connection pool doesn't acquire or
 // if the item doesn't deleted from the table.
-func DeleteItem(ctx context.Context, p *pgxpool.Pool, cartID, itemID int) (bool, error) {
+func DeleteItem(ctx context.Context, p *Pool, cartID, itemID int) (bool, error) {
 	conn, err := p.Acquire(ctx)
 	if err != nil {
 		return false, err
@@ -69,7 +227,16 @@ func DeleteItem(ctx context.Context, p *pgxpool.Pool, cartID, itemID int) (bool,
 
 	defer conn.Release()
 
-	ct, err := conn.Exec(ctx, "DELETE FROM Items WHERE ID=$1 AND cartID=$2", itemID, cartID)
+	return DeleteItemTx(ctx, conn, cartID, itemID)
+}
+
+// DeleteItemTx deletes a CartItem from the cart using the Querier, e.g. within the transaction.
+// Returns the bool value that flagged item was deleted or no.
+// Also it returns an error if the item doesn't deleted from the table.
+func DeleteItemTx(ctx context.Context, q Querier, cartID, itemID int) (bool, error) {
+	q = traced(q)
+
+	ct, err := q.Exec(ctx, "DELETE FROM Items WHERE ID=$1 AND cartID=$2", itemID, cartID)
 	if err != nil {
 		return false, err
 	}
@@ -81,11 +248,42 @@ func DeleteItem(ctx context.Context, p *pgxpool.Pool, cartID, itemID int) (bool,
 	return false, nil
 }
 
+// CountItemsTx counts the items of the Cart using the Querier, e.g. within the transaction.
+// Also it returns an error if the items can't be counted.
+func CountItemsTx(ctx context.Context, q Querier, cartID int) (int, error) {
+	q = traced(q)
+
+	var count int
+
+	err := q.QueryRow(ctx, "SELECT COUNT(*) FROM items WHERE cartID=$1", cartID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // GetCart selects all the items in the Cart from the DB.
 // Returns pointer to the Cart model with the data.
 // Also it returns an error if the connection from the connection pool doesn't acquire or
 // if the error occurred while reading rows or if the cart doesn't selected from the table.
-func GetCart(ctx context.Context, p *pgxpool.Pool, cartID int) (*model.Cart, error) {
+// The transient failures are retried.
+func GetCart(ctx context.Context, p *Pool, cartID int) (*model.Cart, error) {
+	var res *model.Cart
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = getCart(ctx, p, cartID)
+
+		return err
+	})
+
+	return res, err
+}
+
+// getCart is a single attempt of GetCart.
+func getCart(ctx context.Context, p *Pool, cartID int) (*model.Cart, error) {
 	var items []model.CartItem
 
 	var cart model.Cart
@@ -99,7 +297,9 @@ func GetCart(ctx context.Context, p *pgxpool.Pool, cartID int) (*model.Cart, err
 
 	defer conn.Release()
 
-	err = conn.QueryRow(ctx, "SELECT COUNT(*) FROM carts WHERE ID=$1", cartID).Scan(&rowsCount)
+	q := p.traced(conn)
+
+	err = q.QueryRow(ctx, "SELECT COUNT(*) FROM carts WHERE ID=$1", cartID).Scan(&rowsCount)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +308,7 @@ func GetCart(ctx context.Context, p *pgxpool.Pool, cartID int) (*model.Cart, err
 		return &model.Cart{ID: -1}, nil
 	}
 
-	rows, err := conn.Query(ctx, "SELECT id, cartId, product_name, quantity FROM items WHERE cartID=$1", cartID)
+	rows, err := q.Query(ctx, "SELECT id, cartId, product_name, quantity FROM items WHERE cartID=$1", cartID)
 	if err != nil {
 		return nil, err
 	}
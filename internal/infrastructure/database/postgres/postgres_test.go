@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/fedo3nik/cart-go-api/internal/config"
 	"github.com/fedo3nik/cart-go-api/internal/domain/model"
@@ -16,9 +18,11 @@ func TestDeleteItem(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := NewPool(p, nil)
+
 	item := model.CartItem{CartID: 1, Quantity: 1, Product: "test_product"}
 
 	id, err := InsertItem(context.Background(), pool, &item)
@@ -60,10 +64,12 @@ func TestGetCart(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
-	cartID, err := InsertCart(context.Background(), pool)
+	pool := NewPool(p, nil)
+
+	cartID, err := InsertCart(context.Background(), pool, "")
 	require.NoError(t, err)
 
 	tt := []struct {
@@ -93,9 +99,11 @@ func TestInsertCart(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := NewPool(p, nil)
+
 	var maxCartID int
 
 	conn, err := pool.Acquire(context.Background())
@@ -117,7 +125,7 @@ func TestInsertCart(t *testing.T) {
 		tc := tc
 
 		t.Run(tc.name, func(t *testing.T) {
-			id, err := InsertCart(context.Background(), pool)
+			id, err := InsertCart(context.Background(), pool, "")
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expectedResult, id)
@@ -129,9 +137,11 @@ func TestInsertItem(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := NewPool(p, nil)
+
 	var maxItemID, maxCartID int
 
 	conn, err := pool.Acquire(context.Background())
@@ -165,3 +175,214 @@ func TestInsertItem(t *testing.T) {
 		})
 	}
 }
+
+func TestListCarts(t *testing.T) {
+	c, err := config.NewConfig()
+	require.NoError(t, err)
+
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	require.NoError(t, err)
+
+	pool := NewPool(p, nil)
+
+	firstID, err := InsertCart(context.Background(), pool, "list_owner")
+	require.NoError(t, err)
+
+	secondID, err := InsertCart(context.Background(), pool, "list_owner")
+	require.NoError(t, err)
+
+	_, err = InsertItem(context.Background(), pool, &model.CartItem{CartID: secondID, Product: "list_product", Quantity: 1})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name           string
+		filter         model.CartFilter
+		after          *model.CartKey
+		expectedResult []int
+	}{
+		{
+			name:           "Filter by owner",
+			filter:         model.CartFilter{Owner: "list_owner", Sort: model.CartSortID},
+			expectedResult: []int{firstID, secondID},
+		},
+		{
+			name:           "Descending after the key",
+			filter:         model.CartFilter{Owner: "list_owner", Sort: model.CartSortID, Desc: true},
+			after:          &model.CartKey{ID: secondID},
+			expectedResult: []int{firstID},
+		},
+		{
+			name:           "Contains product",
+			filter:         model.CartFilter{Owner: "list_owner", Product: "list_product", NonEmpty: true},
+			expectedResult: []int{secondID},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			carts, err := ListCarts(context.Background(), pool, &tc.filter, tc.after, 10)
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(carts))
+			for _, cart := range carts {
+				ids = append(ids, cart.ID)
+			}
+
+			assert.Equal(t, tc.expectedResult, ids)
+		})
+	}
+}
+
+func TestAppendCartEvent(t *testing.T) {
+	c, err := config.NewConfig()
+	require.NoError(t, err)
+
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	require.NoError(t, err)
+
+	pool := NewPool(p, nil)
+
+	ctx := context.Background()
+
+	cartID, err := InsertCart(ctx, pool, "")
+	require.NoError(t, err)
+
+	for i, event := range []model.DomainEvent{
+		model.CartCreated{CartID: cartID},
+		model.ItemAdded{CartID: cartID, ItemID: 1, Product: "Hat", Quantity: 1},
+	} {
+		version, err := AppendCartEvent(ctx, pool, event)
+		require.NoError(t, err)
+		assert.Equal(t, i+1, version)
+	}
+
+	events, err := ListCartEvents(ctx, pool, cartID, 0, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, model.ItemAdded{CartID: cartID, ItemID: 1, Product: "Hat", Quantity: 1}, events[1].Event)
+
+	events, err = ListCartEvents(ctx, pool, cartID, 0, events[0].OccurredAt)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	snapshot := model.CartSnapshot{Version: 2, TakenAt: time.Now(), Cart: model.Cart{ID: cartID, Items: []model.CartItem{}}}
+	require.NoError(t, InsertCartSnapshot(ctx, pool, &snapshot))
+
+	latest, err := GetLatestSnapshot(ctx, pool, cartID, time.Time{})
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, 2, latest.Version)
+
+	latest, err = GetLatestSnapshot(ctx, pool, cartID, snapshot.TakenAt.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+}
+
+func TestListAuditEntries(t *testing.T) {
+	c, err := config.NewConfig()
+	require.NoError(t, err)
+
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	require.NoError(t, err)
+
+	pool := NewPool(p, nil)
+
+	ctx := context.Background()
+
+	cartID, err := InsertCart(ctx, pool, "")
+	require.NoError(t, err)
+
+	for _, action := range []string{model.AuditActionCreateCart, model.AuditActionAddItem} {
+		err = InsertAuditEntry(ctx, pool, &model.AuditEntry{CartID: cartID, Action: action, Actor: "auditor"})
+		require.NoError(t, err)
+	}
+
+	entries, err := ListAuditEntries(ctx, pool, &model.AuditFilter{CartID: cartID, Actor: "auditor", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, model.AuditActionAddItem, entries[0].Action)
+
+	entries, err = ListAuditEntries(ctx, pool, &model.AuditFilter{CartID: cartID, BeforeID: entries[0].ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, model.AuditActionCreateCart, entries[0].Action)
+}
+
+func TestAPIKeys(t *testing.T) {
+	c, err := config.NewConfig()
+	require.NoError(t, err)
+
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	require.NoError(t, err)
+
+	pool := NewPool(p, nil)
+
+	ctx := context.Background()
+	hash := strconv.FormatInt(time.Now().UnixNano(), 16)
+
+	id, err := InsertAPIKey(ctx, pool, &model.APIKey{Name: "billing", Prefix: "ck_test", Scopes: []string{"cart:read"}}, hash)
+	require.NoError(t, err)
+
+	k, err := GetActiveAPIKeyByHash(ctx, pool, hash)
+	require.NoError(t, err)
+	require.NotNil(t, k)
+	assert.Equal(t, id, k.ID)
+	assert.True(t, k.ExpiresAt.IsZero())
+
+	require.NoError(t, TouchAPIKey(ctx, pool, id, time.Minute))
+
+	found, err := RevokeAPIKey(ctx, pool, id)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	k, err = GetActiveAPIKeyByHash(ctx, pool, hash)
+	require.NoError(t, err)
+	assert.Nil(t, k)
+
+	found, err = RotateAPIKey(ctx, pool, id, "ck_new", hash+"0")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLockCartItemsTx(t *testing.T) {
+	c, err := config.NewConfig()
+	require.NoError(t, err)
+
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	require.NoError(t, err)
+
+	pool := NewPool(p, nil)
+
+	cartID, err := InsertCart(context.Background(), pool, "")
+	require.NoError(t, err)
+
+	_, err = InsertItem(context.Background(), pool, &model.CartItem{CartID: cartID, Quantity: 2, Product: "test_product"})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		cartID        int
+		expectedFound bool
+		expectedItems int
+	}{
+		{name: "Lock cart", cartID: cartID, expectedFound: true, expectedItems: 1},
+		{name: "Wrong cartID", cartID: -1},
+	}
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := pool.Begin(context.Background())
+			require.NoError(t, err)
+
+			defer func() { _ = tx.Rollback(context.Background()) }()
+
+			items, found, err := LockCartItemsTx(context.Background(), tx, tc.cartID)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Len(t, items, tc.expectedItems)
+		})
+	}
+}
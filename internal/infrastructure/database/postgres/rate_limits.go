@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+// LockRateBucket selects the token bucket of the key using the Querier and locks it until the end of the transaction.
+// A missing bucket is created, its zero updatedAt means the bucket was never used.
+// Returns the tokens, the time when they were counted and the current time of the database,
+// so all the replicas count the tokens by the same clock.
+// Also it returns an error if the bucket can't be created or selected.
+func LockRateBucket(ctx context.Context, q Querier, key string) (float64, time.Time, time.Time, error) {
+	q = traced(q)
+
+	var (
+		tokens    float64
+		updatedAt *time.Time
+		now       time.Time
+	)
+
+	_, err := q.Exec(ctx, "INSERT INTO rate_limits (key) VALUES ($1) ON CONFLICT (key) DO NOTHING", key)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	err = q.QueryRow(ctx, "SELECT tokens, updated_at, clock_timestamp() FROM rate_limits WHERE key = $1 FOR UPDATE",
+		key).Scan(&tokens, &updatedAt, &now)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	if updatedAt == nil {
+		return tokens, time.Time{}, now, nil
+	}
+
+	return tokens, *updatedAt, now, nil
+}
+
+// SaveRateBucket stores the tokens of the bucket of the key using the Querier.
+// Returns an error if the bucket can't be updated.
+func SaveRateBucket(ctx context.Context, q Querier, key string, tokens float64, updatedAt time.Time) error {
+	q = traced(q)
+
+	_, err := q.Exec(ctx, "UPDATE rate_limits SET tokens = $2, updated_at = $3 WHERE key = $1", key, tokens, updatedAt)
+
+	return err
+}
+
+// DeleteIdleRateBuckets deletes the token buckets which weren't used for longer than idle.
+// Returns the number of the deleted buckets.
+// Also it returns an error if the buckets can't be deleted.
+// The transient failures are retried.
+func DeleteIdleRateBuckets(ctx context.Context, p *Pool, idle time.Duration) (int64, error) {
+	var res int64
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = deleteIdleRateBuckets(ctx, p, idle)
+
+		return err
+	})
+
+	return res, err
+}
+
+// deleteIdleRateBuckets is a single attempt of DeleteIdleRateBuckets.
+func deleteIdleRateBuckets(ctx context.Context, p *Pool, idle time.Duration) (int64, error) {
+	ct, err := traced(p).Exec(ctx, "DELETE FROM rate_limits WHERE updated_at < now() - $1::interval", idle)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// The defaults of the per-query timeout and the attempts of the idempotent operations.
+const (
+	DefaultQueryTimeout  = 5 * time.Second
+	DefaultRetryAttempts = 3
+)
+
+// The delays between the attempts of the idempotent operations.
+const (
+	retryBackoff    = 50 * time.Millisecond
+	retryMaxBackoff = time.Second
+)
+
+// Settings are the query timeout and the retries of the operations of the pool.
+// They may be changed while the pool is used, e.g. when the config is reloaded.
+// A nil Settings uses the defaults.
+type Settings struct {
+	queryTimeout  int64 // time.Duration, accessed atomically
+	retryAttempts int64 // accessed atomically
+}
+
+// NewSettings is a constructor for Settings struct.
+func NewSettings(queryTimeout time.Duration, retryAttempts int) *Settings {
+	s := &Settings{}
+	s.SetQueryTimeout(queryTimeout)
+	s.SetRetryAttempts(retryAttempts)
+
+	return s
+}
+
+// SetQueryTimeout sets the timeout of every SQL statement, 0 disables it.
+// The statement is bounded by the deadline of the request context if it's earlier.
+func (s *Settings) SetQueryTimeout(d time.Duration) {
+	atomic.StoreInt64(&s.queryTimeout, int64(d))
+}
+
+// SetRetryAttempts sets the number of the attempts of the idempotent operations, 1 disables retries.
+func (s *Settings) SetRetryAttempts(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	atomic.StoreInt64(&s.retryAttempts, int64(n))
+}
+
+// QueryTimeout returns the timeout of every SQL statement.
+func (s *Settings) QueryTimeout() time.Duration {
+	if s == nil {
+		return DefaultQueryTimeout
+	}
+
+	return time.Duration(atomic.LoadInt64(&s.queryTimeout))
+}
+
+// RetryAttempts returns the number of the attempts of the idempotent operations.
+func (s *Settings) RetryAttempts() int {
+	if s == nil {
+		return DefaultRetryAttempts
+	}
+
+	return int(atomic.LoadInt64(&s.retryAttempts))
+}
+
+// withQueryTimeout derives the context of the SQL statement from the context of the request.
+func (s *Settings) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := s.QueryTimeout()
+	if d <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d)
+}
+
+// commitError is an error of the commit, the transaction may be committed even if it's returned.
+type commitError struct {
+	err error
+}
+
+// Error returns the message of the commit error.
+func (ce *commitError) Error() string {
+	return ce.err.Error()
+}
+
+// Unwrap returns the commit error.
+func (ce *commitError) Unwrap() error {
+	return ce.err
+}
+
+// IsTransient reports whether the error is a transient failure which may not repeat on the next attempt:
+// a serialization failure, a deadlock, a restart of the database or a broken connection.
+// The broken connection while committing isn't transient unless the commit wasn't sent,
+// because the transaction may be already committed.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "57P01", "57P02", "57P03":
+			return true
+		}
+
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var ce *commitError
+	if errors.As(err, &ce) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsForeignKeyViolation reports whether the error is a violation of the foreign key constraint,
+// e.g. the inserted row references the missing cart.
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// Retry runs the idempotent operation and reruns it while it fails with a transient error,
+// the number of the attempts is set by SetRetryAttempts.
+// Returns the error of the last attempt or an error of the context if it's canceled while waiting.
+func (s *Settings) Retry(ctx context.Context, fn func() error) error {
+	attempts := s.RetryAttempts()
+	delay := retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if attempt >= attempts || !IsTransient(err) {
+			return err
+		}
+
+		err = sleep(ctx, delay)
+		if err != nil {
+			return err
+		}
+
+		delay *= 2
+		if delay > retryMaxBackoff {
+			delay = retryMaxBackoff
+		}
+	}
+}
+
+// RetryTx runs fn within a transaction like WithTx and reruns the transaction while it fails with a transient error.
+// The rolled back transaction leaves no changes, so fn may be rerun if it has no side effects outside of it.
+// The attempts are set by the settings of the pool.
+func RetryTx(ctx context.Context, p *Pool, fn func(tx pgx.Tx) error) error {
+	return p.Settings.Retry(ctx, func() error {
+		return WithTx(ctx, p, fn)
+	})
+}
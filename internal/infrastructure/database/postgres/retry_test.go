@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	errReset := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tt := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Nil", err: nil, expected: false},
+		{name: "Serialization failure", err: &pgconn.PgError{Code: "40001"}, expected: true},
+		{name: "Deadlock", err: &pgconn.PgError{Code: "40P01"}, expected: true},
+		{name: "Admin shutdown", err: &pgconn.PgError{Code: "57P01"}, expected: true},
+		{name: "Connection failure", err: &pgconn.PgError{Code: "08006"}, expected: true},
+		{name: "Unique violation", err: &pgconn.PgError{Code: "23505"}, expected: false},
+		{name: "Connection reset", err: errReset, expected: true},
+		{name: "Wrapped connection reset", err: fmt.Errorf("select cart: %w", errReset), expected: true},
+		{name: "Unexpected EOF", err: io.ErrUnexpectedEOF, expected: true},
+		{name: "Connection reset on commit", err: &commitError{err: errReset}, expected: false},
+		{name: "Serialization failure on commit", err: &commitError{err: &pgconn.PgError{Code: "40001"}}, expected: true},
+		{name: "Canceled", err: context.Canceled, expected: false},
+		{name: "Deadline exceeded", err: context.DeadlineExceeded, expected: false},
+		{name: "No rows", err: pgx.ErrNoRows, expected: false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsTransient(tc.err))
+		})
+	}
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Foreign key violation", err: &pgconn.PgError{Code: "23503"}, expected: true},
+		{name: "Wrapped foreign key violation", err: fmt.Errorf("insert item: %w", &pgconn.PgError{Code: "23503"}), expected: true},
+		{name: "Value too long", err: &pgconn.PgError{Code: "22001"}, expected: false},
+		{name: "Deadline exceeded", err: context.DeadlineExceeded, expected: false},
+		{name: "Nil", err: nil, expected: false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsForeignKeyViolation(tc.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errSerialization := &pgconn.PgError{Code: "40001"}
+	errConstraint := &pgconn.PgError{Code: "23505"}
+
+	tt := []struct {
+		name          string
+		attempts      int
+		errs          []error
+		expectedErr   error
+		expectedCalls int
+	}{
+		{name: "Success", attempts: 3, errs: []error{nil}, expectedErr: nil, expectedCalls: 1},
+		{name: "Transient then success", attempts: 3, errs: []error{errSerialization, nil}, expectedErr: nil, expectedCalls: 2},
+		{name: "Transient exhausted", attempts: 3, errs: []error{errSerialization, errSerialization, errSerialization},
+			expectedErr: errSerialization, expectedCalls: 3},
+		{name: "Permanent", attempts: 3, errs: []error{errConstraint}, expectedErr: errConstraint, expectedCalls: 1},
+		{name: "Retries disabled", attempts: 1, errs: []error{errSerialization}, expectedErr: errSerialization, expectedCalls: 1},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			settings := NewSettings(DefaultQueryTimeout, tc.attempts)
+			calls := 0
+
+			err := settings.Retry(context.Background(), func() error {
+				err := tc.errs[calls]
+				calls++
+
+				return err
+			})
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
+
+func TestRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := (*Settings)(nil).Retry(ctx, func() error {
+		calls++
+		cancel()
+
+		return io.ErrUnexpectedEOF
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, calls)
+}
+
+// deadlineQuerier records the deadline of the context of the statement, it carries the settings like a transaction.
+type deadlineQuerier struct {
+	fakeQuerier
+	deadline *time.Time
+	s        *Settings
+}
+
+func (dq deadlineQuerier) settings() *Settings {
+	return dq.s
+}
+
+func (dq deadlineQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	*dq.deadline, _ = ctx.Deadline()
+
+	return dq.fakeQuerier.Exec(ctx, sql, args...)
+}
+
+func TestTracedQuerier_QueryTimeout(t *testing.T) {
+	tt := []struct {
+		name            string
+		timeout         time.Duration
+		requestTimeout  time.Duration
+		expectedTimeout time.Duration
+		defaults        bool
+	}{
+		{name: "Query timeout", timeout: time.Minute, expectedTimeout: time.Minute},
+		{name: "Earlier request deadline", timeout: time.Minute, requestTimeout: time.Second, expectedTimeout: time.Second},
+		{name: "Later request deadline", timeout: time.Second, requestTimeout: time.Minute, expectedTimeout: time.Second},
+		{name: "Disabled", timeout: 0, expectedTimeout: 0},
+		{name: "Default settings", defaults: true, expectedTimeout: DefaultQueryTimeout},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if tc.requestTimeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.requestTimeout)
+				defer cancel()
+			}
+
+			var deadline time.Time
+
+			start := time.Now()
+
+			q := deadlineQuerier{deadline: &deadline}
+			if !tc.defaults {
+				q.s = NewSettings(tc.timeout, DefaultRetryAttempts)
+			}
+
+			_, err := traced(q).Exec(ctx, "SELECT 1")
+			require.NoError(t, err)
+
+			if tc.expectedTimeout == 0 {
+				assert.True(t, deadline.IsZero())
+
+				return
+			}
+
+			assert.WithinDuration(t, start.Add(tc.expectedTimeout), deadline, 100*time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import "context"
+
+// SchemaVersion selects the version of the schema applied by the migrate tool using the Querier.
+// Returns the version and the dirty flag which is set if the last migration failed.
+// Also it returns an error if the version can't be selected, e.g. the migrations were never applied.
+func SchemaVersion(ctx context.Context, q Querier) (int, bool, error) {
+	q = traced(q)
+
+	var (
+		version int
+		dirty   bool
+	)
+
+	err := q.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedQuerier starts a client span for every SQL call of the Querier and bounds it by the query timeout.
+// The spans and the timeouts of the queries end when their rows are closed or scanned.
+type tracedQuerier struct {
+	q        Querier
+	settings *Settings
+}
+
+// traced wraps the Querier so its SQL calls are traced.
+// The query timeout is taken from the settings of the pool or the transaction of the Querier.
+func traced(q Querier) Querier {
+	if _, ok := q.(tracedQuerier); ok {
+		return q
+	}
+
+	var settings *Settings
+	if sq, ok := q.(interface{ settings() *Settings }); ok {
+		settings = sq.settings()
+	}
+
+	return tracedQuerier{q: q, settings: settings}
+}
+
+// startSpan starts the span of the SQL statement named by its operation, e.g. SELECT.
+func startSpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	op := "SQL"
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		op = strings.ToUpper(fields[0])
+	}
+
+	return tracing.Tracer().Start(ctx, "postgres "+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationKey.String(op),
+		semconv.DBStatementKey.String(sql),
+	))
+}
+
+// Exec is a method to execute the traced statement.
+func (tq tracedQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, cancel := tq.settings.withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, span := startSpan(ctx, sql)
+
+	ct, err := tq.q.Exec(ctx, sql, args...)
+	tracing.End(span, err)
+
+	return ct, err
+}
+
+// Query is a method to run the traced query, the span ends when the rows are closed.
+func (tq tracedQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, cancel := tq.settings.withQueryTimeout(ctx)
+	ctx, span := startSpan(ctx, sql)
+
+	rows, err := tq.q.Query(ctx, sql, args...)
+	if err != nil {
+		tracing.End(span, err)
+		cancel()
+
+		return nil, err
+	}
+
+	return &tracedRows{Rows: rows, span: span, cancel: cancel}, nil
+}
+
+// QueryRow is a method to run the traced query of a single row, the span ends when the row is scanned.
+func (tq tracedQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, cancel := tq.settings.withQueryTimeout(ctx)
+	ctx, span := startSpan(ctx, sql)
+
+	return tracedRow{row: tq.q.QueryRow(ctx, sql, args...), span: span, cancel: cancel}
+}
+
+// tracedRows ends the span of the query when the rows are closed.
+type tracedRows struct {
+	pgx.Rows
+	span   trace.Span
+	cancel context.CancelFunc
+	ended  bool
+}
+
+// Close is a method to close the rows and end the span with the error of the rows.
+func (tr *tracedRows) Close() {
+	tr.Rows.Close()
+
+	if !tr.ended {
+		tr.ended = true
+		tracing.End(tr.span, tr.Rows.Err())
+		tr.cancel()
+	}
+}
+
+// tracedRow ends the span of the query when the row is scanned.
+type tracedRow struct {
+	row    pgx.Row
+	span   trace.Span
+	cancel context.CancelFunc
+}
+
+// Scan is a method to scan the row and end the span, no rows isn't recorded as an error.
+func (tr tracedRow) Scan(dest ...interface{}) error {
+	err := tr.row.Scan(dest...)
+	tr.cancel()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		tracing.End(tr.span, nil)
+
+		return err
+	}
+
+	tracing.End(tr.span, err)
+
+	return err
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeQuerier fails the statements with the error.
+type fakeQuerier struct {
+	err error
+}
+
+func (fq fakeQuerier) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return nil, fq.err
+}
+
+func (fq fakeQuerier) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, fq.err
+}
+
+func (fq fakeQuerier) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return fakeRow{err: fq.err}
+}
+
+// fakeRow fails the scan with the error.
+type fakeRow struct {
+	err error
+}
+
+func (fr fakeRow) Scan(...interface{}) error {
+	return fr.err
+}
+
+func TestTracedQuerier(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	errQuery := errors.New("connection reset")
+
+	tt := []struct {
+		name           string
+		err            error
+		call           func(q Querier) error
+		expectedName   string
+		expectedStatus codes.Code
+	}{
+		{
+			name: "Failed exec",
+			err:  errQuery,
+			call: func(q Querier) error {
+				_, err := q.Exec(context.Background(), "DELETE FROM items WHERE ID=$1", 1)
+
+				return err
+			},
+			expectedName:   "postgres DELETE",
+			expectedStatus: codes.Error,
+		},
+		{
+			name: "Failed query",
+			err:  errQuery,
+			call: func(q Querier) error {
+				_, err := q.Query(context.Background(), "select id FROM items")
+
+				return err
+			},
+			expectedName:   "postgres SELECT",
+			expectedStatus: codes.Error,
+		},
+		{
+			name: "No rows",
+			err:  pgx.ErrNoRows,
+			call: func(q Querier) error {
+				return q.QueryRow(context.Background(), "SELECT id FROM carts WHERE ID=$1", 1).Scan()
+			},
+			expectedName:   "postgres SELECT",
+			expectedStatus: codes.Unset,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			exporter.Reset()
+
+			err := tc.call(traced(fakeQuerier{err: tc.err}))
+			assert.ErrorIs(t, err, tc.err)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.expectedName, spans[0].Name)
+			assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+			assert.Equal(t, tc.expectedStatus, spans[0].Status.Code)
+		})
+	}
+}
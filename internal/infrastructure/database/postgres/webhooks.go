@@ -0,0 +1,357 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// webhookColumns is a list of the selected webhook columns in the order of scanWebhook.
+const webhookColumns = "id, url, events, secret, active, created_at"
+
+// scanWebhook scans the row selected with webhookColumns.
+func scanWebhook(row pgx.Row) (*model.Webhook, error) {
+	var w model.Webhook
+
+	err := row.Scan(&w.ID, &w.URL, &w.Events, &w.Secret, &w.Active, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// InsertWebhook inserts a new Webhook in the DB.
+// Returns the ID of a new webhook.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if a new webhook doesn't inserted in the table.
+func InsertWebhook(ctx context.Context, p *Pool, w *model.Webhook) (int, error) {
+	var id int
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer conn.Release()
+
+	err = p.traced(conn).QueryRow(ctx, "INSERT INTO webhooks (url, events, secret, active) VALUES ($1, $2, $3, $4) RETURNING id",
+		w.URL, w.Events, w.Secret, w.Active).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetWebhook selects the Webhook from the DB.
+// Returns nil if the webhook with the ID doesn't exist.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the webhook can't be selected.
+// The transient failures are retried.
+func GetWebhook(ctx context.Context, p *Pool, id int) (*model.Webhook, error) {
+	var res *model.Webhook
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = getWebhook(ctx, p, id)
+
+		return err
+	})
+
+	return res, err
+}
+
+// getWebhook is a single attempt of GetWebhook.
+func getWebhook(ctx context.Context, p *Pool, id int) (*model.Webhook, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	w, err := scanWebhook(p.traced(conn).QueryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return w, err
+}
+
+// ListWebhooks selects all the Webhooks from the DB ordered by ID.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the error occurred while reading rows.
+// The transient failures are retried.
+func ListWebhooks(ctx context.Context, p *Pool) ([]model.Webhook, error) {
+	var res []model.Webhook
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = listWebhooks(ctx, p)
+
+		return err
+	})
+
+	return res, err
+}
+
+// listWebhooks is a single attempt of ListWebhooks.
+func listWebhooks(ctx context.Context, p *Pool) ([]model.Webhook, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := p.traced(conn).Query(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, *w)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook updates the URL, events, secret and active flag of the Webhook in the DB.
+// Returns false if the webhook with the ID doesn't exist.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the webhook can't be updated.
+func UpdateWebhook(ctx context.Context, p *Pool, w *model.Webhook) (bool, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Release()
+
+	ct, err := p.traced(conn).Exec(ctx, "UPDATE webhooks SET url = $2, events = $3, secret = $4, active = $5 WHERE id = $1",
+		w.ID, w.URL, w.Events, w.Secret, w.Active)
+	if err != nil {
+		return false, err
+	}
+
+	return ct.RowsAffected() == 1, nil
+}
+
+// DeleteWebhook deletes the Webhook and its deliveries from the DB.
+// Returns false if the webhook with the ID doesn't exist.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the webhook can't be deleted.
+func DeleteWebhook(ctx context.Context, p *Pool, id int) (bool, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Release()
+
+	ct, err := p.traced(conn).Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	return ct.RowsAffected() == 1, nil
+}
+
+// deliveryColumns is a list of the selected delivery columns in the order of scanDelivery.
+const deliveryColumns = `d.id, d.webhookID, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.next_attempt_at, d.created_at, d.delivered_at`
+
+// scanDelivery scans the row selected with deliveryColumns followed by the extra destinations.
+func scanDelivery(row pgx.Row, extra ...interface{}) (*model.WebhookDelivery, error) {
+	var (
+		d           model.WebhookDelivery
+		deliveredAt *time.Time
+	)
+
+	dest := append([]interface{}{
+		&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &deliveredAt,
+	}, extra...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveredAt != nil {
+		d.DeliveredAt = *deliveredAt
+	}
+
+	return &d, nil
+}
+
+// ListWebhookDeliveries selects at most limit latest deliveries of the webhook from the DB.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the error occurred while reading rows.
+// The transient failures are retried.
+func ListWebhookDeliveries(ctx context.Context, p *Pool, webhookID, limit int) ([]model.WebhookDelivery, error) {
+	var res []model.WebhookDelivery
+
+	err := p.Settings.Retry(ctx, func() error {
+		var err error
+
+		res, err = listWebhookDeliveries(ctx, p, webhookID, limit)
+
+		return err
+	})
+
+	return res, err
+}
+
+// listWebhookDeliveries is a single attempt of ListWebhookDeliveries.
+func listWebhookDeliveries(ctx context.Context, p *Pool, webhookID, limit int) ([]model.WebhookDelivery, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := p.traced(conn).Query(ctx, "SELECT "+deliveryColumns+
+		" FROM webhook_deliveries d WHERE d.webhookID = $1 ORDER BY d.id DESC LIMIT $2", webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, *d)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueWebhookDeliveries schedules the delivery of the outbox message to all the active webhooks
+// subscribed to its type. The message is scheduled only once for every webhook.
+// Also it returns an error if the connection from the connection pool doesn't acquire or
+// if the deliveries can't be inserted.
+func EnqueueWebhookDeliveries(ctx context.Context, p *Pool, msg *model.OutboxMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = p.traced(conn).Exec(ctx, `INSERT INTO webhook_deliveries (webhookID, event_id, event_type, payload, trace_parent)
+		SELECT id, $1, $2, $3, $4 FROM webhooks WHERE active AND (cardinality(events) = 0 OR $2 = ANY(events))
+		ON CONFLICT (webhookID, event_id) DO NOTHING`, msg.ID, msg.Type, payload, msg.TraceParent)
+
+	return err
+}
+
+// ClaimDueDeliveries selects at most limit pending deliveries which are due together with
+// the URL and the secret of their webhooks and the trace context of their events
+// and postpones their next attempt until the lease ends, so other dispatchers skip them meanwhile.
+// The claim is committed by itself, the deliveries are made outside of any transaction.
+// Returns an error if the error occurred while reading rows.
+func ClaimDueDeliveries(ctx context.Context, q Querier, limit int, leaseEnd time.Time) ([]model.WebhookDelivery, error) {
+	q = traced(q)
+
+	rows, err := q.Query(ctx, `WITH d AS (
+			UPDATE webhook_deliveries SET next_attempt_at = $2 WHERE id IN (
+				SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= now()
+				ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+			RETURNING *)
+		SELECT `+deliveryColumns+`, w.url, w.secret, d.trace_parent
+		FROM d JOIN webhooks w ON w.id = d.webhookID ORDER BY d.id`, limit, leaseEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+
+	for rows.Next() {
+		var url, secret, traceParent string
+
+		d, err := scanDelivery(rows, &url, &secret, &traceParent)
+		if err != nil {
+			return nil, err
+		}
+
+		d.URL, d.Secret, d.TraceParent = url, secret, traceParent
+		deliveries = append(deliveries, *d)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return deliveries, nil
+}
+
+// MarkDeliveryDelivered marks the delivery as accepted by the webhook.
+// Returns an error if the delivery can't be updated.
+func MarkDeliveryDelivered(ctx context.Context, q Querier, id int64, statusCode int) error {
+	q = traced(q)
+
+	_, err := q.Exec(ctx, `UPDATE webhook_deliveries
+		SET status = 'delivered', delivered_at = now(), last_status_code = $2, last_error = NULL WHERE id = $1`,
+		id, statusCode)
+
+	return err
+}
+
+// MarkDeliveryFailed records the failed attempt of the delivery.
+// The delivery is dead-lettered if dead is true, otherwise the next attempt is scheduled.
+// Returns an error if the delivery can't be updated.
+func MarkDeliveryFailed(ctx context.Context, q Querier, id int64, statusCode int, deliveryErr string,
+	nextAttempt time.Time, dead bool) error {
+	q = traced(q)
+
+	status := model.DeliveryPending
+	if dead {
+		status = model.DeliveryDead
+	}
+
+	_, err := q.Exec(ctx, `UPDATE webhook_deliveries SET attempts = attempts + 1, status = $2,
+		last_status_code = NULLIF($3, 0), last_error = $4, next_attempt_at = $5 WHERE id = $1`,
+		id, status, statusCode, deliveryErr, nextAttempt)
+
+	return err
+}
@@ -0,0 +1,283 @@
+// Package tlsconfig builds the TLS configuration of the servers from the certificate files
+// and reloads it when the files change, so the certificates are rotated without a restart.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+)
+
+// Modes of the verification of the client certificates.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// DefaultReloadInterval is how often the files are checked for changes.
+const DefaultReloadInterval = 30 * time.Second
+
+// Options represents the TLS settings of the server.
+type Options struct {
+	CertFile     string   // path of the PEM certificate chain of the server
+	KeyFile      string   // path of the PEM private key of the server
+	MinVersion   string   // minimal version of the protocol: 1.2 or 1.3
+	CipherSuites []string // names of the TLS 1.2 cipher suites, the Go defaults if empty
+	ClientAuth   string   // verification of the client certificates: none, optional or require
+	ClientCAFile string   // path of the PEM bundle of the CAs which issue the client certificates
+}
+
+// Reloader serves the certificate and the client CAs loaded from the files
+// and reloads them when the files are modified.
+type Reloader struct {
+	ReloadInterval time.Duration // how often the files are checked for changes
+
+	opts     Options
+	base     *tls.Config
+	mu       sync.RWMutex
+	config   *tls.Config
+	modTimes map[string]time.Time
+}
+
+// New is a constructor for Reloader struct which loads the files.
+// Returns an error if the options are invalid or the files can't be loaded.
+func New(opts Options) (*Reloader, error) {
+	minVersion, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := parseClientAuth(opts.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientAuth != tls.NoClientCert && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("client auth %q requires the client CA file", opts.ClientAuth)
+	}
+
+	r := Reloader{
+		ReloadInterval: DefaultReloadInterval,
+		opts:           opts,
+		base: &tls.Config{
+			MinVersion:   minVersion,
+			CipherSuites: suites,
+			ClientAuth:   clientAuth,
+			NextProtos:   []string{"h2", "http/1.1"},
+		},
+	}
+
+	err = r.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// TLSConfig returns the configuration of the server which uses the latest loaded files for every handshake.
+func (r *Reloader) TLSConfig() *tls.Config {
+	cfg := r.base.Clone()
+	cfg.GetCertificate = r.getCertificate
+	cfg.GetConfigForClient = r.getConfigForClient
+
+	return cfg
+}
+
+// getCertificate returns the latest loaded certificate.
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &r.config.Certificates[0], nil
+}
+
+// getConfigForClient returns the configuration with the latest loaded files.
+func (r *Reloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.config, nil
+}
+
+// Reload loads the files and replaces the served configuration.
+// Returns an error if the files can't be loaded, the previous configuration is served then.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	cfg := r.base.Clone()
+	cfg.Certificates = []tls.Certificate{cert}
+
+	if r.opts.ClientCAFile != "" {
+		pool, err := loadCertPool(r.opts.ClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		cfg.ClientCAs = pool
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = cfg
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// Run reloads the files when they are modified until the context is canceled.
+// The errors are logged and the previous configuration is served until the files are fixed.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.modified() {
+			continue
+		}
+
+		err := r.Reload()
+		if err != nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Reload TLS certificate error")
+
+			continue
+		}
+
+		logging.FromContext(ctx).Info().Str("cert_file", r.opts.CertFile).Msg("TLS certificate reloaded")
+	}
+}
+
+// modified reports whether any of the files was modified since the last reload.
+func (r *Reloader) modified() bool {
+	modTimes, err := r.stat()
+	if err != nil {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name, t := range modTimes {
+		if !t.Equal(r.modTimes[name]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stat returns the modification times of the files.
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+
+	for _, name := range []string{r.opts.CertFile, r.opts.KeyFile, r.opts.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+
+		modTimes[name] = fi.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+// loadCertPool loads the PEM bundle of the certificates.
+func loadCertPool(name string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificates in %s", name)
+	}
+
+	return pool, nil
+}
+
+// ParseVersion parses the protocol version: 1.2 or 1.3, TLS 1.2 if blank.
+func ParseVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(v), "tls") {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("unsupported TLS version %q", v)
+}
+
+// ParseCipherSuites parses the names of the cipher suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+// The insecure suites are rejected. Returns nil if the names are empty, so the Go defaults are used.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// parseClientAuth parses the mode of the verification of the client certificates.
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+
+	return 0, fmt.Errorf("unknown client auth %q", mode)
+}
@@ -0,0 +1,196 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues the certificates of the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key for the common name.
+func (ca *testCA) issue(t *testing.T, serial int64, cn string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, name string, b []byte) {
+	require.NoError(t, ioutil.WriteFile(name, b, 0o600))
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+
+	opts := Options{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientAuth:   ClientAuthRequire,
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+
+	certPEM, keyPEM := ca.issue(t, 2, "localhost", x509.ExtKeyUsageServerAuth)
+	writeFile(t, opts.CertFile, certPEM)
+	writeFile(t, opts.KeyFile, keyPEM)
+	writeFile(t, opts.ClientCAFile, ca.pem)
+
+	r, err := New(opts)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+	server.TLS = r.TLSConfig()
+	server.StartTLS()
+
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	clientPEM, clientKeyPEM := ca.issue(t, 3, "billing", x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	get := func(certs ...tls.Certificate) (*http.Response, *tls.ConnectionState, error) {
+		client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			ServerName:   "localhost",
+		}}}
+
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		defer resp.Body.Close()
+
+		return resp, resp.TLS, nil
+	}
+
+	_, _, err = get()
+	assert.Error(t, err, "the client certificate is required")
+
+	resp, state, err := get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, big.NewInt(2), state.PeerCertificates[0].SerialNumber)
+
+	assert.False(t, r.modified())
+
+	certPEM, keyPEM = ca.issue(t, 4, "localhost", x509.ExtKeyUsageServerAuth)
+	writeFile(t, opts.CertFile, certPEM)
+	writeFile(t, opts.KeyFile, keyPEM)
+
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(opts.CertFile, future, future))
+
+	assert.True(t, r.modified())
+	require.NoError(t, r.Reload())
+
+	_, state, err = get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(4), state.PeerCertificates[0].SerialNumber)
+
+	writeFile(t, opts.KeyFile, []byte("broken"))
+	assert.Error(t, r.Reload())
+
+	_, state, err = get(clientCert)
+	require.NoError(t, err, "the previous certificate is served")
+	assert.Equal(t, big.NewInt(4), state.PeerCertificates[0].SerialNumber)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tt := []struct {
+		name string
+		opts Options
+	}{
+		{name: "Unknown version", opts: Options{MinVersion: "1.1"}},
+		{name: "Unknown cipher suite", opts: Options{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}},
+		{name: "Unknown client auth", opts: Options{ClientAuth: "maybe"}},
+		{name: "Client auth without CA", opts: Options{ClientAuth: ClientAuthRequire}},
+		{name: "Missing files", opts: Options{CertFile: "missing.crt", KeyFile: "missing.key"}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, ids)
+
+	ids, err = ParseCipherSuites(nil)
+	require.NoError(t, err)
+	assert.Nil(t, ids)
+}
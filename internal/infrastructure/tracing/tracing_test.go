@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	tt := []struct {
+		name        string
+		exporter    string
+		expectedErr bool
+	}{
+		{name: "None", exporter: ExporterNone},
+		{name: "Default", exporter: ""},
+		{name: "Stdout", exporter: ExporterStdout},
+		{name: "Unknown", exporter: "zipkin", expectedErr: true},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tc.exporter, "", &bytes.Buffer{})
+			if tc.expectedErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewProvider(sdktrace.WithSyncer(exporter))
+
+	assert.Empty(t, TraceParent(context.Background()))
+	assert.Equal(t, context.Background(), WithTraceParent(context.Background(), ""))
+
+	ctx, span := tp.Tracer(InstrumentationName).Start(context.Background(), "request")
+	traceParent := TraceParent(ctx)
+	span.End()
+
+	require.NotEmpty(t, traceParent)
+
+	_, child := tp.Tracer(InstrumentationName).Start(WithTraceParent(context.Background(), traceParent), "delivery")
+	child.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+}
@@ -1,6 +1,16 @@
 package controller
 
-import "github.com/fedo3nik/cart-go-api/internal/domain/model"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
+
+// CreateCartRequest represents optional json request for the CreateCart handler.
+type CreateCartRequest struct {
+	Owner string `json:"owner"` // Owner of the cart, blank for anonymous cart
+}
 
 // CartResponse represents json response for the CreateCart and GetCart handlers.
 type CartResponse struct {
@@ -8,6 +18,21 @@ type CartResponse struct {
 	Items []model.CartItem `json:"items"` // Items in the cart
 }
 
+// ListedCartResponse represents a single cart in the json response of the ListCarts handler.
+type ListedCartResponse struct {
+	ID        int              `json:"id"`              // Cart ID
+	Owner     string           `json:"owner,omitempty"` // Owner of the cart
+	CreatedAt time.Time        `json:"created_at"`      // Time when the cart was created
+	UpdatedAt time.Time        `json:"updated_at"`      // Time when the cart was changed last time
+	Items     []model.CartItem `json:"items"`           // Items in the cart
+}
+
+// ListCartsResponse represents json response for the ListCarts handler.
+type ListCartsResponse struct {
+	Carts      []ListedCartResponse `json:"carts"`                 // Carts of the page
+	NextCursor string               `json:"next_cursor,omitempty"` // Cursor of the next page
+}
+
 // AddItemRequest represents json request for the AddItem handler.
 type AddItemRequest struct {
 	Product  string `json:"product"`  // Product title
@@ -26,7 +51,163 @@ type AddItemResponse struct {
 type RemoveItemResponse struct {
 }
 
-// ErrorResponse represents json response for the cases when the error is occurred.
+// ErrorResponse represents RFC 7807 problem+json response for the cases when the error is occurred.
 type ErrorResponse struct {
-	Message string `json:"message"` // Message of the error
+	Type   string `json:"type"`             // URI reference that identifies the problem type
+	Title  string `json:"title"`            // Short summary of the problem type
+	Status int    `json:"status"`           // HTTP status code
+	Detail string `json:"detail,omitempty"` // Explanation of the problem type
+	Code   string `json:"code"`             // Machine-readable error code
+}
+
+// CartItemResponse represents json of the item in the cart events.
+type CartItemResponse struct {
+	ID       int    `json:"id"`                 // Item ID
+	CartID   int    `json:"cart_id"`            // ID of the cart in which item was placed
+	Product  string `json:"product,omitempty"`  // Product title
+	Quantity int    `json:"quantity,omitempty"` // Quantity of the products in the item
+}
+
+// CartEventResponse represents json of the cart event streamed by the CartEvents handlers.
+type CartEventResponse struct {
+	Type       string           `json:"type"`        // Type of the event
+	CartID     int              `json:"cart_id"`     // ID of the changed cart
+	Item       CartItemResponse `json:"item"`        // Changed item
+	OccurredAt time.Time        `json:"occurred_at"` // Time when the change happened
+}
+
+// WebhookRequest represents json request for the CreateWebhook and UpdateWebhook handlers.
+type WebhookRequest struct {
+	URL    string   `json:"url"`              // URL to which the events are posted
+	Events []string `json:"events"`           // Types of the domain events to deliver, empty means all the events
+	Secret string   `json:"secret,omitempty"` // Secret used to sign the payloads, generated if blank
+	Active *bool    `json:"active,omitempty"` // Inactive webhooks don't receive new events, true by default
+}
+
+// WebhookResponse represents json response of the webhook.
+// The secret is returned only when the webhook is created.
+type WebhookResponse struct {
+	ID        int       `json:"id"`               // Webhook ID
+	URL       string    `json:"url"`              // URL to which the events are posted
+	Events    []string  `json:"events"`           // Types of the domain events to deliver
+	Secret    string    `json:"secret,omitempty"` // Secret used to sign the payloads
+	Active    bool      `json:"active"`           // Inactive webhooks don't receive new events
+	CreatedAt time.Time `json:"created_at"`       // Time when the webhook was created
+}
+
+// WebhookDeliveryResponse represents json of the webhook delivery in the deliveries log.
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`                         // Delivery ID
+	EventID        int64      `json:"event_id"`                   // ID of the domain event
+	EventType      string     `json:"event_type"`                 // Type of the domain event
+	Status         string     `json:"status"`                     // Status of the delivery: pending, delivered or dead
+	Attempts       int        `json:"attempts"`                   // Number of the failed attempts
+	LastStatusCode int        `json:"last_status_code,omitempty"` // HTTP status of the last attempt
+	LastError      string     `json:"last_error,omitempty"`       // Error of the last attempt
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`  // Time of the next attempt of the pending delivery
+	CreatedAt      time.Time  `json:"created_at"`                 // Time when the delivery was scheduled
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`     // Time when the webhook accepted the event
+}
+
+// ListDeliveriesResponse represents json response for the ListDeliveries handler.
+type ListDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"` // Latest deliveries, newest first
+}
+
+// CartHistoryEventResponse represents json of the event in the cart history.
+type CartHistoryEventResponse struct {
+	Version    int         `json:"version"`     // Version of the cart after the event
+	Type       string      `json:"type"`        // Type of the domain event
+	Data       interface{} `json:"data"`        // Domain event
+	OccurredAt time.Time   `json:"occurred_at"` // Time when the event was recorded
+}
+
+// CartHistoryResponse represents json response for the CartHistory handler.
+type CartHistoryResponse struct {
+	CartID int                        `json:"cart_id"` // ID of the cart
+	Events []CartHistoryEventResponse `json:"events"`  // Events ordered by version
+}
+
+// LimitUsageResponse represents json of the usage of the cart limit.
+type LimitUsageResponse struct {
+	Limit     *int `json:"limit"`     // Limit, null if there is no limit
+	Used      int  `json:"used"`      // Used capacity
+	Remaining *int `json:"remaining"` // Remaining capacity, null if there is no limit
+}
+
+// ProductLimitResponse represents json of the usage of the purchase limit of the product.
+type ProductLimitResponse struct {
+	Product   string `json:"product"`   // Name of the product
+	Limit     int    `json:"limit"`     // Maximum quantity of the product in the cart
+	Used      int    `json:"used"`      // Quantity of the product in the cart
+	Remaining int    `json:"remaining"` // Quantity of the product which may be added
+}
+
+// CartLimitsResponse represents json response for the CartLimits handler.
+type CartLimitsResponse struct {
+	CartID           int                    `json:"cart_id"`            // ID of the cart
+	Lines            LimitUsageResponse     `json:"lines"`              // Number of the items
+	Units            LimitUsageResponse     `json:"units"`              // Total quantity of the items
+	MaxLineQuantity  *int                   `json:"max_line_quantity"`  // Maximum quantity of the single item, null if there is no limit
+	MaxProductLength *int                   `json:"max_product_length"` // Maximum length of the product name, null if there is no limit
+	Products         []ProductLimitResponse `json:"products"`           // Purchase limits of the products
+}
+
+// AuditEntryResponse represents json of the audit log entry.
+type AuditEntryResponse struct {
+	ID         int64           `json:"id"`          // Entry ID
+	CartID     int             `json:"cart_id"`     // ID of the changed cart
+	Action     string          `json:"action"`      // Action: create_cart, add_item or remove_item
+	Actor      string          `json:"actor"`       // User or client that made the change
+	ClientIP   string          `json:"client_ip"`   // IP address of the client
+	UserAgent  string          `json:"user_agent"`  // User agent of the client
+	RequestID  string          `json:"request_id"`  // ID of the request
+	Before     json.RawMessage `json:"before"`      // State before the change, null if the entity didn't exist
+	After      json.RawMessage `json:"after"`       // State after the change, null if the entity was removed
+	OccurredAt time.Time       `json:"occurred_at"` // Time of the change
+}
+
+// AuditLogResponse represents json response for the ListAudit handler.
+type AuditLogResponse struct {
+	Entries    []AuditEntryResponse `json:"entries"`               // Entries of the page, newest first
+	NextCursor string               `json:"next_cursor,omitempty"` // Cursor of the next page, absent on the last page
+}
+
+// APIKeyRequest represents json request for the CreateAPIKey handler.
+type APIKeyRequest struct {
+	Name      string     `json:"name"`                 // Name of the client which uses the key
+	Scopes    []string   `json:"scopes"`               // Scopes granted to the client
+	Roles     []string   `json:"roles,omitempty"`      // Roles of the client, shopper by default
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Time after which the key is rejected, never by default
+}
+
+// APIKeyResponse represents json response of the API key.
+// The key is returned only when the API key is created or rotated.
+type APIKeyResponse struct {
+	ID         int        `json:"id"`                     // API key ID
+	Name       string     `json:"name"`                   // Name of the client which uses the key
+	Prefix     string     `json:"prefix"`                 // First characters of the key
+	Scopes     []string   `json:"scopes"`                 // Scopes granted to the client
+	Roles      []string   `json:"roles"`                  // Roles of the client, empty means shopper
+	Key        string     `json:"key,omitempty"`          // Plain key, returned only once
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`   // Time after which the key is rejected
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Time when the key was last used
+	CreatedAt  time.Time  `json:"created_at"`             // Time when the key was created
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`   // Time when the key was revoked
+}
+
+// HealthCheckResponse represents json of the result of the single health check.
+type HealthCheckResponse struct {
+	Name       string  `json:"name"`            // Name of the check
+	Status     string  `json:"status"`          // Status of the check: ok or failing
+	Error      string  `json:"error,omitempty"` // Error of the failing check
+	DurationMS float64 `json:"duration_ms"`     // Duration of the check in milliseconds
+}
+
+// HealthResponse represents json response for the Health handlers.
+type HealthResponse struct {
+	Status        string                `json:"status"`                   // Status of the application: ok, failing or draining
+	StartedAt     *time.Time            `json:"started_at,omitempty"`     // Time when the application was started
+	UptimeSeconds float64               `json:"uptime_seconds,omitempty"` // Time since the application was started
+	Checks        []HealthCheckResponse `json:"checks,omitempty"`         // Results of the checks, returned only by the details endpoint
 }
@@ -2,7 +2,7 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -12,6 +12,7 @@ import (
 	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 )
 
 // HTTPCreateCartHandler represents handler for CreateCart endpoint.
@@ -34,38 +35,17 @@ type HTTPGetCartHandler struct {
 	cartService service.Cart
 }
 
-func handleError(w http.ResponseWriter, err error) *dto.ErrorResponse {
-	if errors.Is(err, e.ErrDB) {
-		w.WriteHeader(http.StatusBadGateway)
+// decodeCreateCartRequest decodes the optional body of the CreateCart request.
+// Empty body is treated as a request for the anonymous cart.
+func decodeCreateCartRequest(r *http.Request) (*dto.CreateCartRequest, error) {
+	var req dto.CreateCartRequest
 
-		return &dto.ErrorResponse{Message: "Database error"}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.Wrap(e.ErrInvalidRequest, err.Error())
 	}
 
-	if errors.Is(err, e.ErrInvalidCartID) {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return &dto.ErrorResponse{Message: "Cart with the same ID does not exist"}
-	}
-
-	if errors.Is(err, e.ErrInvalidQuantity) {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return &dto.ErrorResponse{Message: "Products quantity must be positive"}
-	}
-
-	if errors.Is(err, e.ErrInvalidProduct) {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return &dto.ErrorResponse{Message: "Product title can't be blank"}
-	}
-
-	if errors.Is(err, e.ErrRemove) {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return &dto.ErrorResponse{Message: "Cart or item with these IDs does not exist"}
-	}
-
-	return nil
+	return &req, nil
 }
 
 // NewHTTPCreateCartHandler is a constructor for HTTPCreateCartHandler struct.
@@ -77,7 +57,7 @@ func NewHTTPCreateCartHandler(cartService service.Cart) *HTTPCreateCartHandler {
 // Returns a new cart
 // responses:
 //	200: createCartResponse
-//  502: errorResponse
+//	500: errorResponse
 
 // ServeHTTP is a method to handle CreateCart endpoint.
 // It uses ResponseWriter and pointer to the Request from the standard package http.
@@ -88,14 +68,16 @@ func (hh HTTPCreateCartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	w.Header().Set("Content-Type", "application/json")
 
-	cart, err := hh.cartService.CreateCart(r.Context())
+	req, err := decodeCreateCartRequest(r)
 	if err != nil {
-		resp := handleError(w, err)
+		WriteError(w, r, err)
 
-		err = json.NewEncoder(w).Encode(&resp)
-		if err != nil {
-			return
-		}
+		return
+	}
+
+	cart, err := hh.cartService.CreateCart(r.Context(), req.Owner)
+	if err != nil {
+		WriteError(w, r, err)
 
 		return
 	}
@@ -121,7 +103,8 @@ func NewHTTPAddItemHandler(cartService service.Cart) *HTTPAddItemHandler {
 // responses:
 //	200: addItemResponse
 //	400: errorResponse
-//	502: errorResponse
+//	422: errorResponse
+//	500: errorResponse
 
 // ServeHTTP is a method to handle AddItem endpoint.
 // It uses ResponseWriter and pointer to the Request from the standard package http.
@@ -136,6 +119,8 @@ func (hh HTTPAddItemHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	cartID, err := strconv.Atoi(strCartID)
 	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
 		return
 	}
 
@@ -145,19 +130,14 @@ func (hh HTTPAddItemHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
 
 		return
 	}
 
 	item, err := hh.cartService.AddItem(r.Context(), req.Product, req.Quantity, cartID)
 	if err != nil {
-		resp := handleError(w, err)
-
-		err = json.NewEncoder(w).Encode(&resp)
-		if err != nil {
-			return
-		}
+		WriteError(w, r, err)
 
 		return
 	}
@@ -185,7 +165,7 @@ func NewHTTPRemoveItemHandler(cartService service.Cart) *HTTPRemoveItemHandler {
 // responses:
 //	200: removeItemResponse
 //	400: errorResponse
-//	502: errorResponse
+//	500: errorResponse
 
 // ServeHTTP is a method to handle RemoveItem endpoint.
 // It uses ResponseWriter and pointer to the Request from the standard package http.
@@ -199,11 +179,15 @@ func (hh HTTPRemoveItemHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	cartID, err := strconv.Atoi(strCartID)
 	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
 		return
 	}
 
 	itemID, err := strconv.Atoi(strItemID)
 	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
 		return
 	}
 
@@ -211,14 +195,7 @@ func (hh HTTPRemoveItemHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	err = hh.cartService.RemoveItem(r.Context(), cartID, itemID)
 	if err != nil {
-		resp := handleError(w, err)
-
-		err = json.NewEncoder(w).Encode(&resp)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-
-			return
-		}
+		WriteError(w, r, err)
 
 		return
 	}
@@ -237,11 +214,11 @@ func NewHTTPGetCartHandler(cartService service.Cart) *HTTPGetCartHandler {
 }
 
 // swagger:route GET /carts/{cartID} carts getCart
-// Returns cart with the items in it
+// Returns cart with the items in it, as_of returns the cart as it was at that time
 // responses:
 //	200: getCartResponse
 //	400: errorResponse
-//	502: errorResponse
+//	500: errorResponse
 
 // ServeHTTP is a method to handle GetCart endpoint.
 // It uses ResponseWriter and pointer to the Request from the standard package http.
@@ -255,19 +232,16 @@ func (hh HTTPGetCartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	cartID, err := strconv.Atoi(strCartID)
 	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
 		return
 	}
 
 	var resp dto.CartResponse
 
-	cart, err := hh.cartService.GetCart(r.Context(), cartID)
+	cart, err := getCart(hh.cartService, r, cartID)
 	if err != nil {
-		resp := handleError(w, err)
-
-		err = json.NewEncoder(w).Encode(&resp)
-		if err != nil {
-			return
-		}
+		WriteError(w, r, err)
 
 		return
 	}
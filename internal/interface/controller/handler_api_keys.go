@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// HTTPCreateAPIKeyHandler represents handler for CreateAPIKey endpoint.
+type HTTPCreateAPIKeyHandler struct {
+	apiKeyService service.APIKeys
+}
+
+// HTTPListAPIKeysHandler represents handler for ListAPIKeys endpoint.
+type HTTPListAPIKeysHandler struct {
+	apiKeyService service.APIKeys
+}
+
+// HTTPRotateAPIKeyHandler represents handler for RotateAPIKey endpoint.
+type HTTPRotateAPIKeyHandler struct {
+	apiKeyService service.APIKeys
+}
+
+// HTTPRevokeAPIKeyHandler represents handler for RevokeAPIKey endpoint.
+type HTTPRevokeAPIKeyHandler struct {
+	apiKeyService service.APIKeys
+}
+
+// apiKeyID parses the API key ID from the path parameters.
+func apiKeyID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["keyID"])
+	if err != nil {
+		return 0, errors.Wrap(e.ErrInvalidRequest, err.Error())
+	}
+
+	return id, nil
+}
+
+// optionalTime returns nil for the zero time.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// newAPIKeyResponse converts the API key model to the response, the plain key is included if it is set.
+func newAPIKeyResponse(k *model.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		Roles:      k.Roles,
+		Key:        k.Key,
+		ExpiresAt:  optionalTime(k.ExpiresAt),
+		LastUsedAt: optionalTime(k.LastUsedAt),
+		CreatedAt:  k.CreatedAt,
+		RevokedAt:  optionalTime(k.RevokedAt),
+	}
+}
+
+// NewHTTPCreateAPIKeyHandler is a constructor for HTTPCreateAPIKeyHandler struct.
+func NewHTTPCreateAPIKeyHandler(apiKeyService service.APIKeys) *HTTPCreateAPIKeyHandler {
+	return &HTTPCreateAPIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// swagger:route POST /admin/api-keys admin createAPIKey
+// Creates a new API key of the server-to-server client
+// responses:
+//	201: apiKeyResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CreateAPIKey endpoint.
+// Responds with 201 Created, the key is returned only in this response.
+func (kh HTTPCreateAPIKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req dto.APIKeyRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	k := model.APIKey{Name: req.Name, Scopes: req.Scopes, Roles: req.Roles}
+	if req.ExpiresAt != nil {
+		k.ExpiresAt = *req.ExpiresAt
+	}
+
+	created, err := kh.apiKeyService.CreateAPIKey(r.Context(), &k)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := newAPIKeyResponse(created)
+	writeJSON(w, http.StatusCreated, &resp)
+}
+
+// NewHTTPListAPIKeysHandler is a constructor for HTTPListAPIKeysHandler struct.
+func NewHTTPListAPIKeysHandler(apiKeyService service.APIKeys) *HTTPListAPIKeysHandler {
+	return &HTTPListAPIKeysHandler{apiKeyService: apiKeyService}
+}
+
+// swagger:route GET /admin/api-keys admin listAPIKeys
+// Returns all the API keys without the keys themselves
+// responses:
+//	200: listAPIKeysResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle ListAPIKeys endpoint.
+func (kh HTTPListAPIKeysHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	keys, err := kh.apiKeyService.ListAPIKeys(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, newAPIKeyResponse(&keys[i]))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// NewHTTPRotateAPIKeyHandler is a constructor for HTTPRotateAPIKeyHandler struct.
+func NewHTTPRotateAPIKeyHandler(apiKeyService service.APIKeys) *HTTPRotateAPIKeyHandler {
+	return &HTTPRotateAPIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// swagger:route POST /admin/api-keys/{keyID}/rotate admin rotateAPIKey
+// Replaces the key of the API key, the previous key is rejected immediately
+// responses:
+//	200: apiKeyResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle RotateAPIKey endpoint.
+// The new key is returned only in this response.
+func (kh HTTPRotateAPIKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := apiKeyID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	k, err := kh.apiKeyService.RotateAPIKey(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := newAPIKeyResponse(k)
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+// NewHTTPRevokeAPIKeyHandler is a constructor for HTTPRevokeAPIKeyHandler struct.
+func NewHTTPRevokeAPIKeyHandler(apiKeyService service.APIKeys) *HTTPRevokeAPIKeyHandler {
+	return &HTTPRevokeAPIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// swagger:route DELETE /admin/api-keys/{keyID} admin revokeAPIKey
+// Revokes the API key
+// responses:
+//	204: revokeAPIKeyResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle RevokeAPIKey endpoint.
+// Responds with 204 No Content.
+func (kh HTTPRevokeAPIKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := apiKeyID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	err = kh.apiKeyService.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
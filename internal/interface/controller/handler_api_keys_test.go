@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gorilla/mux"
+)
+
+// stubAPIKeyService is an in-memory implementation of the service.APIKeys interface for handler tests.
+type stubAPIKeyService struct {
+	keys []model.APIKey
+}
+
+func (s *stubAPIKeyService) CreateAPIKey(_ context.Context, k *model.APIKey) (*model.APIKey, error) {
+	err := service.ValidateAPIKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	created := *k
+	created.ID = len(s.keys) + 1
+	created.Prefix = "ck_" + strconv.Itoa(created.ID)
+	s.keys = append(s.keys, created)
+	created.Key = created.Prefix + "_secret"
+
+	return &created, nil
+}
+
+func (s *stubAPIKeyService) ListAPIKeys(_ context.Context) ([]model.APIKey, error) {
+	return s.keys, nil
+}
+
+func (s *stubAPIKeyService) active(id int) (*model.APIKey, error) {
+	if id < 1 || id > len(s.keys) || !s.keys[id-1].RevokedAt.IsZero() {
+		return nil, e.ErrAPIKeyNotFound
+	}
+
+	return &s.keys[id-1], nil
+}
+
+func (s *stubAPIKeyService) RotateAPIKey(_ context.Context, id int) (*model.APIKey, error) {
+	k, err := s.active(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated := *k
+	rotated.Key = k.Prefix + "_rotated"
+
+	return &rotated, nil
+}
+
+func (s *stubAPIKeyService) RevokeAPIKey(_ context.Context, id int) error {
+	k, err := s.active(id)
+	if err != nil {
+		return err
+	}
+
+	k.RevokedAt = time.Now()
+
+	return nil
+}
+
+func (s *stubAPIKeyService) VerifyAPIKey(_ context.Context, _ string) (*auth.Principal, error) {
+	return nil, e.ErrUnauthenticated
+}
+
+func newAPIKeysTestRouter(ks *stubAPIKeyService) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(withRESTErrors)
+
+	r.Handle("/admin/api-keys", NewHTTPCreateAPIKeyHandler(ks)).Methods(http.MethodPost)
+	r.Handle("/admin/api-keys", NewHTTPListAPIKeysHandler(ks)).Methods(http.MethodGet)
+	r.Handle("/admin/api-keys/{keyID}/rotate", NewHTTPRotateAPIKeyHandler(ks)).Methods(http.MethodPost)
+	r.Handle("/admin/api-keys/{keyID}", NewHTTPRevokeAPIKeyHandler(ks)).Methods(http.MethodDelete)
+
+	return r
+}
+
+func TestAPIKeyHandlers_ServeHTTP(t *testing.T) {
+	server := httptest.NewServer(newAPIKeysTestRouter(&stubAPIKeyService{}))
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+	problem := httpexpect.ContentOpts{MediaType: problemContentType}
+
+	ex.POST("/admin/api-keys").WithJSON(dto.APIKeyRequest{Name: "billing", Scopes: []string{auth.ScopeCartRead}}).
+		Expect().Status(http.StatusCreated).JSON().Object().
+		ValueEqual("id", 1).ValueEqual("key", "ck_1_secret").NotContainsKey("expires_at")
+
+	past := time.Now().Add(-time.Hour)
+	tt := []struct {
+		name string
+		req  dto.APIKeyRequest
+	}{
+		{name: "Blank name", req: dto.APIKeyRequest{Scopes: []string{auth.ScopeCartRead}}},
+		{name: "No scopes", req: dto.APIKeyRequest{Name: "billing"}},
+		{name: "Unknown scope", req: dto.APIKeyRequest{Name: "billing", Scopes: []string{"cart:delete"}}},
+		{name: "Expired", req: dto.APIKeyRequest{Name: "billing", Scopes: []string{auth.ScopeCartRead}, ExpiresAt: &past}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ex.POST("/admin/api-keys").WithJSON(tc.req).Expect().Status(http.StatusBadRequest).
+				JSON(problem).Object().ValueEqual("code", "invalid_api_key")
+		})
+	}
+
+	ex.GET("/admin/api-keys").Expect().Status(http.StatusOK).
+		JSON().Array().Length().Equal(1)
+	ex.GET("/admin/api-keys").Expect().JSON().Array().Element(0).Object().NotContainsKey("key")
+
+	ex.POST("/admin/api-keys/1/rotate").Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("key", "ck_1_rotated")
+
+	ex.DELETE("/admin/api-keys/1").Expect().Status(http.StatusNoContent).Body().Empty()
+	ex.DELETE("/admin/api-keys/1").Expect().Status(http.StatusNotFound).
+		JSON(problem).Object().ValueEqual("code", "api_key_not_found")
+	ex.POST("/admin/api-keys/1/rotate").Expect().Status(http.StatusNotFound)
+	ex.GET("/admin/api-keys").Expect().JSON().Array().Element(0).Object().ContainsKey("revoked_at")
+}
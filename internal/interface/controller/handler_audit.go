@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPListAuditHandler represents handler for ListAudit endpoint.
+type HTTPListAuditHandler struct {
+	auditService service.Audit
+}
+
+// NewHTTPListAuditHandler is a constructor for HTTPListAuditHandler struct.
+func NewHTTPListAuditHandler(auditService service.Audit) *HTTPListAuditHandler {
+	return &HTTPListAuditHandler{auditService: auditService}
+}
+
+// swagger:route GET /admin/audit admin listAudit
+// Returns a page of the audit log entries matching the filters, newest first
+// responses:
+//	200: auditLogResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle ListAudit endpoint.
+// Filters and cursor are received from the query string.
+func (hh HTTPListAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	page, err := hh.auditService.ListAuditEntries(r.Context(), *filter)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.AuditLogResponse{Entries: make([]dto.AuditEntryResponse, 0, len(page.Entries)), NextCursor: page.NextCursor}
+
+	for i := range page.Entries {
+		entry := &page.Entries[i]
+
+		resp.Entries = append(resp.Entries, dto.AuditEntryResponse{
+			ID:         entry.ID,
+			CartID:     entry.CartID,
+			Action:     entry.Action,
+			Actor:      entry.Actor,
+			ClientIP:   entry.ClientIP,
+			UserAgent:  entry.UserAgent,
+			RequestID:  entry.RequestID,
+			Before:     entry.Before,
+			After:      entry.After,
+			OccurredAt: entry.OccurredAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+// parseAuditFilter parses the query string of the ListAudit request.
+// Returns ErrInvalidFilter if any parameter can't be parsed.
+func parseAuditFilter(q url.Values) (*model.AuditFilter, error) {
+	var err error
+
+	f := model.AuditFilter{Actor: q.Get("actor"), Cursor: q.Get("cursor")}
+
+	times := []struct {
+		param string
+		dst   *time.Time
+	}{
+		{param: "from", dst: &f.From},
+		{param: "to", dst: &f.To},
+	}
+
+	for _, t := range times {
+		if v := q.Get(t.param); v != "" {
+			*t.dst, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errors.Wrap(e.ErrInvalidFilter, t.param)
+			}
+		}
+	}
+
+	if v := q.Get("cart_id"); v != "" {
+		f.CartID, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.Wrap(e.ErrInvalidFilter, "cart_id")
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		f.Limit, err = strconv.Atoi(v)
+		if err != nil || f.Limit <= 0 {
+			return nil, errors.Wrap(e.ErrInvalidFilter, "limit")
+		}
+	}
+
+	return &f, nil
+}
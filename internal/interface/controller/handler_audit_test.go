@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuditService is an implementation of the service.Audit interface which records the received filter.
+type stubAuditService struct {
+	filter model.AuditFilter
+}
+
+func (s *stubAuditService) ListAuditEntries(_ context.Context, filter model.AuditFilter) (*model.AuditPage, error) {
+	s.filter = filter
+
+	return &model.AuditPage{
+		Entries: []model.AuditEntry{{
+			ID:       7,
+			CartID:   1,
+			Action:   model.AuditActionRemoveItem,
+			Actor:    "alice",
+			ClientIP: "192.0.2.1",
+			Before:   json.RawMessage(`{"id":3,"cart_id":1,"product":"Hat","quantity":1}`),
+			After:    json.RawMessage(`null`),
+		}},
+		NextCursor: "7",
+	}, nil
+}
+
+func TestHTTPListAuditHandler_ServeHTTP(t *testing.T) {
+	as := &stubAuditService{}
+
+	server := httptest.NewServer(NewHTTPListAuditHandler(as))
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+	problem := httpexpect.ContentOpts{MediaType: problemContentType}
+
+	obj := ex.GET("/admin/audit").
+		WithQuery("cart_id", 1).WithQuery("actor", "alice").
+		WithQuery("from", "2021-05-03T14:00:00Z").WithQuery("to", "2021-05-04T00:00:00Z").
+		WithQuery("limit", 10).WithQuery("cursor", "eyJzIjoiYXVkaXQifQ").
+		Expect().Status(http.StatusOK).JSON().Object()
+
+	obj.ValueEqual("next_cursor", "7")
+	entry := obj.Value("entries").Array().Element(0).Object()
+	entry.ValueEqual("action", "remove_item")
+	entry.Value("before").Object().ValueEqual("product", "Hat")
+	entry.Value("after").Null()
+
+	assert.Equal(t, model.AuditFilter{
+		CartID:   1,
+		Actor:    "alice",
+		From:     time.Date(2021, 5, 3, 14, 0, 0, 0, time.UTC),
+		To:       time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC),
+		Limit:    10,
+		Cursor:   "eyJzIjoiYXVkaXQifQ",
+	}, as.filter)
+
+	tt := []struct {
+		name  string
+		param string
+		value string
+		code  string
+	}{
+		{name: "Invalid cart", param: "cart_id", value: "x", code: "invalid_filter"},
+		{name: "Invalid time", param: "from", value: "yesterday", code: "invalid_filter"},
+		{name: "Invalid limit", param: "limit", value: "0", code: "invalid_filter"},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ex.GET("/admin/audit").WithQuery(tc.param, tc.value).Expect().Status(http.StatusBadRequest).
+				JSON(problem).Object().ValueEqual("code", tc.code)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// heartbeatInterval is how often an idle event stream sends a keep-alive message.
+const heartbeatInterval = 15 * time.Second
+
+// streamWriteTimeout is how long a single message of the event stream may be written.
+// The streams extend their write deadline before every message instead of using the WriteTimeout of the server.
+const streamWriteTimeout = 10 * time.Second
+
+// connKey is a context key of the connection of the request.
+type connKey struct{}
+
+// WithConn returns a copy of the context carrying the connection.
+// It is used as the ConnContext hook of the server, so the event streams can extend their write deadline.
+func WithConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// extendWriteDeadline postpones the write deadline of the connection of the request by streamWriteTimeout.
+// The HTTP/2 connections are shared by the requests, so their deadline isn't changed
+// and the stream ends after the WriteTimeout of the server, the EventSource clients reconnect then.
+func extendWriteDeadline(r *http.Request) {
+	conn, ok := r.Context().Value(connKey{}).(net.Conn)
+	if !ok || r.ProtoMajor != 1 {
+		return
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+}
+
+// HTTPCartEventsHandler represents handler for the CartEvents endpoint streaming Server-Sent Events.
+type HTTPCartEventsHandler struct {
+	cartService service.Cart
+	events      pubsub.Subscriber
+}
+
+// HTTPCartEventsWSHandler represents handler for the CartEvents endpoint streaming over WebSocket.
+type HTTPCartEventsWSHandler struct {
+	cartService service.Cart
+	events      pubsub.Subscriber
+	upgrader    websocket.Upgrader
+}
+
+// toCartEventResponse converts the cart event to the json response.
+func toCartEventResponse(event *model.CartEvent) *dto.CartEventResponse {
+	return &dto.CartEventResponse{
+		Type:   event.Type,
+		CartID: event.CartID,
+		Item: dto.CartItemResponse{
+			ID:       event.Item.ID,
+			CartID:   event.Item.CartID,
+			Product:  event.Item.Product,
+			Quantity: event.Item.Quantity,
+		},
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+// subscribeCart checks that the cart from the URL exists and subscribes to its events.
+func subscribeCart(r *http.Request, cartService service.Cart, events pubsub.Subscriber) (<-chan model.CartEvent, func(), error) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		return nil, nil, errors.Wrap(e.ErrInvalidRequest, err.Error())
+	}
+
+	_, err = cartService.GetCart(r.Context(), cartID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, cancel := events.Subscribe(cartID)
+
+	return ch, cancel, nil
+}
+
+// NewHTTPCartEventsHandler is a constructor for HTTPCartEventsHandler struct.
+func NewHTTPCartEventsHandler(cartService service.Cart, events pubsub.Subscriber) *HTTPCartEventsHandler {
+	return &HTTPCartEventsHandler{cartService: cartService, events: events}
+}
+
+// swagger:route GET /carts/{cartID}/events carts cartEvents
+// Streams item_added and item_removed events of the cart as Server-Sent Events
+// produces:
+//	- text/event-stream
+// responses:
+//	200: cartEventsResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CartEvents endpoint.
+// Every event is written as the SSE message with the event type as the event name and json data.
+// The write deadline is extended before every message, so the stream outlives the WriteTimeout of the server.
+// The stream is closed when the client disconnects.
+func (hh HTTPCartEventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, r, errors.New("streaming is not supported"))
+
+		return
+	}
+
+	ch, cancel, err := subscribeCart(r, hh.cartService, hh.events)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	extendWriteDeadline(r)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			extendWriteDeadline(r)
+
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-ch:
+			var data []byte
+
+			data, err = json.Marshal(toCartEventResponse(&event))
+			if err != nil {
+				return
+			}
+
+			extendWriteDeadline(r)
+
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		if err != nil {
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// NewHTTPCartEventsWSHandler is a constructor for HTTPCartEventsWSHandler struct.
+func NewHTTPCartEventsWSHandler(cartService service.Cart, events pubsub.Subscriber) *HTTPCartEventsWSHandler {
+	return &HTTPCartEventsWSHandler{cartService: cartService, events: events}
+}
+
+// swagger:route GET /carts/{cartID}/events/ws carts cartEventsWS
+// Streams the events of the cart over WebSocket
+// responses:
+//	101: cartEventsResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CartEvents endpoint over WebSocket.
+// Every event is sent as a json text message. Messages from the client are ignored,
+// the stream is closed when the client closes the connection or stops answering the pings.
+// The hijacked connection has no deadlines of the server, so every write sets its own.
+func (hh HTTPCartEventsWSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ch, cancel, err := subscribeCart(r, hh.cartService, hh.events)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	defer cancel()
+
+	conn, err := hh.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+		case event := <-ch:
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			err = conn.WriteJSON(toCartEventResponse(&event))
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
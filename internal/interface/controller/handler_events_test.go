@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventsTestWriteTimeout is the WriteTimeout of the test server which the event streams must outlive.
+const eventsTestWriteTimeout = 100 * time.Millisecond
+
+func newEventsTestServer(t *testing.T) (*httptest.Server, *pubsub.Hub) {
+	t.Helper()
+
+	cs := newStubCartService()
+
+	_, err := cs.CreateCart(context.Background(), "")
+	require.NoError(t, err)
+
+	hub := pubsub.NewHub()
+
+	r := mux.NewRouter()
+	r.Handle("/carts/{cartID}/events", NewHTTPCartEventsHandler(cs, hub))
+	r.Handle("/carts/{cartID}/events/ws", NewHTTPCartEventsWSHandler(cs, hub))
+
+	server := httptest.NewUnstartedServer(r)
+	server.Config.WriteTimeout = eventsTestWriteTimeout
+	server.Config.ConnContext = WithConn
+	server.Start()
+
+	return server, hub
+}
+
+func TestHTTPCartEventsHandler_ServeHTTP(t *testing.T) {
+	server, hub := newEventsTestServer(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/carts/5/events") //nolint:noctx
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/carts/1/events") //nolint:noctx
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	time.Sleep(2 * eventsTestWriteTimeout)
+
+	err = hub.Publish(context.Background(), &model.CartEvent{
+		Type:   model.CartEventItemAdded,
+		CartID: 1,
+		Item:   model.CartItem{ID: 1, CartID: 1, Product: "Hat", Quantity: 2},
+	})
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: item_added\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, `data: {"type":"item_added","cart_id":1,"item":{"id":1,"cart_id":1,"product":"Hat"`))
+}
+
+func TestHTTPCartEventsWSHandler_ServeHTTP(t *testing.T) {
+	server, hub := newEventsTestServer(t)
+	defer server.Close()
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/carts/1/events/ws", nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+	defer conn.Close()
+
+	time.Sleep(2 * eventsTestWriteTimeout)
+
+	err = hub.Publish(context.Background(), &model.CartEvent{
+		Type:   model.CartEventItemRemoved,
+		CartID: 1,
+		Item:   model.CartItem{ID: 4, CartID: 1},
+	})
+	require.NoError(t, err)
+
+	var event dto.CartEventResponse
+
+	err = conn.ReadJSON(&event)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.CartEventItemRemoved, event.Type)
+	assert.Equal(t, 4, event.Item.ID)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+)
+
+// HTTPLivenessHandler represents handler for Liveness endpoint.
+type HTTPLivenessHandler struct{}
+
+// NewHTTPLivenessHandler is a constructor for HTTPLivenessHandler struct.
+func NewHTTPLivenessHandler() *HTTPLivenessHandler {
+	return &HTTPLivenessHandler{}
+}
+
+// swagger:route GET /healthz health liveness
+// Reports that the process is alive
+// responses:
+//	200: healthResponse
+
+// ServeHTTP is a method to handle Liveness endpoint.
+func (HTTPLivenessHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, http.StatusOK, &dto.HealthResponse{Status: health.StatusOK})
+}
+
+// HTTPReadinessHandler represents handler for Readiness and HealthDetails endpoints.
+type HTTPReadinessHandler struct {
+	checker *health.Checker
+	details bool
+}
+
+// NewHTTPReadinessHandler is a constructor for HTTPReadinessHandler struct.
+// The handler reports the results of the checks if details is set.
+func NewHTTPReadinessHandler(checker *health.Checker, details bool) *HTTPReadinessHandler {
+	return &HTTPReadinessHandler{checker: checker, details: details}
+}
+
+// swagger:route GET /readyz health readiness
+// Reports whether the application is ready to serve the requests
+// responses:
+//	200: healthResponse
+//	503: healthResponse
+
+// swagger:route GET /health/details health healthDetails
+// Reports the results of the readiness checks
+// responses:
+//	200: healthResponse
+//	503: healthResponse
+
+// ServeHTTP is a method to handle Readiness and HealthDetails endpoints.
+func (rh HTTPReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := rh.checker.Report(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	resp := dto.HealthResponse{Status: report.Status}
+
+	if rh.details {
+		resp.StartedAt = &report.StartedAt
+		resp.UptimeSeconds = report.Uptime.Seconds()
+		resp.Checks = make([]dto.HealthCheckResponse, 0, len(report.Checks))
+
+		for _, c := range report.Checks {
+			resp.Checks = append(resp.Checks, dto.HealthCheckResponse{
+				Name:       c.Name,
+				Status:     c.Status,
+				Error:      c.Error,
+				DurationMS: float64(c.Duration) / float64(time.Millisecond),
+			})
+		}
+	}
+
+	writeHealth(w, status, &resp)
+}
+
+// writeHealth writes the health response which must never be cached.
+func writeHealth(w http.ResponseWriter, status int, resp *dto.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/health"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestHTTPHealthHandlers(t *testing.T) {
+	var dbErr error
+
+	checker := health.NewChecker()
+	checker.Add("database", func(context.Context) error { return dbErr })
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", NewHTTPLivenessHandler())
+	mux.Handle("/readyz", NewHTTPReadinessHandler(checker, false))
+	mux.Handle("/health/details", NewHTTPReadinessHandler(checker, true))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+
+	ex.GET("/healthz").Expect().Status(http.StatusOK).JSON().Object().ValueEqual("status", "ok")
+
+	ready := ex.GET("/readyz").Expect().Status(http.StatusOK)
+	ready.Header("Cache-Control").Equal("no-store")
+	ready.JSON().Object().ValueEqual("status", "ok").NotContainsKey("checks")
+
+	details := ex.GET("/health/details").Expect().Status(http.StatusOK).JSON().Object()
+	details.ValueEqual("status", "ok").ContainsKey("started_at").ContainsKey("uptime_seconds")
+	details.Value("checks").Array().Element(0).Object().
+		ValueEqual("name", "database").ValueEqual("status", "ok").NotContainsKey("error")
+
+	dbErr = errors.New("connection refused")
+
+	ex.GET("/readyz").Expect().Status(http.StatusServiceUnavailable).JSON().Object().ValueEqual("status", "failing")
+	ex.GET("/health/details").Expect().Status(http.StatusServiceUnavailable).JSON().Object().
+		Value("checks").Array().Element(0).Object().
+		ValueEqual("status", "failing").ValueEqual("error", "connection refused")
+
+	dbErr = nil
+
+	checker.Drain()
+
+	ex.GET("/readyz").Expect().Status(http.StatusServiceUnavailable).JSON().Object().ValueEqual("status", "draining")
+	ex.GET("/healthz").Expect().Status(http.StatusOK)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// HTTPCartHistoryHandler represents handler for CartHistory endpoint.
+type HTTPCartHistoryHandler struct {
+	cartService service.Cart
+}
+
+// NewHTTPCartHistoryHandler is a constructor for HTTPCartHistoryHandler struct.
+func NewHTTPCartHistoryHandler(cartService service.Cart) *HTTPCartHistoryHandler {
+	return &HTTPCartHistoryHandler{cartService: cartService}
+}
+
+// swagger:route GET /carts/{cartID}/history carts cartHistory
+// Returns all the events of the cart ordered by version
+// responses:
+//	200: cartHistoryResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CartHistory endpoint.
+func (hh HTTPCartHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	events, err := hh.cartService.GetCartHistory(r.Context(), cartID)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.CartHistoryResponse{CartID: cartID, Events: make([]dto.CartHistoryEventResponse, 0, len(events))}
+
+	for i := range events {
+		resp.Events = append(resp.Events, dto.CartHistoryEventResponse{
+			Version:    events[i].Version,
+			Type:       events[i].Event.EventType(),
+			Data:       events[i].Event,
+			OccurredAt: events[i].OccurredAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
+
+// getCart gets the cart for the GetCart endpoints.
+// If the request has the as_of query parameter, the cart is rebuilt as it was at that time.
+// Returns ErrInvalidRequest if as_of isn't an RFC 3339 timestamp.
+func getCart(cartService service.Cart, r *http.Request, cartID int) (*model.Cart, error) {
+	asOf := r.URL.Query().Get("as_of")
+	if asOf == "" {
+		return cartService.GetCart(r.Context(), cartID)
+	}
+
+	t, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrInvalidRequest, "as_of must be an RFC 3339 timestamp")
+	}
+
+	return cartService.GetCartAsOf(r.Context(), cartID, t)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCartHistoryHandler_ServeHTTP(t *testing.T) {
+	cs := newStubCartService()
+	ctx := context.Background()
+
+	_, err := cs.CreateCart(ctx, "alice")
+	require.NoError(t, err)
+
+	_, err = cs.AddItem(ctx, "Hat", 1, 1)
+	require.NoError(t, err)
+
+	before := cs.history[1].OccurredAt
+
+	_, err = cs.AddItem(ctx, "Shoes", 2, 1)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Handle("/carts/{cartID}/history", NewHTTPCartHistoryHandler(cs)).Methods(http.MethodGet)
+	r.Handle("/carts/{cartID}", NewHTTPGetCartHandler(cs)).Methods(http.MethodGet)
+	r.Handle("/v2/carts/{cartID}", withRESTErrors(NewHTTPGetCartV2Handler(cs))).Methods(http.MethodGet)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+	problem := httpexpect.ContentOpts{MediaType: problemContentType}
+
+	events := ex.GET("/carts/1/history").Expect().Status(http.StatusOK).JSON().Object().Value("events").Array()
+	events.Length().Equal(3)
+	events.Element(0).Object().ValueEqual("type", "CartCreated").ValueEqual("version", 1)
+	events.Element(2).Object().Value("data").Object().ValueEqual("product", "Shoes")
+
+	ex.GET("/carts/2/history").Expect().Status(http.StatusBadRequest).
+		JSON(problem).Object().ValueEqual("code", "cart_not_found")
+
+	tt := []struct {
+		name   string
+		path   string
+		asOf   string
+		status int
+		items  int
+	}{
+		{name: "Current state", path: "/carts/1", status: http.StatusOK, items: 2},
+		{name: "After the first item", path: "/carts/1", asOf: before.Format(time.RFC3339Nano), status: http.StatusOK, items: 1},
+		{name: "Before the cart", path: "/carts/1", asOf: "2000-01-01T00:00:00Z", status: http.StatusBadRequest},
+		{name: "Before the cart v2", path: "/v2/carts/1", asOf: "2000-01-01T00:00:00Z", status: http.StatusNotFound},
+		{name: "Invalid timestamp", path: "/carts/1", asOf: "yesterday", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := ex.GET(tc.path)
+			if tc.asOf != "" {
+				req = req.WithQuery("as_of", tc.asOf)
+			}
+
+			resp := req.Expect().Status(tc.status)
+			if tc.status == http.StatusOK {
+				resp.JSON().Object().Value("items").Array().Length().Equal(tc.items)
+			}
+		})
+	}
+}
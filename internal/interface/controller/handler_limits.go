@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// HTTPCartLimitsHandler represents handler for CartLimits endpoint.
+type HTTPCartLimitsHandler struct {
+	cartService service.Cart
+}
+
+// NewHTTPCartLimitsHandler is a constructor for HTTPCartLimitsHandler struct.
+func NewHTTPCartLimitsHandler(cartService service.Cart) *HTTPCartLimitsHandler {
+	return &HTTPCartLimitsHandler{cartService: cartService}
+}
+
+// swagger:route GET /carts/{cartID}/limits carts cartLimits
+// Returns the limits of the cart and their remaining capacity
+// responses:
+//	200: cartLimitsResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CartLimits endpoint.
+func (lh HTTPCartLimitsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	report, err := lh.cartService.GetCartLimits(r.Context(), cartID)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.CartLimitsResponse{
+		CartID:           report.CartID,
+		Lines:            newLimitUsageResponse(report.Lines),
+		Units:            newLimitUsageResponse(report.Units),
+		MaxLineQuantity:  optionalLimit(report.MaxLineQuantity),
+		MaxProductLength: optionalLimit(report.MaxProductLength),
+		Products:         make([]dto.ProductLimitResponse, 0, len(report.Products)),
+	}
+
+	for _, p := range report.Products {
+		resp.Products = append(resp.Products, dto.ProductLimitResponse{
+			Product:   p.Product,
+			Limit:     p.Limit,
+			Used:      p.Used,
+			Remaining: p.Remaining(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
+
+// newLimitUsageResponse converts the usage of the limit to its json representation.
+func newLimitUsageResponse(u model.LimitUsage) dto.LimitUsageResponse {
+	resp := dto.LimitUsageResponse{Limit: optionalLimit(u.Limit), Used: u.Used}
+	if resp.Limit != nil {
+		remaining := u.Remaining()
+		resp.Remaining = &remaining
+	}
+
+	return resp
+}
+
+// optionalLimit returns nil if the limit isn't set.
+func optionalLimit(limit int) *int {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &limit
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCartLimitsHandler_ServeHTTP(t *testing.T) {
+	cs := newStubCartService()
+	cs.limits = model.CartLimits{MaxLines: 3, MaxLineQuantity: 5, ProductLimits: map[string]int{"Hat": 2}}
+	ctx := context.Background()
+
+	_, err := cs.CreateCart(ctx, "alice")
+	require.NoError(t, err)
+
+	_, err = cs.AddItem(ctx, "Hat", 1, 1)
+	require.NoError(t, err)
+
+	_, err = cs.AddItem(ctx, "Shoes", 4, 1)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Handle("/carts/{cartID}/limits", NewHTTPCartLimitsHandler(cs)).Methods(http.MethodGet)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+	problem := httpexpect.ContentOpts{MediaType: problemContentType}
+
+	obj := ex.GET("/carts/1/limits").Expect().Status(http.StatusOK).JSON().Object()
+	obj.ValueEqual("cart_id", 1).ValueEqual("max_line_quantity", 5)
+	obj.Value("max_product_length").Null()
+	obj.Value("lines").Object().ValueEqual("limit", 3).ValueEqual("used", 2).ValueEqual("remaining", 1)
+	obj.Value("units").Object().ValueEqual("used", 5).Value("remaining").Null()
+
+	products := obj.Value("products").Array()
+	products.Length().Equal(1)
+	products.Element(0).Object().ValueEqual("product", "Hat").ValueEqual("remaining", 1)
+
+	ex.GET("/carts/2/limits").Expect().Status(http.StatusBadRequest).
+		JSON(problem).Object().ValueEqual("code", "cart_not_found")
+	ex.GET("/carts/abc/limits").Expect().Status(http.StatusBadRequest)
+}
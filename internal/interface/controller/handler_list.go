@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPListCartsHandler represents handler for ListCarts endpoint.
+type HTTPListCartsHandler struct {
+	cartService service.Cart
+}
+
+// NewHTTPListCartsHandler is a constructor for HTTPListCartsHandler struct.
+func NewHTTPListCartsHandler(cartService service.Cart) *HTTPListCartsHandler {
+	return &HTTPListCartsHandler{cartService: cartService}
+}
+
+// swagger:route GET /carts carts listCarts
+// Returns a page of carts matching the filters
+// responses:
+//	200: listCartsResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle ListCarts endpoint.
+// Filters, sorting and cursor are received from the query string.
+// The Link header contains the URLs of the first and the next pages.
+func (hh HTTPListCartsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCartFilter(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	page, err := hh.cartService.ListCarts(r.Context(), *filter)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.ListCartsResponse{Carts: make([]dto.ListedCartResponse, 0, len(page.Carts)), NextCursor: page.NextCursor}
+
+	for i := range page.Carts {
+		c := &page.Carts[i]
+
+		resp.Carts = append(resp.Carts, dto.ListedCartResponse{
+			ID:        c.ID,
+			Owner:     c.Owner,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+			Items:     c.Items,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Link", pageLinks(r.URL, page.NextCursor))
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
+
+// parseCartFilter parses the query string of the ListCarts request.
+// Sort field prefixed with "-" sorts carts in the descending order.
+// Returns ErrInvalidFilter if any parameter can't be parsed.
+func parseCartFilter(q url.Values) (*model.CartFilter, error) {
+	var err error
+
+	f := model.CartFilter{
+		Owner:   q.Get("owner"),
+		Product: q.Get("product"),
+		Cursor:  q.Get("cursor"),
+		Sort:    strings.TrimPrefix(q.Get("sort"), "-"),
+		Desc:    strings.HasPrefix(q.Get("sort"), "-"),
+	}
+
+	times := []struct {
+		param string
+		dst   *time.Time
+	}{
+		{param: "created_after", dst: &f.CreatedAfter},
+		{param: "created_before", dst: &f.CreatedBefore},
+		{param: "updated_after", dst: &f.UpdatedAfter},
+		{param: "updated_before", dst: &f.UpdatedBefore},
+	}
+
+	for _, t := range times {
+		if v := q.Get(t.param); v != "" {
+			*t.dst, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errors.Wrap(e.ErrInvalidFilter, t.param)
+			}
+		}
+	}
+
+	if v := q.Get("non_empty"); v != "" {
+		f.NonEmpty, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrap(e.ErrInvalidFilter, "non_empty")
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		f.Limit, err = strconv.Atoi(v)
+		if err != nil || f.Limit <= 0 {
+			return nil, errors.Wrap(e.ErrInvalidFilter, "limit")
+		}
+	}
+
+	return &f, nil
+}
+
+// pageLinks builds the value of the Link header with the first and the next page URLs.
+// The next link is omitted on the last page.
+func pageLinks(u *url.URL, nextCursor string) string {
+	link := func(cursor, rel string) string {
+		q := u.Query()
+		q.Del("cursor")
+
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+
+		l := url.URL{Path: u.Path, RawQuery: q.Encode()}
+
+		return "<" + l.String() + ">; rel=\"" + rel + "\""
+	}
+
+	links := []string{link("", "first")}
+	if nextCursor != "" {
+		links = append(links, link(nextCursor, "next"))
+	}
+
+	return strings.Join(links, ", ")
+}
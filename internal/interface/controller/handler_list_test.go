@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCartFilter(t *testing.T) {
+	tt := []struct {
+		name           string
+		query          string
+		expectedResult *model.CartFilter
+		expectedError  error
+	}{
+		{
+			name:  "All the filters",
+			query: "owner=bob&product=Hat&non_empty=true&created_after=2021-05-01T00:00:00Z&sort=-updated_at&limit=5",
+			expectedResult: &model.CartFilter{
+				Owner:        "bob",
+				Product:      "Hat",
+				NonEmpty:     true,
+				CreatedAfter: time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC),
+				Sort:         model.CartSortUpdatedAt,
+				Desc:         true,
+				Limit:        5,
+			},
+		},
+		{
+			name:          "Invalid time",
+			query:         "updated_before=yesterday",
+			expectedError: e.ErrInvalidFilter,
+		},
+		{
+			name:          "Invalid limit",
+			query:         "limit=-1",
+			expectedError: e.ErrInvalidFilter,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			f, err := parseCartFilter(q)
+			if err != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.Equal(t, tc.expectedResult, f)
+		})
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	u, err := url.Parse("/v2/carts?owner=bob&cursor=old")
+	require.NoError(t, err)
+
+	assert.Equal(t, `</v2/carts?owner=bob>; rel="first", </v2/carts?cursor=abc&owner=bob>; rel="next"`, pageLinks(u, "abc"))
+	assert.Equal(t, `</v2/carts?owner=bob>; rel="first"`, pageLinks(u, ""))
+}
@@ -22,9 +22,11 @@ func TestHTTPAddItemHandler_ServeHTTP(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	var maxItemID int
 
 	conn, err := pool.Acquire(context.Background())
@@ -51,9 +53,11 @@ func TestHTTPCreateCartHandler_ServeHTTP(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	var maxCartID int
 
 	conn, err := pool.Acquire(context.Background())
@@ -80,9 +84,11 @@ func TestHTTPGetCartHandler_ServeHTTP(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	cartService := service.NewCartService(pool)
 
 	handler := NewHTTPGetCartHandler(cartService)
@@ -99,9 +105,11 @@ func TestHTTPRemoveItemHandler_ServeHTTP(t *testing.T) {
 	c, err := config.NewConfig()
 	require.NoError(t, err)
 
-	pool, err := pgxpool.Connect(context.Background(), c.PostgresURL)
+	p, err := pgxpool.Connect(context.Background(), c.PostgresURL)
 	require.NoError(t, err)
 
+	pool := postgres.NewPool(p, nil)
+
 	id, err := postgres.InsertItem(context.Background(), pool, &model.CartItem{CartID: 3})
 	require.NoError(t, err)
 
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// HTTPCreateCartV2Handler represents handler for CreateCart endpoint of the v2 API.
+type HTTPCreateCartV2Handler struct {
+	cartService service.Cart
+}
+
+// HTTPAddItemV2Handler represents handler for AddItem endpoint of the v2 API.
+type HTTPAddItemV2Handler struct {
+	cartService service.Cart
+}
+
+// HTTPRemoveItemV2Handler represents handler for RemoveItem endpoint of the v2 API.
+type HTTPRemoveItemV2Handler struct {
+	cartService service.Cart
+}
+
+// HTTPGetCartV2Handler represents handler for GetCart endpoint of the v2 API.
+type HTTPGetCartV2Handler struct {
+	cartService service.Cart
+}
+
+// NewHTTPCreateCartV2Handler is a constructor for HTTPCreateCartV2Handler struct.
+func NewHTTPCreateCartV2Handler(cartService service.Cart) *HTTPCreateCartV2Handler {
+	return &HTTPCreateCartV2Handler{cartService: cartService}
+}
+
+// swagger:route POST /v2/carts carts createCartV2
+// Creates a new cart and returns it with the Location header
+// responses:
+//	201: createCartResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CreateCart endpoint of the v2 API.
+// Responds with 201 Created and the Location header pointing to the new cart.
+func (hh HTTPCreateCartV2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeCreateCartRequest(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	cart, err := hh.cartService.CreateCart(r.Context(), req.Owner)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.CartResponse{ID: cart.ID, Items: []model.CartItem{}}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(cart.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
+
+// NewHTTPAddItemV2Handler is a constructor for HTTPAddItemV2Handler struct.
+func NewHTTPAddItemV2Handler(cartService service.Cart) *HTTPAddItemV2Handler {
+	return &HTTPAddItemV2Handler{cartService: cartService}
+}
+
+// swagger:route POST /v2/carts/{cartID}/items items addItemV2
+// Adds a new cartItem and returns it with the Location header of the cart
+// responses:
+//	201: addItemResponse
+//	400: errorResponse
+//	404: errorResponse
+//	422: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle AddItem endpoint of the v2 API.
+// Responds with 201 Created, the new item and the Location header pointing to the cart which contains it,
+// or with 404 Not Found if the cart doesn't exist.
+func (hh HTTPAddItemV2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	var req dto.AddItemRequest
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	item, err := hh.cartService.AddItem(r.Context(), req.Product, req.Quantity, cartID)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.AddItemResponse{ID: item.ID, CartID: item.CartID, Product: item.Product, Quantity: item.Quantity}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/items"))
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
+
+// NewHTTPRemoveItemV2Handler is a constructor for HTTPRemoveItemV2Handler struct.
+func NewHTTPRemoveItemV2Handler(cartService service.Cart) *HTTPRemoveItemV2Handler {
+	return &HTTPRemoveItemV2Handler{cartService: cartService}
+}
+
+// swagger:route DELETE /v2/carts/{cartID}/items/{itemID} items removeItemV2
+// Removes the cartItem from the cart
+// responses:
+//	204: removeItemResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle RemoveItem endpoint of the v2 API.
+// Responds with 204 No Content, or with 404 Not Found if the cart or the item doesn't exist.
+func (hh HTTPRemoveItemV2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	itemID, err := strconv.Atoi(mux.Vars(r)["itemID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	err = hh.cartService.RemoveItem(r.Context(), cartID, itemID)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NewHTTPGetCartV2Handler is a constructor for HTTPGetCartV2Handler struct.
+func NewHTTPGetCartV2Handler(cartService service.Cart) *HTTPGetCartV2Handler {
+	return &HTTPGetCartV2Handler{cartService: cartService}
+}
+
+// swagger:route GET /v2/carts/{cartID} carts getCartV2
+// Returns cart with the items in it, as_of returns the cart as it was at that time
+// responses:
+//	200: getCartResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle GetCart endpoint of the v2 API.
+// Responds with 404 Not Found if the cart doesn't exist.
+func (hh HTTPGetCartV2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cartID, err := strconv.Atoi(mux.Vars(r)["cartID"])
+	if err != nil {
+		WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	cart, err := getCart(hh.cartService, r, cartID)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.CartResponse{ID: cart.ID, Items: cart.Items}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		return
+	}
+}
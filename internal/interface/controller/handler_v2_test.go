@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gorilla/mux"
+)
+
+// stubCartService is an in-memory implementation of the service.Cart interface for handler tests.
+type stubCartService struct {
+	carts   map[int]*model.Cart
+	history []model.CartHistoryEvent
+	limits  model.CartLimits
+}
+
+func newStubCartService() *stubCartService {
+	return &stubCartService{carts: map[int]*model.Cart{}}
+}
+
+func (s *stubCartService) CreateCart(_ context.Context, owner string) (*model.Cart, error) {
+	cart := model.Cart{ID: len(s.carts) + 1, Owner: owner, Items: []model.CartItem{}}
+	s.carts[cart.ID] = &cart
+	s.record(model.CartCreated{CartID: cart.ID, Owner: owner})
+
+	return &cart, nil
+}
+
+func (s *stubCartService) AddItem(_ context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	item := model.CartItem{ID: len(cart.Items) + 1, CartID: cartID, Product: product, Quantity: quantity}
+	cart.Items = append(cart.Items, item)
+	s.record(model.ItemAdded{CartID: cartID, ItemID: item.ID, Product: product, Quantity: quantity})
+
+	return &item, nil
+}
+
+func (s *stubCartService) RemoveItem(_ context.Context, cartID, itemID int) error {
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return e.ErrRemove
+	}
+
+	for i, item := range cart.Items {
+		if item.ID == itemID {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			s.record(model.ItemRemoved{CartID: cartID, ItemID: itemID})
+
+			return nil
+		}
+	}
+
+	return e.ErrRemove
+}
+
+func (s *stubCartService) GetCart(_ context.Context, cartID int) (*model.Cart, error) {
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return cart, nil
+}
+
+func (s *stubCartService) ListCarts(_ context.Context, filter model.CartFilter) (*model.CartPage, error) {
+	var page model.CartPage
+
+	for id := 1; id <= len(s.carts); id++ {
+		if filter.Owner == "" || s.carts[id].Owner == filter.Owner {
+			page.Carts = append(page.Carts, *s.carts[id])
+		}
+	}
+
+	return &page, nil
+}
+
+func (s *stubCartService) record(event model.DomainEvent) {
+	version := 1
+
+	for _, he := range s.history {
+		if he.CartID == event.EventCartID() {
+			version++
+		}
+	}
+
+	s.history = append(s.history, model.CartHistoryEvent{
+		CartID:     event.EventCartID(),
+		Version:    version,
+		Event:      event,
+		OccurredAt: time.Now(),
+	})
+}
+
+func (s *stubCartService) GetCartHistory(_ context.Context, cartID int) ([]model.CartHistoryEvent, error) {
+	var events []model.CartHistoryEvent
+
+	for _, he := range s.history {
+		if he.CartID == cartID {
+			events = append(events, he)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return events, nil
+}
+
+func (s *stubCartService) GetCartAsOf(_ context.Context, cartID int, asOf time.Time) (*model.Cart, error) {
+	var events []model.CartHistoryEvent
+
+	for _, he := range s.history {
+		if he.CartID == cartID && !he.OccurredAt.After(asOf) {
+			events = append(events, he)
+		}
+	}
+
+	cart := model.ReplayCart(nil, events)
+	if cart == nil {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return cart, nil
+}
+
+func (s *stubCartService) GetCartLimits(_ context.Context, cartID int) (*model.CartLimitsReport, error) {
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return s.limits.Report(cart), nil
+}
+
+func newV2TestRouter(cs *stubCartService) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(withRESTErrors)
+
+	r.Handle("/v2/carts", NewHTTPCreateCartV2Handler(cs)).Methods(http.MethodPost)
+	r.Handle("/v2/carts/{cartID}/items", NewHTTPAddItemV2Handler(cs)).Methods(http.MethodPost)
+	r.Handle("/v2/carts/{cartID}/items/{itemID}", NewHTTPRemoveItemV2Handler(cs)).Methods(http.MethodDelete)
+	r.Handle("/v2/carts/{cartID}", NewHTTPGetCartV2Handler(cs)).Methods(http.MethodGet)
+
+	return r
+}
+
+func TestV2Handlers_ServeHTTP(t *testing.T) {
+	server := httptest.NewServer(newV2TestRouter(newStubCartService()))
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+
+	created := ex.POST("/v2/carts").Expect().Status(http.StatusCreated)
+	created.Header("Location").Equal("/v2/carts/1")
+	created.JSON().Object().ValueEqual("id", 1)
+
+	item := ex.POST("/v2/carts/1/items").WithJSON(dto.AddItemRequest{Product: "Shoes", Quantity: 2}).
+		Expect().Status(http.StatusCreated)
+	item.Header("Location").Equal("/v2/carts/1")
+	item.JSON().Object().ValueEqual("product", "Shoes")
+
+	ex.POST("/v2/carts/5/items").WithJSON(dto.AddItemRequest{Product: "Shoes", Quantity: 2}).
+		Expect().Status(http.StatusNotFound).ContentType(problemContentType)
+
+	ex.GET("/v2/carts/5").Expect().Status(http.StatusNotFound).
+		JSON(httpexpect.ContentOpts{MediaType: problemContentType}).Object().ValueEqual("code", "cart_not_found")
+
+	ex.DELETE("/v2/carts/1/items/1").Expect().Status(http.StatusNoContent).Body().Empty()
+	ex.DELETE("/v2/carts/1/items/1").Expect().Status(http.StatusNotFound)
+
+	ex.GET("/v2/carts/abc").Expect().Status(http.StatusBadRequest).
+		JSON(httpexpect.ContentOpts{MediaType: problemContentType}).Object().ValueEqual("code", "invalid_request")
+}
@@ -0,0 +1,331 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// HTTPCreateWebhookHandler represents handler for CreateWebhook endpoint.
+type HTTPCreateWebhookHandler struct {
+	webhookService service.Webhooks
+}
+
+// HTTPListWebhooksHandler represents handler for ListWebhooks endpoint.
+type HTTPListWebhooksHandler struct {
+	webhookService service.Webhooks
+}
+
+// HTTPGetWebhookHandler represents handler for GetWebhook endpoint.
+type HTTPGetWebhookHandler struct {
+	webhookService service.Webhooks
+}
+
+// HTTPUpdateWebhookHandler represents handler for UpdateWebhook endpoint.
+type HTTPUpdateWebhookHandler struct {
+	webhookService service.Webhooks
+}
+
+// HTTPDeleteWebhookHandler represents handler for DeleteWebhook endpoint.
+type HTTPDeleteWebhookHandler struct {
+	webhookService service.Webhooks
+}
+
+// HTTPListDeliveriesHandler represents handler for ListDeliveries endpoint.
+type HTTPListDeliveriesHandler struct {
+	webhookService service.Webhooks
+}
+
+// decodeWebhookRequest decodes the webhook from the request body.
+// Webhooks are active unless the request disables them explicitly.
+func decodeWebhookRequest(r *http.Request) (*model.Webhook, error) {
+	var req dto.WebhookRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, errors.Wrap(e.ErrInvalidRequest, err.Error())
+	}
+
+	w := model.Webhook{URL: req.URL, Events: req.Events, Secret: req.Secret, Active: true}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+
+	return &w, nil
+}
+
+// webhookID parses the webhook ID from the path parameters.
+func webhookID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["webhookID"])
+	if err != nil {
+		return 0, errors.Wrap(e.ErrInvalidRequest, err.Error())
+	}
+
+	return id, nil
+}
+
+// newWebhookResponse converts the webhook model to the response without the secret.
+func newWebhookResponse(w *model.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{ID: w.ID, URL: w.URL, Events: w.Events, Active: w.Active, CreatedAt: w.CreatedAt}
+}
+
+// writeJSON writes the value as json with the status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		return
+	}
+}
+
+// NewHTTPCreateWebhookHandler is a constructor for HTTPCreateWebhookHandler struct.
+func NewHTTPCreateWebhookHandler(webhookService service.Webhooks) *HTTPCreateWebhookHandler {
+	return &HTTPCreateWebhookHandler{webhookService: webhookService}
+}
+
+// swagger:route POST /v2/webhooks webhooks createWebhook
+// Subscribes a new webhook to the domain events
+// responses:
+//	201: webhookResponse
+//	400: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle CreateWebhook endpoint.
+// Responds with 201 Created and the Location header, the secret is returned only in this response.
+func (hh HTTPCreateWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhook, err := decodeWebhookRequest(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	webhook, err = hh.webhookService.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := newWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(webhook.ID))
+	writeJSON(w, http.StatusCreated, &resp)
+}
+
+// NewHTTPListWebhooksHandler is a constructor for HTTPListWebhooksHandler struct.
+func NewHTTPListWebhooksHandler(webhookService service.Webhooks) *HTTPListWebhooksHandler {
+	return &HTTPListWebhooksHandler{webhookService: webhookService}
+}
+
+// swagger:route GET /v2/webhooks webhooks listWebhooks
+// Returns all the webhooks
+// responses:
+//	200: listWebhooksResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle ListWebhooks endpoint.
+func (hh HTTPListWebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := hh.webhookService.ListWebhooks(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := make([]dto.WebhookResponse, 0, len(webhooks))
+	for i := range webhooks {
+		resp = append(resp, newWebhookResponse(&webhooks[i]))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// NewHTTPGetWebhookHandler is a constructor for HTTPGetWebhookHandler struct.
+func NewHTTPGetWebhookHandler(webhookService service.Webhooks) *HTTPGetWebhookHandler {
+	return &HTTPGetWebhookHandler{webhookService: webhookService}
+}
+
+// swagger:route GET /v2/webhooks/{webhookID} webhooks getWebhook
+// Returns the webhook
+// responses:
+//	200: webhookResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle GetWebhook endpoint.
+func (hh HTTPGetWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	webhook, err := hh.webhookService.GetWebhook(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := newWebhookResponse(webhook)
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+// NewHTTPUpdateWebhookHandler is a constructor for HTTPUpdateWebhookHandler struct.
+func NewHTTPUpdateWebhookHandler(webhookService service.Webhooks) *HTTPUpdateWebhookHandler {
+	return &HTTPUpdateWebhookHandler{webhookService: webhookService}
+}
+
+// swagger:route PUT /v2/webhooks/{webhookID} webhooks updateWebhook
+// Replaces the webhook, the secret is rotated only if a new one is received
+// responses:
+//	200: webhookResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle UpdateWebhook endpoint.
+func (hh HTTPUpdateWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	webhook, err := decodeWebhookRequest(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	webhook.ID = id
+
+	webhook, err = hh.webhookService.UpdateWebhook(r.Context(), webhook)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := newWebhookResponse(webhook)
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+// NewHTTPDeleteWebhookHandler is a constructor for HTTPDeleteWebhookHandler struct.
+func NewHTTPDeleteWebhookHandler(webhookService service.Webhooks) *HTTPDeleteWebhookHandler {
+	return &HTTPDeleteWebhookHandler{webhookService: webhookService}
+}
+
+// swagger:route DELETE /v2/webhooks/{webhookID} webhooks deleteWebhook
+// Unsubscribes the webhook and drops its deliveries
+// responses:
+//	204: deleteWebhookResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle DeleteWebhook endpoint.
+// Responds with 204 No Content.
+func (hh HTTPDeleteWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	err = hh.webhookService.DeleteWebhook(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NewHTTPListDeliveriesHandler is a constructor for HTTPListDeliveriesHandler struct.
+func NewHTTPListDeliveriesHandler(webhookService service.Webhooks) *HTTPListDeliveriesHandler {
+	return &HTTPListDeliveriesHandler{webhookService: webhookService}
+}
+
+// swagger:route GET /v2/webhooks/{webhookID}/deliveries webhooks listDeliveries
+// Returns the latest deliveries of the webhook, newest first
+// responses:
+//	200: listDeliveriesResponse
+//	400: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+
+// ServeHTTP is a method to handle ListDeliveries endpoint.
+// The page size is received in the limit query parameter.
+func (hh HTTPListDeliveriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookID(r)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	var limit int
+
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil {
+			WriteError(w, r, errors.Wrap(e.ErrInvalidFilter, "limit must be an integer"))
+
+			return
+		}
+	}
+
+	deliveries, err := hh.webhookService.ListDeliveries(r.Context(), id, limit)
+	if err != nil {
+		WriteError(w, r, err)
+
+		return
+	}
+
+	resp := dto.ListDeliveriesResponse{Deliveries: make([]dto.WebhookDeliveryResponse, 0, len(deliveries))}
+
+	for i := range deliveries {
+		d := &deliveries[i]
+		item := dto.WebhookDeliveryResponse{
+			ID:             d.ID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+		}
+
+		if d.Status == model.DeliveryPending {
+			item.NextAttemptAt = &d.NextAttemptAt
+		}
+
+		if !d.DeliveredAt.IsZero() {
+			item.DeliveredAt = &d.DeliveredAt
+		}
+
+		resp.Deliveries = append(resp.Deliveries, item)
+	}
+
+	writeJSON(w, http.StatusOK, &resp)
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gorilla/mux"
+)
+
+// stubWebhookService is an in-memory implementation of the service.Webhooks interface for handler tests.
+type stubWebhookService struct {
+	webhooks   map[int]*model.Webhook
+	deliveries map[int][]model.WebhookDelivery
+}
+
+func newStubWebhookService() *stubWebhookService {
+	return &stubWebhookService{webhooks: map[int]*model.Webhook{}, deliveries: map[int][]model.WebhookDelivery{}}
+}
+
+func (s *stubWebhookService) CreateWebhook(_ context.Context, w *model.Webhook) (*model.Webhook, error) {
+	err := service.ValidateWebhook(w)
+	if err != nil {
+		return nil, err
+	}
+
+	created := *w
+	created.ID = len(s.webhooks) + 1
+
+	if created.Secret == "" {
+		created.Secret = "generated"
+	}
+
+	s.webhooks[created.ID] = &created
+
+	return &created, nil
+}
+
+func (s *stubWebhookService) ListWebhooks(_ context.Context) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	for id := 1; id <= len(s.webhooks); id++ {
+		if w, ok := s.webhooks[id]; ok {
+			webhooks = append(webhooks, *w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (s *stubWebhookService) GetWebhook(_ context.Context, id int) (*model.Webhook, error) {
+	w, ok := s.webhooks[id]
+	if !ok {
+		return nil, e.ErrWebhookNotFound
+	}
+
+	return w, nil
+}
+
+func (s *stubWebhookService) UpdateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	current, err := s.GetWebhook(ctx, w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *w
+	if updated.Secret == "" {
+		updated.Secret = current.Secret
+	}
+
+	s.webhooks[w.ID] = &updated
+
+	return &updated, nil
+}
+
+func (s *stubWebhookService) DeleteWebhook(_ context.Context, id int) error {
+	if _, ok := s.webhooks[id]; !ok {
+		return e.ErrWebhookNotFound
+	}
+
+	delete(s.webhooks, id)
+
+	return nil
+}
+
+func (s *stubWebhookService) ListDeliveries(ctx context.Context, webhookID, _ int) ([]model.WebhookDelivery, error) {
+	_, err := s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deliveries[webhookID], nil
+}
+
+func newWebhooksTestRouter(ws *stubWebhookService) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(withRESTErrors)
+
+	r.Handle("/v2/webhooks", NewHTTPCreateWebhookHandler(ws)).Methods(http.MethodPost)
+	r.Handle("/v2/webhooks", NewHTTPListWebhooksHandler(ws)).Methods(http.MethodGet)
+	r.Handle("/v2/webhooks/{webhookID}", NewHTTPGetWebhookHandler(ws)).Methods(http.MethodGet)
+	r.Handle("/v2/webhooks/{webhookID}", NewHTTPUpdateWebhookHandler(ws)).Methods(http.MethodPut)
+	r.Handle("/v2/webhooks/{webhookID}", NewHTTPDeleteWebhookHandler(ws)).Methods(http.MethodDelete)
+	r.Handle("/v2/webhooks/{webhookID}/deliveries", NewHTTPListDeliveriesHandler(ws)).Methods(http.MethodGet)
+
+	return r
+}
+
+func TestWebhookHandlers_ServeHTTP(t *testing.T) {
+	ws := newStubWebhookService()
+	ws.deliveries[1] = []model.WebhookDelivery{
+		{ID: 2, EventID: 9, EventType: model.EventItemAdded, Status: model.DeliveryDead, Attempts: 8, LastStatusCode: 500},
+		{ID: 1, EventID: 8, EventType: model.EventCartCreated, Status: model.DeliveryPending, Attempts: 1},
+	}
+
+	server := httptest.NewServer(newWebhooksTestRouter(ws))
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+	problem := httpexpect.ContentOpts{MediaType: problemContentType}
+
+	created := ex.POST("/v2/webhooks").
+		WithJSON(dto.WebhookRequest{URL: "https://partner.example.com/hook", Events: []string{model.EventItemAdded}}).
+		Expect().Status(http.StatusCreated)
+	created.Header("Location").Equal("/v2/webhooks/1")
+	created.JSON().Object().ValueEqual("secret", "generated").ValueEqual("active", true)
+
+	ex.POST("/v2/webhooks").WithJSON(dto.WebhookRequest{URL: "ftp://partner.example.com"}).
+		Expect().Status(http.StatusBadRequest).JSON(problem).Object().ValueEqual("code", "invalid_webhook")
+	ex.POST("/v2/webhooks").WithJSON(dto.WebhookRequest{URL: "https://partner.example.com", Events: []string{"Unknown"}}).
+		Expect().Status(http.StatusBadRequest).JSON(problem).Object().ValueEqual("code", "invalid_webhook")
+
+	ex.GET("/v2/webhooks/1").Expect().Status(http.StatusOK).JSON().Object().NotContainsKey("secret")
+	ex.GET("/v2/webhooks").Expect().Status(http.StatusOK).JSON().Array().Length().Equal(1)
+	ex.GET("/v2/webhooks/7").Expect().Status(http.StatusNotFound).
+		JSON(problem).Object().ValueEqual("code", "webhook_not_found")
+
+	inactive := false
+	ex.PUT("/v2/webhooks/1").WithJSON(dto.WebhookRequest{URL: "https://partner.example.com/v2", Active: &inactive}).
+		Expect().Status(http.StatusOK).JSON().Object().ValueEqual("active", false).NotContainsKey("secret")
+
+	deliveries := ex.GET("/v2/webhooks/1/deliveries").Expect().Status(http.StatusOK).
+		JSON().Object().Value("deliveries").Array()
+	deliveries.Length().Equal(2)
+	deliveries.Element(0).Object().ValueEqual("status", "dead").NotContainsKey("next_attempt_at")
+	deliveries.Element(1).Object().ContainsKey("next_attempt_at")
+
+	ex.GET("/v2/webhooks/1/deliveries").WithQuery("limit", "x").Expect().Status(http.StatusBadRequest)
+
+	ex.DELETE("/v2/webhooks/1").Expect().Status(http.StatusNoContent).Body().Empty()
+	ex.DELETE("/v2/webhooks/1").Expect().Status(http.StatusNotFound)
+	ex.GET("/v2/webhooks/1/deliveries").Expect().Status(http.StatusNotFound)
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+)
+
+// problemContentType is a media type of the RFC 7807 error responses.
+const problemContentType = "application/problem+json"
+
+// problemTypeBase is a prefix of the problem type URI, the error code is appended to it.
+const problemTypeBase = "https://github.com/fedo3nik/cart-go-api/problems/"
+
+// problem describes how a domain error is represented in the response.
+type problem struct {
+	err    error  // Domain error from the errors package
+	status int    // HTTP status code
+	code   string // Machine-readable error code
+	title  string // Short summary of the problem type
+	detail string // Explanation of the problem type, the underlying errors are logged instead of returned
+}
+
+// internalProblem is used for the errors which are not registered in the problems registry.
+var internalProblem = problem{
+	status: http.StatusInternalServerError,
+	code:   "internal_error",
+	title:  "Internal server error",
+}
+
+// problems is a registry that maps domain errors to the problem responses.
+// Errors are matched with errors.Is in the order of declaration.
+var problems = []problem{
+	{
+		err:    e.ErrDB,
+		status: http.StatusInternalServerError,
+		code:   "database_error",
+		title:  "Database error",
+		detail: "The database failed to process the request, retry it later.",
+	},
+	{
+		err:    e.ErrInvalidCartID,
+		status: http.StatusBadRequest,
+		code:   "cart_not_found",
+		title:  "Cart with the same ID does not exist",
+		detail: "The cart doesn't exist or was deleted.",
+	},
+	{
+		err:    e.ErrInvalidQuantity,
+		status: http.StatusBadRequest,
+		code:   "invalid_quantity",
+		title:  "Products quantity must be positive",
+		detail: "The quantity of the item must be a positive number.",
+	},
+	{
+		err:    e.ErrInvalidProduct,
+		status: http.StatusBadRequest,
+		code:   "invalid_product",
+		title:  "Product title can't be blank",
+		detail: "The product of the item must not be blank.",
+	},
+	{
+		err:    e.ErrRemove,
+		status: http.StatusBadRequest,
+		code:   "item_not_found",
+		title:  "Cart or item with these IDs does not exist",
+		detail: "The item doesn't exist in the cart.",
+	},
+	{
+		err:    e.ErrInvalidRequest,
+		status: http.StatusBadRequest,
+		code:   "invalid_request",
+		title:  "Request is malformed",
+		detail: "The body, the query or the path parameters of the request can't be parsed.",
+	},
+	{
+		err:    e.ErrMethodNotAllowed,
+		status: http.StatusMethodNotAllowed,
+		code:   "method_not_allowed",
+		title:  "Method not allowed",
+		detail: "The resource doesn't support the method, see the Allow header.",
+	},
+	{
+		err:    e.ErrInvalidCursor,
+		status: http.StatusBadRequest,
+		code:   "invalid_cursor",
+		title:  "Pagination cursor is invalid",
+		detail: "The cursor must be taken from the previous page of the same list.",
+	},
+	{
+		err:    e.ErrInvalidFilter,
+		status: http.StatusBadRequest,
+		code:   "invalid_filter",
+		title:  "List filter is invalid",
+		detail: "The filter, the sort field or the order of the list isn't supported.",
+	},
+	{
+		err:    e.ErrInvalidWebhook,
+		status: http.StatusBadRequest,
+		code:   "invalid_webhook",
+		title:  "Webhook is invalid",
+		detail: "The URL or the events of the webhook are invalid.",
+	},
+	{
+		err:    e.ErrWebhookNotFound,
+		status: http.StatusBadRequest,
+		code:   "webhook_not_found",
+		title:  "Webhook with the same ID does not exist",
+		detail: "The webhook doesn't exist or was deleted.",
+	},
+	{
+		err:    e.ErrUnauthenticated,
+		status: http.StatusUnauthorized,
+		code:   "unauthenticated",
+		title:  "Authentication is required",
+		detail: "The credentials are missing, invalid or expired.",
+	},
+	{
+		err:    e.ErrForbidden,
+		status: http.StatusForbidden,
+		code:   "forbidden",
+		title:  "Operation is not allowed",
+		detail: "The credentials don't grant the scope required by the operation.",
+	},
+	{
+		err:    e.ErrAccessDenied,
+		status: http.StatusForbidden,
+		code:   "access_denied",
+		title:  "Operation on the cart is not allowed",
+		detail: "The roles of the caller don't allow the operation on the resource.",
+	},
+	{
+		err:    e.ErrRateLimited,
+		status: http.StatusTooManyRequests,
+		code:   "rate_limited",
+		title:  "Too many requests",
+		detail: "The client made too many requests, retry after the Retry-After header.",
+	},
+	{
+		err:    e.ErrProductNameTooLong,
+		status: http.StatusBadRequest,
+		code:   "product_name_too_long",
+		title:  "Product title is too long",
+		detail: "The product title exceeds the maximum length.",
+	},
+	{
+		err:    e.ErrLineQuantityExceeded,
+		status: http.StatusBadRequest,
+		code:   "line_quantity_exceeded",
+		title:  "Item quantity exceeds the limit",
+		detail: "The quantity of the item exceeds the maximum quantity of a line.",
+	},
+	{
+		err:    e.ErrTooManyLines,
+		status: http.StatusUnprocessableEntity,
+		code:   "too_many_lines",
+		title:  "Cart has the maximum number of items",
+		detail: "The cart can't have more items, remove one first.",
+	},
+	{
+		err:    e.ErrCartUnitsExceeded,
+		status: http.StatusUnprocessableEntity,
+		code:   "cart_units_exceeded",
+		title:  "Cart quantity exceeds the limit",
+		detail: "The total quantity of the cart would exceed the limit.",
+	},
+	{
+		err:    e.ErrProductLimitExceeded,
+		status: http.StatusUnprocessableEntity,
+		code:   "product_limit_exceeded",
+		title:  "Product purchase limit is exceeded",
+		detail: "The quantity of the product would exceed its purchase limit.",
+	},
+	{
+		err:    e.ErrInvalidAPIKey,
+		status: http.StatusBadRequest,
+		code:   "invalid_api_key",
+		title:  "API key is invalid",
+		detail: "The name, the scopes, the roles or the expiry of the API key are invalid.",
+	},
+	{
+		err:    e.ErrAPIKeyNotFound,
+		status: http.StatusBadRequest,
+		code:   "api_key_not_found",
+		title:  "Active API key with the same ID does not exist",
+		detail: "The API key doesn't exist or was revoked.",
+	},
+}
+
+// notFoundStatuses override the statuses of the problems in the REST versions of the API,
+// which report missing resources as 404 Not Found instead of 400 Bad Request.
+var notFoundStatuses = map[error]int{
+	e.ErrInvalidCartID:   http.StatusNotFound,
+	e.ErrRemove:          http.StatusNotFound,
+	e.ErrWebhookNotFound: http.StatusNotFound,
+	e.ErrAPIKeyNotFound:  http.StatusNotFound,
+}
+
+// restErrorsKey is a context key of the flag of the REST versions of the API.
+type restErrorsKey struct{}
+
+// WithRESTErrors returns a copy of the context in which the errors are reported with the statuses
+// of the REST versions of the API, e.g. 404 Not Found for missing resources.
+func WithRESTErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restErrorsKey{}, true)
+}
+
+// restErrors reports whether the errors of the request are reported with the statuses of the REST versions.
+func restErrors(r *http.Request) bool {
+	rest, _ := r.Context().Value(restErrorsKey{}).(bool)
+
+	return rest
+}
+
+// lookupProblem finds the problem for the error in the registry, rest applies the statuses of the REST versions.
+// Returns internalProblem if the error is not registered.
+func lookupProblem(err error, rest bool) problem {
+	for _, p := range problems {
+		if !errors.Is(err, p.err) {
+			continue
+		}
+
+		if status, ok := notFoundStatuses[p.err]; ok && rest {
+			p.status = status
+		}
+
+		return p
+	}
+
+	return internalProblem
+}
+
+// ErrorCode returns the machine-readable code of the error from the problems registry.
+// It is used by the non-HTTP interfaces to report the same codes as the REST API.
+func ErrorCode(err error) string {
+	return lookupProblem(err, false).code
+}
+
+// newErrorResponse builds the problem response for the error using the registry, rest applies the statuses
+// of the REST versions.
+// The detail is fixed per problem type, so the messages of the underlying errors aren't exposed.
+func newErrorResponse(err error, rest bool) *dto.ErrorResponse {
+	p := lookupProblem(err, rest)
+
+	return &dto.ErrorResponse{
+		Type:   problemTypeBase + p.code,
+		Title:  p.title,
+		Status: p.status,
+		Detail: p.detail,
+		Code:   p.code,
+	}
+}
+
+// WriteError writes the problem+json response for the error to the ResponseWriter
+// with the statuses of the version of the API which serves the request.
+// The error is logged with the logger of the request, unregistered errors are returned as 500 Internal Server Error.
+// It is used by the handlers and the components outside of them, e.g. router and middlewares.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := newErrorResponse(err, restErrors(r))
+	logger := logging.FromContext(r.Context())
+
+	switch {
+	case resp.Code == internalProblem.code:
+		logger.Error().Err(err).Msg("Unhandled error")
+	case resp.Status >= http.StatusInternalServerError:
+		logger.Error().Err(err).Str("code", resp.Code).Msg("Request error")
+	default:
+		logger.Info().Err(err).Str("code", resp.Code).Msg("Request error")
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(resp.Status)
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logger.Error().Err(err).Msg("Encode error response error")
+	}
+}
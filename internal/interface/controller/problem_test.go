@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	dto "github.com/fedo3nik/cart-go-api/internal/interface/controller/dtohttp"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withRESTErrors reports the errors of the test routes with the statuses of the REST versions like the router does.
+func withRESTErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithRESTErrors(r.Context())))
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	tt := []struct {
+		name           string
+		err            error
+		rest           bool
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			name:           "Database error",
+			err:            errors.Wrap(e.ErrDB, "connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "database_error",
+			expectedDetail: "The database failed to process the request, retry it later.",
+		},
+		{
+			name:           "Cart not found",
+			err:            e.ErrInvalidCartID,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "cart_not_found",
+			expectedDetail: "The cart doesn't exist or was deleted.",
+		},
+		{
+			name:           "Cart not found in REST version",
+			err:            e.ErrInvalidCartID,
+			rest:           true,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "cart_not_found",
+			expectedDetail: "The cart doesn't exist or was deleted.",
+		},
+		{
+			name:           "Not overridden in REST version",
+			err:            e.ErrInvalidQuantity,
+			rest:           true,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_quantity",
+			expectedDetail: "The quantity of the item must be a positive number.",
+		},
+		{
+			name:           "Underlying error is not exposed",
+			err:            errors.Wrap(e.ErrInvalidCartID, `insert or update on table "cart_items" violates foreign key constraint`),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "cart_not_found",
+			expectedDetail: "The cart doesn't exist or was deleted.",
+		},
+		{
+			name:           "Invalid path parameter",
+			err:            errors.Wrap(e.ErrInvalidRequest, "invalid syntax"),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+			expectedDetail: "The body, the query or the path parameters of the request can't be parsed.",
+		},
+		{
+			name:           "Unregistered error",
+			err:            errors.New("unexpected"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+			expectedDetail: "",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/carts/1", nil)
+
+			if tc.rest {
+				r = r.WithContext(WithRESTErrors(r.Context()))
+			}
+
+			WriteError(w, r, tc.err)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+
+			var resp dto.ErrorResponse
+
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.Status)
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.Equal(t, problemTypeBase+tc.expectedCode, resp.Type)
+			assert.Equal(t, tc.expectedDetail, resp.Detail)
+		})
+	}
+}
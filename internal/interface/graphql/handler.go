@@ -0,0 +1,64 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/pkg/errors"
+)
+
+// maxParallelism limits the number of resolvers executed concurrently within a request.
+const maxParallelism = 20
+
+// request represents json body of the GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// HTTPHandler represents handler for the GraphQL endpoint.
+type HTTPHandler struct {
+	schema   *graphqlgo.Schema
+	resolver *Resolver
+}
+
+// NewHTTPHandler is a constructor for HTTPHandler struct.
+// It panics if the schema doesn't match the resolvers.
+func NewHTTPHandler(cartService service.Cart) *HTTPHandler {
+	resolver := NewResolver(cartService)
+
+	return &HTTPHandler{
+		schema:   graphqlgo.MustParseSchema(schema, resolver, graphqlgo.MaxParallelism(maxParallelism)),
+		resolver: resolver,
+	}
+}
+
+// ServeHTTP is a method to handle the GraphQL endpoint.
+// Every request gets its own cart loader so carts are batched and cached only within the request.
+func (hh HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		controller.WriteError(w, r, errors.Wrap(e.ErrInvalidRequest, err.Error()))
+
+		return
+	}
+
+	ctx := withCartLoader(r.Context(), newCartLoader(hh.resolver.fetchCarts))
+
+	resp := hh.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCartService is an in-memory implementation of the service.Cart interface which counts list calls.
+type stubCartService struct {
+	mu        sync.Mutex
+	carts     map[int]*model.Cart
+	listCalls int
+}
+
+func (s *stubCartService) CreateCart(_ context.Context, owner string) (*model.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart := model.Cart{ID: len(s.carts) + 1, Owner: owner, Items: []model.CartItem{}}
+	s.carts[cart.ID] = &cart
+
+	return &cart, nil
+}
+
+func (s *stubCartService) AddItem(_ context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	item := model.CartItem{ID: len(cart.Items) + 1, CartID: cartID, Product: product, Quantity: quantity}
+	cart.Items = append(cart.Items, item)
+
+	return &item, nil
+}
+
+func (s *stubCartService) RemoveItem(_ context.Context, _, _ int) error {
+	return e.ErrRemove
+}
+
+func (s *stubCartService) GetCart(_ context.Context, cartID int) (*model.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return cart, nil
+}
+
+func (s *stubCartService) ListCarts(_ context.Context, filter model.CartFilter) (*model.CartPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listCalls++
+
+	var page model.CartPage
+
+	for _, id := range filter.IDs {
+		if cart, ok := s.carts[id]; ok {
+			page.Carts = append(page.Carts, *cart)
+		}
+	}
+
+	return &page, nil
+}
+
+func (s *stubCartService) GetCartHistory(_ context.Context, _ int) ([]model.CartHistoryEvent, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func (s *stubCartService) GetCartAsOf(_ context.Context, _ int, _ time.Time) (*model.Cart, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func (s *stubCartService) GetCartLimits(_ context.Context, _ int) (*model.CartLimitsReport, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func TestHTTPHandler_ServeHTTP(t *testing.T) {
+	cs := &stubCartService{carts: map[int]*model.Cart{}}
+
+	server := httptest.NewServer(NewHTTPHandler(cs))
+	defer server.Close()
+
+	ex := httpexpect.New(t, server.URL)
+
+	ex.POST("/").WithJSON(request{Query: `mutation { createCart(owner: "bob") { id owner } }`}).
+		Expect().Status(http.StatusOK).
+		JSON().Path("$.data.createCart.owner").Equal("bob")
+
+	ex.POST("/").WithJSON(request{Query: `mutation { createCart { id } }`}).Expect().Status(http.StatusOK)
+
+	ex.POST("/").WithJSON(request{
+		Query:     `mutation($cart: Int!) { addItem(cartId: $cart, product: "Hat", quantity: 2) { id } }`,
+		Variables: map[string]interface{}{"cart": 1},
+	}).Expect().Status(http.StatusOK)
+
+	resp := ex.POST("/").WithJSON(request{
+		Query: `{
+			a: cart(id: 1) { id products totals { lines quantity } items { cart { id } } }
+			b: cart(id: 2) { id totals { lines } }
+			c: cart(id: 3) { id }
+		}`,
+	}).Expect().Status(http.StatusOK).JSON()
+
+	resp.Path("$.data.a.products").Equal([]string{"Hat"})
+	resp.Path("$.data.a.totals.quantity").Equal(2)
+	resp.Path("$.data.a.items[0].cart.id").Equal(1)
+	resp.Path("$.data.b.totals.lines").Equal(0)
+	resp.Path("$.data.c").Null()
+
+	assert.Equal(t, 1, cs.listCalls)
+
+	ex.POST("/").WithJSON(request{Query: `mutation { addItem(cartId: 9, product: "Hat", quantity: 1) { id } }`}).
+		Expect().Status(http.StatusOK).
+		JSON().Path("$.errors[0].extensions.code").Equal("cart_not_found")
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+)
+
+// batchWait is how long the loader collects keys before the batch is fetched.
+const batchWait = 2 * time.Millisecond
+
+// cartsFetcher fetches several carts with their items at once.
+// Carts which do not exist are absent in the result.
+type cartsFetcher func(ctx context.Context, cartIDs []int) (map[int]*model.Cart, error)
+
+// cartsBatch represents the cart IDs collected during the batch wait and the result of their fetch.
+type cartsBatch struct {
+	ids   []int
+	carts map[int]*model.Cart
+	err   error
+	done  chan struct{}
+}
+
+// cartLoader is a per-request dataloader that batches loading of the carts by ID.
+// Resolvers which run concurrently within batchWait share a single fetch.
+type cartLoader struct {
+	fetch cartsFetcher
+	wait  time.Duration
+
+	mu    sync.Mutex
+	cache map[int]*model.Cart
+	batch *cartsBatch
+}
+
+// newCartLoader is a constructor for cartLoader struct.
+func newCartLoader(fetch cartsFetcher) *cartLoader {
+	return &cartLoader{fetch: fetch, wait: batchWait, cache: map[int]*model.Cart{}}
+}
+
+// Prime stores already known cart so it isn't fetched again.
+func (l *cartLoader) Prime(cart *model.Cart) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cache[cart.ID] = cart
+}
+
+// Load returns the cart with its items, nil if the cart does not exist.
+// Returns an error if the batch containing the cart fails.
+func (l *cartLoader) Load(ctx context.Context, cartID int) (*model.Cart, error) {
+	l.mu.Lock()
+
+	if cart, ok := l.cache[cartID]; ok {
+		l.mu.Unlock()
+
+		return cart, nil
+	}
+
+	if l.batch == nil {
+		l.batch = &cartsBatch{done: make(chan struct{})}
+
+		go l.dispatch(ctx, l.batch)
+	}
+
+	b := l.batch
+	b.ids = append(b.ids, cartID)
+
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.carts[cartID], nil
+}
+
+// dispatch fetches the batch after the wait and caches the result.
+func (l *cartLoader) dispatch(ctx context.Context, b *cartsBatch) {
+	time.Sleep(l.wait)
+
+	l.mu.Lock()
+	l.batch = nil
+	ids := b.ids
+	l.mu.Unlock()
+
+	b.carts, b.err = l.fetch(ctx, ids)
+
+	if b.err == nil {
+		l.mu.Lock()
+
+		for _, id := range ids {
+			l.cache[id] = b.carts[id]
+		}
+
+		l.mu.Unlock()
+	}
+
+	close(b.done)
+}
+
+// loaderKey is a context key of the per-request cart loader.
+type loaderKey struct{}
+
+// withCartLoader returns a copy of the context with the cart loader.
+func withCartLoader(ctx context.Context, l *cartLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+// cartLoaderFrom returns the cart loader from the context.
+func cartLoaderFrom(ctx context.Context) *cartLoader {
+	l, _ := ctx.Value(loaderKey{}).(*cartLoader)
+
+	return l
+}
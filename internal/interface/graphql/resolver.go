@@ -0,0 +1,345 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+)
+
+// resolverError is an error of the resolver with the machine-readable code in the extensions.
+type resolverError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (re resolverError) Error() string {
+	return re.err.Error()
+}
+
+// Extensions returns the code of the error from the problems registry of the REST API.
+func (re resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": controller.ErrorCode(re.err)}
+}
+
+// wrapError wraps the service error to expose its code to the client.
+func wrapError(err error) error {
+	return resolverError{err: err}
+}
+
+// Resolver is the root resolver of the GraphQL schema implemented on top of the service layer.
+type Resolver struct {
+	cartService service.Cart
+}
+
+// NewResolver is a constructor for Resolver struct.
+func NewResolver(cartService service.Cart) *Resolver {
+	return &Resolver{cartService: cartService}
+}
+
+// fetchCarts loads the carts with the received IDs using a single ListCarts call per page.
+func (r *Resolver) fetchCarts(ctx context.Context, cartIDs []int) (map[int]*model.Cart, error) {
+	carts := map[int]*model.Cart{}
+
+	for start := 0; start < len(cartIDs); start += service.MaxListLimit {
+		end := start + service.MaxListLimit
+		if end > len(cartIDs) {
+			end = len(cartIDs)
+		}
+
+		page, err := r.cartService.ListCarts(ctx, model.CartFilter{IDs: cartIDs[start:end], Limit: end - start})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page.Carts {
+			carts[page.Carts[i].ID] = &page.Carts[i]
+		}
+	}
+
+	return carts, nil
+}
+
+// loadCart loads the cart with the per-request loader.
+func loadCart(ctx context.Context, cartID int) (*cartResolver, error) {
+	cart, err := cartLoaderFrom(ctx).Load(ctx, cartID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if cart == nil {
+		return nil, nil
+	}
+
+	return &cartResolver{cart: cart}, nil
+}
+
+// Cart resolves the cart query, null if the cart does not exist.
+func (r *Resolver) Cart(ctx context.Context, args struct{ ID int32 }) (*cartResolver, error) {
+	return loadCart(ctx, int(args.ID))
+}
+
+// cartsArgs represents arguments of the carts query.
+type cartsArgs struct {
+	Owner    *string
+	Product  *string
+	NonEmpty *bool
+	Sort     string
+	Desc     bool
+	First    *int32
+	After    *string
+}
+
+// Carts resolves the carts query.
+// Listed carts are primed into the loader so their items aren't fetched again.
+func (r *Resolver) Carts(ctx context.Context, args cartsArgs) (*cartConnectionResolver, error) {
+	filter := model.CartFilter{Sort: strings.ToLower(args.Sort), Desc: args.Desc}
+
+	if args.Owner != nil {
+		filter.Owner = *args.Owner
+	}
+
+	if args.Product != nil {
+		filter.Product = *args.Product
+	}
+
+	if args.NonEmpty != nil {
+		filter.NonEmpty = *args.NonEmpty
+	}
+
+	if args.First != nil {
+		filter.Limit = int(*args.First)
+	}
+
+	if args.After != nil {
+		filter.Cursor = *args.After
+	}
+
+	page, err := r.cartService.ListCarts(ctx, filter)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	loader := cartLoaderFrom(ctx)
+	conn := cartConnectionResolver{nextCursor: page.NextCursor}
+
+	for i := range page.Carts {
+		loader.Prime(&page.Carts[i])
+		conn.nodes = append(conn.nodes, &cartResolver{cart: &page.Carts[i]})
+	}
+
+	return &conn, nil
+}
+
+// CreateCart resolves the createCart mutation.
+// The mutation requires the cart:write scope since the /graphql route only requires cart:read.
+func (r *Resolver) CreateCart(ctx context.Context, args struct{ Owner *string }) (*cartResolver, error) {
+	if err := auth.Require(ctx, auth.ScopeCartWrite); err != nil {
+		return nil, wrapError(err)
+	}
+
+	var owner string
+
+	if args.Owner != nil {
+		owner = *args.Owner
+	}
+
+	cart, err := r.cartService.CreateCart(ctx, owner)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	cart.Items = []model.CartItem{}
+
+	return &cartResolver{cart: cart}, nil
+}
+
+// addItemArgs represents arguments of the addItem mutation.
+type addItemArgs struct {
+	CartID   int32
+	Product  string
+	Quantity int32
+}
+
+// AddItem resolves the addItem mutation, it requires the cart:write scope.
+func (r *Resolver) AddItem(ctx context.Context, args addItemArgs) (*itemResolver, error) {
+	if err := auth.Require(ctx, auth.ScopeCartWrite); err != nil {
+		return nil, wrapError(err)
+	}
+
+	item, err := r.cartService.AddItem(ctx, args.Product, int(args.Quantity), int(args.CartID))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &itemResolver{item: *item}, nil
+}
+
+// RemoveItem resolves the removeItem mutation, it requires the cart:write scope.
+func (r *Resolver) RemoveItem(ctx context.Context, args struct{ CartID, ItemID int32 }) (bool, error) {
+	if err := auth.Require(ctx, auth.ScopeCartWrite); err != nil {
+		return false, wrapError(err)
+	}
+
+	err := r.cartService.RemoveItem(ctx, int(args.CartID), int(args.ItemID))
+	if errors.Is(err, e.ErrRemove) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// cartResolver resolves fields of the Cart type.
+type cartResolver struct {
+	cart *model.Cart
+}
+
+// ID resolves the id field.
+func (cr *cartResolver) ID() int32 {
+	return int32(cr.cart.ID)
+}
+
+// Owner resolves the owner field, null for anonymous carts.
+func (cr *cartResolver) Owner() *string {
+	if cr.cart.Owner == "" {
+		return nil
+	}
+
+	return &cr.cart.Owner
+}
+
+// formatTime formats the time as RFC 3339, null for the zero time.
+func formatTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+
+	s := t.Format(time.RFC3339Nano)
+
+	return &s
+}
+
+// CreatedAt resolves the createdAt field.
+func (cr *cartResolver) CreatedAt() *string {
+	return formatTime(cr.cart.CreatedAt)
+}
+
+// UpdatedAt resolves the updatedAt field.
+func (cr *cartResolver) UpdatedAt() *string {
+	return formatTime(cr.cart.UpdatedAt)
+}
+
+// Items resolves the items field.
+func (cr *cartResolver) Items() []*itemResolver {
+	items := make([]*itemResolver, 0, len(cr.cart.Items))
+	for _, item := range cr.cart.Items {
+		items = append(items, &itemResolver{item: item})
+	}
+
+	return items
+}
+
+// Products resolves the distinct products of the cart in the order they were added.
+func (cr *cartResolver) Products() []string {
+	seen := map[string]struct{}{}
+	products := []string{}
+
+	for _, item := range cr.cart.Items {
+		if _, ok := seen[item.Product]; !ok {
+			seen[item.Product] = struct{}{}
+			products = append(products, item.Product)
+		}
+	}
+
+	return products
+}
+
+// Totals resolves the totals field.
+func (cr *cartResolver) Totals() *totalsResolver {
+	t := totalsResolver{lines: int32(len(cr.cart.Items))}
+	for _, item := range cr.cart.Items {
+		t.quantity += int32(item.Quantity)
+	}
+
+	return &t
+}
+
+// totalsResolver resolves fields of the CartTotals type.
+type totalsResolver struct {
+	lines    int32
+	quantity int32
+}
+
+// Lines resolves the lines field.
+func (tr *totalsResolver) Lines() int32 {
+	return tr.lines
+}
+
+// Quantity resolves the quantity field.
+func (tr *totalsResolver) Quantity() int32 {
+	return tr.quantity
+}
+
+// itemResolver resolves fields of the CartItem type.
+type itemResolver struct {
+	item model.CartItem
+}
+
+// ID resolves the id field.
+func (ir *itemResolver) ID() int32 {
+	return int32(ir.item.ID)
+}
+
+// CartID resolves the cartId field.
+func (ir *itemResolver) CartID() int32 {
+	return int32(ir.item.CartID)
+}
+
+// Product resolves the product field.
+func (ir *itemResolver) Product() string {
+	return ir.item.Product
+}
+
+// Quantity resolves the quantity field.
+func (ir *itemResolver) Quantity() int32 {
+	return int32(ir.item.Quantity)
+}
+
+// Cart resolves the cart in which the item was placed using the per-request loader.
+func (ir *itemResolver) Cart(ctx context.Context) (*cartResolver, error) {
+	return loadCart(ctx, ir.item.CartID)
+}
+
+// cartConnectionResolver resolves fields of the CartConnection type.
+type cartConnectionResolver struct {
+	nodes      []*cartResolver
+	nextCursor string
+}
+
+// Nodes resolves the nodes field.
+func (cc *cartConnectionResolver) Nodes() []*cartResolver {
+	if cc.nodes == nil {
+		return []*cartResolver{}
+	}
+
+	return cc.nodes
+}
+
+// NextCursor resolves the nextCursor field, null on the last page.
+func (cc *cartConnectionResolver) NextCursor() *string {
+	if cc.nextCursor == "" {
+		return nil
+	}
+
+	return &cc.nextCursor
+}
@@ -0,0 +1,69 @@
+package graphql
+
+// schema is the GraphQL schema of the cart API.
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	# Cart with the ID, null if the cart does not exist.
+	cart(id: Int!): Cart
+	# Page of the carts matching the filters.
+	carts(
+		owner: String
+		product: String
+		nonEmpty: Boolean
+		sort: CartSort = ID
+		desc: Boolean = false
+		first: Int
+		after: String
+	): CartConnection!
+}
+
+type Mutation {
+	createCart(owner: String): Cart!
+	addItem(cartId: Int!, product: String!, quantity: Int!): CartItem!
+	removeItem(cartId: Int!, itemId: Int!): Boolean!
+}
+
+enum CartSort {
+	ID
+	CREATED_AT
+	UPDATED_AT
+}
+
+type Cart {
+	id: Int!
+	owner: String
+	createdAt: String
+	updatedAt: String
+	items: [CartItem!]!
+	# Distinct products placed in the cart.
+	products: [String!]!
+	totals: CartTotals!
+}
+
+type CartItem {
+	id: Int!
+	cartId: Int!
+	product: String!
+	quantity: Int!
+	# Cart in which the item was placed.
+	cart: Cart
+}
+
+type CartTotals {
+	# Number of the items in the cart.
+	lines: Int!
+	# Sum of the quantities of all the items.
+	quantity: Int!
+}
+
+type CartConnection {
+	nodes: [Cart!]!
+	# Cursor of the next page, null on the last page.
+	nextCursor: String
+}
+`
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+)
+
+// bearerChallenge is a value of the WWW-Authenticate header of the 401 responses.
+const bearerChallenge = `Bearer realm="cart-api"`
+
+// Authenticator is the interface that authenticates the request by its credentials.
+// Authenticate returns nil principal and nil error if the request has no credentials of this kind.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*auth.Principal, error)
+}
+
+// BearerAuthenticator authenticates the requests with the JWT bearer token in the Authorization header.
+type BearerAuthenticator struct {
+	Verifier *auth.JWTVerifier
+}
+
+// NewBearerAuthenticator is a constructor for BearerAuthenticator struct.
+func NewBearerAuthenticator(verifier *auth.JWTVerifier) *BearerAuthenticator {
+	return &BearerAuthenticator{Verifier: verifier}
+}
+
+// Authenticate verifies the bearer token of the request.
+func (ba *BearerAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return nil, nil
+	}
+
+	return ba.Verifier.Verify(r.Context(), strings.TrimSpace(header[len("Bearer "):]))
+}
+
+// APIKeyHeader is a header of the API key of the server-to-server clients.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator authenticates the requests with the API key in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	Verifier auth.APIKeyVerifier
+}
+
+// NewAPIKeyAuthenticator is a constructor for APIKeyAuthenticator struct.
+func NewAPIKeyAuthenticator(verifier auth.APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{Verifier: verifier}
+}
+
+// Authenticate verifies the API key of the request.
+func (ka *APIKeyAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+
+	return ka.Verifier.VerifyAPIKey(r.Context(), key)
+}
+
+// ClientCertAuthenticator authenticates the requests with the client certificate verified during the TLS handshake.
+type ClientCertAuthenticator struct {
+	Scopes []string // scopes granted to the clients with a verified certificate
+}
+
+// NewClientCertAuthenticator is a constructor for ClientCertAuthenticator struct.
+func NewClientCertAuthenticator(scopes []string) *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{Scopes: scopes}
+}
+
+// Authenticate returns the principal of the verified client certificate of the request.
+func (ca *ClientCertAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	id := auth.VerifiedClientIdentity(r.TLS)
+	if id == nil {
+		return nil, nil
+	}
+
+	return auth.NewClientCertPrincipal(id, ca.Scopes), nil
+}
+
+// Authenticate enforces the authentication for the requests.
+// The first authenticator which finds the credentials in the request decides, so several
+// methods can coexist on the same router. Invalid credentials are rejected with 401 Unauthorized.
+// Requests without credentials proceed anonymously and are rejected by the routes which require scopes.
+// The failures are throttled by the limiter, it runs before RateLimit which identifies only the authenticated clients.
+// The subject of the principal becomes the actor of the audit log.
+func Authenticate(limiter *ratelimit.FailureLimiter, authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter := limiter.RetryAfter(requestIP(r)); retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				controller.WriteError(w, r, e.ErrRateLimited)
+
+				return
+			}
+
+			var principal *auth.Principal
+
+			for _, a := range authenticators {
+				p, err := a.Authenticate(r)
+				if err != nil {
+					if errors.Is(err, e.ErrUnauthenticated) {
+						limiter.Fail(r.Context(), requestIP(r))
+					}
+
+					writeAuthError(w, r, err)
+
+					return
+				}
+
+				if p != nil {
+					principal = p
+
+					break
+				}
+			}
+
+			ctx := auth.WithAuthentication(r.Context(), principal)
+			if principal != nil {
+				ctx = audit.WithActor(ctx, principal.Subject)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScopes rejects the requests of the callers which weren't granted all the scopes
+// with 401 Unauthorized for anonymous callers and 403 Forbidden otherwise.
+// It passes all the requests if the authentication isn't enforced.
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := auth.Require(r.Context(), scopes...)
+			if err != nil {
+				writeAuthError(w, r, err)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeAuthError writes the problem response for the authentication error.
+// 401 responses get the WWW-Authenticate challenge.
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, e.ErrUnauthenticated) {
+		w.Header().Set("WWW-Authenticate", bearerChallenge)
+	}
+
+	controller.WriteError(w, r, err)
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte("test-secret")
+	future := time.Now().Add(time.Hour).Unix()
+
+	sign := func(scope string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice", "exp": future, "scope": scope}).
+			SignedString(secret)
+		require.NoError(t, err)
+
+		return "Bearer " + token
+	}
+
+	tt := []struct {
+		name          string
+		enforced      bool
+		authorization string
+		expectedCode  int
+		expectedActor string
+	}{
+		{name: "Not enforced", expectedCode: http.StatusOK, expectedActor: audit.AnonymousActor},
+		{name: "Anonymous", enforced: true, expectedCode: http.StatusUnauthorized},
+		{name: "Invalid token", enforced: true, authorization: "Bearer invalid", expectedCode: http.StatusUnauthorized},
+		{name: "Missing scope", enforced: true, authorization: sign(auth.ScopeCartRead), expectedCode: http.StatusForbidden},
+		{
+			name:          "Granted scope",
+			enforced:      true,
+			authorization: sign(auth.ScopeCartWrite),
+			expectedCode:  http.StatusOK,
+			expectedActor: "alice",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var actor string
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = audit.MetadataFrom(r.Context()).ActorOrAnonymous()
+			})
+
+			handler = RequireScopes(auth.ScopeCartWrite)(handler)
+
+			if tc.enforced {
+				verifier := auth.JWTVerifier{Secret: secret}
+				handler = Authenticate(nil, NewBearerAuthenticator(&verifier))(handler)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedActor, actor)
+
+			if tc.expectedCode == http.StatusUnauthorized {
+				assert.Equal(t, bearerChallenge, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// stubAPIKeyVerifier accepts the single API key.
+type stubAPIKeyVerifier struct{}
+
+func (stubAPIKeyVerifier) VerifyAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	if key != "ck_valid" {
+		return nil, e.ErrUnauthenticated
+	}
+
+	return &auth.Principal{Subject: "api-key:1", Scopes: []string{auth.ScopeCartWrite}, Method: auth.MethodAPIKey}, nil
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier := auth.JWTVerifier{Secret: secret}
+	future := time.Now().Add(time.Hour).Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice", "exp": future, "scope": auth.ScopeCartWrite}).
+		SignedString(secret)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		apiKey        string
+		authorization string
+		expectedCode  int
+		expectedActor string
+	}{
+		{name: "Valid key", apiKey: "ck_valid", expectedCode: http.StatusOK, expectedActor: "api-key:1"},
+		{name: "Invalid key", apiKey: "ck_invalid", expectedCode: http.StatusUnauthorized},
+		{name: "Bearer token", authorization: "Bearer " + token, expectedCode: http.StatusOK, expectedActor: "alice"},
+		{name: "No credentials", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var actor string
+
+			handler := Authenticate(nil, NewBearerAuthenticator(&verifier), NewAPIKeyAuthenticator(stubAPIKeyVerifier{}))(
+				RequireScopes(auth.ScopeCartWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					actor = audit.MetadataFrom(r.Context()).ActorOrAnonymous()
+				})))
+
+			req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+			if tc.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedActor, actor)
+		})
+	}
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://example.org/billing")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		Raw:          []byte("der"),
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "billing"},
+		URIs:         []*url.URL{spiffe},
+	}
+
+	var (
+		principal *auth.Principal
+		identity  *auth.ClientIdentity
+		actor     string
+	)
+
+	handler := RequestMetadata(0)(Authenticate(nil, NewClientCertAuthenticator([]string{auth.ScopeCartRead}))(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			principal = auth.PrincipalFrom(r.Context())
+			identity = auth.ClientIdentityFrom(r.Context())
+			actor = audit.MetadataFrom(r.Context()).Actor
+		})))
+
+	req := httptest.NewRequest(http.MethodGet, "/carts", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, principal)
+	assert.Equal(t, "spiffe://example.org/billing", principal.Subject)
+	assert.Equal(t, auth.MethodClientCert, principal.Method)
+	assert.True(t, principal.HasScope(auth.ScopeCartRead))
+	assert.Equal(t, "spiffe://example.org/billing", actor)
+
+	require.NotNil(t, identity)
+	assert.Equal(t, "billing", identity.CommonName)
+	assert.Equal(t, "42", identity.SerialNumber)
+	assert.Len(t, identity.Fingerprint, 64)
+
+	// The unverified certificates don't identify the client.
+	req = httptest.NewRequest(http.MethodGet, "/carts", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Nil(t, principal)
+	assert.Nil(t, identity)
+}
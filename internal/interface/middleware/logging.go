@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLog logs every request with the method, the route template, the status, the latency,
+// the size of the response and the request ID. The server errors are logged at the error level
+// and the client errors at the warn level.
+// The logger with the request ID and the trace ID, if the request is traced,
+// is stored in the context for the service and storage layers.
+// It must be registered inside RequestMetadata to know the request ID,
+// the route template is matched with the router which may be nil.
+func AccessLog(logger zerolog.Logger, router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r = withRouteTemplate(router, r)
+
+			m := audit.MetadataFrom(r.Context())
+			lc := logger.With().Str("request_id", m.RequestID)
+
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				lc = lc.Str("trace_id", sc.TraceID().String())
+			}
+
+			l := lc.Logger()
+			rec := &responseRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r.WithContext(logging.WithLogger(r.Context(), l)))
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			lvl := zerolog.InfoLevel
+
+			switch {
+			case rec.status >= http.StatusInternalServerError:
+				lvl = zerolog.ErrorLevel
+			case rec.status >= http.StatusBadRequest:
+				lvl = zerolog.WarnLevel
+			}
+
+			l.WithLevel(lvl).
+				Str("method", r.Method).
+				Str("route", routeTemplate(r)).
+				Str("path", r.URL.Path).
+				Int("status", rec.status).
+				Int("bytes", rec.bytes).
+				Dur("latency", time.Since(start)).
+				Str("client_ip", m.ClientIP).
+				Msg("Request")
+		})
+	}
+}
+
+// routeKey is a context key of the path template of the route matching the request.
+type routeKey struct{}
+
+// withRouteTemplate returns a shallow copy of the request with the path template of the route matching it
+// stored in the context, so the route is matched once by the outermost of the Tracing, AccessLog and Metrics.
+// The request is returned as is if the template is already stored or the router is nil.
+func withRouteTemplate(router *mux.Router, r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeKey{}).(string); ok || router == nil {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, matchRouteTemplate(router, r)))
+}
+
+// routeTemplate returns the path template stored by withRouteTemplate or empty string.
+func routeTemplate(r *http.Request) string {
+	tpl, _ := r.Context().Value(routeKey{}).(string)
+
+	return tpl
+}
+
+// matchRouteTemplate returns the path template of the route matching the request or empty string.
+func matchRouteTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+
+	if !router.Match(r, &match) || match.Route == nil {
+		return ""
+	}
+
+	tpl, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return tpl
+}
+
+// responseRecorder records the status and the size of the response.
+// It supports flushing and hijacking for the server-sent events and the websockets.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WriteHeader is a method to record the status of the response.
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write is a method to record the size of the response.
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += n
+
+	return n, err
+}
+
+// Flush is a method to flush the response if the underlying writer supports it.
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack is a method to take over the connection if the underlying writer supports it.
+func (rr *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking isn't supported")
+	}
+
+	rr.status = http.StatusSwitchingProtocols
+
+	return h.Hijack()
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/metrics"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "info", logging.FormatJSON)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/carts/{cartID}", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info().Msg("Handled")
+		_, _ = w.Write([]byte("cart"))
+	})
+	r.HandleFunc("/carts/{cartID}/items", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+
+	handler := RequestMetadata(0)(AccessLog(logger, r)(r))
+
+	tt := []struct {
+		name          string
+		path          string
+		expectedRoute string
+		expectedCode  int
+		expectedLevel string
+		expectedBytes int
+	}{
+		{name: "Matched route", path: "/carts/1", expectedRoute: "/carts/{cartID}", expectedCode: 200, expectedLevel: "info", expectedBytes: 4},
+		{name: "Client error", path: "/carts/1/items", expectedRoute: "/carts/{cartID}/items", expectedCode: 422, expectedLevel: "warn"},
+		{name: "Unmatched route", path: "/unknown", expectedCode: 404, expectedLevel: "warn", expectedBytes: 19},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(RequestIDHeader, "req-42")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+
+			var entry map[string]interface{}
+
+			require.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry))
+			assert.Equal(t, "Request", entry["message"])
+			assert.Equal(t, tc.expectedLevel, entry["level"])
+			assert.Equal(t, "req-42", entry["request_id"])
+			assert.Equal(t, http.MethodGet, entry["method"])
+			assert.Equal(t, tc.expectedRoute, entry["route"])
+			assert.Equal(t, float64(tc.expectedCode), entry["status"])
+			assert.Equal(t, float64(tc.expectedBytes), entry["bytes"])
+			assert.Contains(t, entry, "latency")
+
+			for _, line := range lines[:len(lines)-1] {
+				assert.Contains(t, string(line), `"request_id":"req-42"`)
+			}
+		})
+	}
+}
+
+func TestWithRouteTemplate(t *testing.T) {
+	matches := 0
+
+	r := mux.NewRouter()
+	r.HandleFunc("/carts/{cartID}", func(w http.ResponseWriter, _ *http.Request) {}).
+		MatcherFunc(func(*http.Request, *mux.RouteMatch) bool {
+			matches++
+
+			return true
+		})
+
+	var route string
+
+	handler := Tracing(r)(AccessLog(zerolog.Nop(), r)(Metrics(metrics.New(prometheus.NewRegistry()), r)(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route = routeTemplate(req)
+
+			r.ServeHTTP(w, req)
+		}))))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/carts/1", nil))
+
+	assert.Equal(t, "/carts/{cartID}", route)
+	assert.Equal(t, 2, matches, "the middlewares must match the route once besides the router")
+}
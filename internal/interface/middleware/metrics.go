@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrics counts the requests and observes their latency by the method, the route template and the status.
+// The route template is matched with the router, so the unknown paths don't create new series.
+func Metrics(m *metrics.Metrics, router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			r = withRouteTemplate(router, r)
+
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			m.ObserveRequest(r.Method, routeTemplate(r), rec.status, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/metrics"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := metrics.New(reg)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/carts/{cartID}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("cart"))
+	})
+
+	handler := Metrics(m, r)(r)
+
+	for _, path := range []string{"/carts/1", "/carts/2", "/unknown/1", "/unknown/2"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP cart_http_requests_total Number of the HTTP requests by the method, the route template and the status.
+# TYPE cart_http_requests_total counter
+cart_http_requests_total{method="GET",route="/carts/{cartID}",status="200"} 2
+cart_http_requests_total{method="GET",route="unmatched",status="404"} 2
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "cart_http_requests_total")
+	require.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(reg, "cart_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimit limits the rate of the requests of every client to the matched route.
+// The route is limited by the limit of its name, e.g. createCart, or by the default limit,
+// routes without both aren't limited. All the versions of the route share the bucket of the client.
+// Clients are identified by the API key, the authenticated user or the client IP.
+// The responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers,
+// rejected requests get 429 Too Many Requests with the Retry-After header.
+// The requests are allowed if the store fails, so the limiter doesn't take the API down.
+// It must be registered with the Use method of the router to know the matched route.
+func RateLimit(store ratelimit.Store, limits map[string]ratelimit.Limit) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routeName(r)
+
+			limit, ok := limits[route]
+			if !ok {
+				limit, ok = limits[ratelimit.DefaultRoute]
+			}
+
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			res, err := store.Take(r.Context(), route+"|"+clientKey(r), limit)
+			if err != nil {
+				logging.FromContext(r.Context()).Error().Err(err).Str("route", route).Msg("Rate limit error")
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Period)))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				controller.WriteError(w, r, e.ErrRateLimited)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeName returns the name of the matched route without the version prefix.
+func routeName(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	name := route.GetName()
+
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// clientKey identifies the client of the request by the API key, the authenticated user or the client IP.
+func clientKey(r *http.Request) string {
+	if p := auth.PrincipalFrom(r.Context()); p != nil {
+		if p.Method == auth.MethodAPIKey {
+			return "key:" + p.Subject
+		}
+
+		return "user:" + p.Subject
+	}
+
+	return "ip:" + requestIP(r)
+}
+
+// requestIP returns the client IP of the request stored by RequestMetadata or the remote address.
+func requestIP(r *http.Request) string {
+	if ip := audit.MetadataFrom(r.Context()).ClientIP; ip != "" {
+		return ip
+	}
+
+	return clientIP(r, 0)
+}
+
+// ceilSeconds rounds the duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore is a rate limit store which is unavailable.
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store is down")
+}
+
+func newRateLimitTestRouter(store ratelimit.Store) *mux.Router {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+
+	r := mux.NewRouter()
+	r.Handle("/carts", ok).Methods(http.MethodPost).Name(":createCart")
+	r.Handle("/v2/carts", ok).Methods(http.MethodPost).Name("/v2:createCart")
+	r.Handle("/carts/{cartID}", ok).Methods(http.MethodGet).Name(":getCart")
+	r.Use(RateLimit(store, map[string]ratelimit.Limit{"createCart": {Requests: 2, Period: time.Minute}}))
+
+	return r
+}
+
+func TestRateLimit(t *testing.T) {
+	r := newRateLimitTestRouter(ratelimit.NewMemoryStore())
+
+	do := func(method, path, remoteAddr string, p *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+
+		if p != nil {
+			req = req.WithContext(auth.WithAuthentication(req.Context(), p))
+		}
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/carts", "192.0.2.1:1000", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+
+	rec = do(http.MethodPost, "/v2/carts", "192.0.2.1:1001", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"), "versions of the route share the bucket")
+
+	rec = do(http.MethodPost, "/carts", "192.0.2.1:1002", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	rec = do(http.MethodPost, "/carts", "192.0.2.1:1003", &auth.Principal{Subject: "alice"})
+	assert.Equal(t, http.StatusOK, rec.Code, "authenticated users have their own buckets")
+
+	rec = do(http.MethodPost, "/carts", "192.0.2.2:1000", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(http.MethodGet, "/carts/1", "192.0.2.1:1004", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"), "routes without the limit aren't limited")
+}
+
+func TestRateLimit_StoreError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+	rec := httptest.NewRecorder()
+
+	newRateLimitTestRouter(failingStore{}).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthenticate_FailureLimit(t *testing.T) {
+	limiter := ratelimit.NewFailureLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Period: time.Minute})
+	handler := Authenticate(limiter, NewAPIKeyAuthenticator(stubAPIKeyVerifier{}))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+
+	do := func(key, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(APIKeyHeader, key)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("ck_valid", "192.0.2.1:1000").Code)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, do("ck_guess", "192.0.2.1:1000").Code)
+	}
+
+	rec := do("ck_valid", "192.0.2.1:1000")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "the blocked client must not be authenticated")
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, do("ck_valid", "192.0.2.2:1000").Code, "other clients must not be blocked")
+}
+
+func TestAuthenticate_FailureLimitStoreError(t *testing.T) {
+	limiter := ratelimit.NewFailureLimiter(failingStore{}, ratelimit.Limit{Requests: 1, Period: time.Minute})
+	handler := Authenticate(limiter, NewAPIKeyAuthenticator(stubAPIKeyVerifier{}))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+		req.Header.Set(APIKeyHeader, "ck_guess")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+}
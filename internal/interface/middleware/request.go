@@ -0,0 +1,102 @@
+// Package middleware contains the HTTP middlewares shared by all the versions of the API.
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+)
+
+// RequestIDHeader is a header that carries the ID of the request.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limits the length of the request ID received from the client.
+const maxRequestIDLength = 128
+
+// RequestMetadata stores the origin of the request in the context for the audit log.
+// The request ID is taken from the X-Request-ID header or generated, and is echoed in the response.
+// The client IP is taken from the X-Forwarded-For header only if trustedProxies is positive,
+// otherwise the remote address of the connection is used.
+// The identity of the verified client certificate is stored for the authorization of the handlers.
+func RequestMetadata(trustedProxies int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m := audit.MetadataFrom(r.Context())
+			m.RequestID = requestID(r)
+			m.ClientIP = clientIP(r, trustedProxies)
+			m.UserAgent = r.UserAgent()
+
+			w.Header().Set(RequestIDHeader, m.RequestID)
+
+			ctx := audit.WithMetadata(r.Context(), m)
+			if id := auth.VerifiedClientIdentity(r.TLS); id != nil {
+				ctx = auth.WithClientIdentity(ctx, id)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestID returns the request ID received from the client if it is valid or generates a new one.
+func requestID(r *http.Request) string {
+	id := r.Header.Get(RequestIDHeader)
+	if id != "" && len(id) <= maxRequestIDLength && isPrintable(id) {
+		return id
+	}
+
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// isPrintable reports whether the string contains only printable ASCII characters.
+func isPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clientIP returns the IP address of the client.
+// Every proxy appends the address of its peer to X-Forwarded-For, so the entry trustedProxies hops
+// from the right was appended by the farthest trusted proxy, and the entries to the left of it may be forged by the client.
+// The leftmost entry is used if the header is shorter, because all of its entries were appended by the trusted proxies.
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var entries []string
+
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			entries = append(entries, strings.Split(header, ",")...)
+		}
+
+		if len(entries) > 0 {
+			i := len(entries) - trustedProxies
+			if i < 0 {
+				i = 0
+			}
+
+			return strings.TrimSpace(entries[i])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
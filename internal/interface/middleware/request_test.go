@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetadata(t *testing.T) {
+	tt := []struct {
+		name          string
+		proxies       int
+		requestID     string
+		forwarded     string
+		expectedIP    string
+		keepRequestID bool
+	}{
+		{name: "Remote address", expectedIP: "192.0.2.1"},
+		{name: "Client request ID", requestID: "req-42", expectedIP: "192.0.2.1", keepRequestID: true},
+		{name: "Invalid request ID", requestID: "bad id", expectedIP: "192.0.2.1"},
+		{name: "Too long request ID", requestID: strings.Repeat("a", 129), expectedIP: "192.0.2.1"},
+		{name: "Untrusted proxy", forwarded: "203.0.113.7", expectedIP: "192.0.2.1"},
+		{name: "Trusted proxy", proxies: 1, forwarded: "203.0.113.7", expectedIP: "203.0.113.7"},
+		{name: "Forged entry", proxies: 1, forwarded: "198.51.100.9, 203.0.113.7", expectedIP: "203.0.113.7"},
+		{name: "Two trusted proxies", proxies: 2, forwarded: "198.51.100.9, 203.0.113.7, 10.0.0.1", expectedIP: "203.0.113.7"},
+		{name: "Fewer entries than proxies", proxies: 3, forwarded: "203.0.113.7, 10.0.0.1", expectedIP: "203.0.113.7"},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var got audit.Metadata
+
+			handler := RequestMetadata(tc.proxies)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = audit.MetadataFrom(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/carts", nil)
+			req.RemoteAddr = "192.0.2.1:51234"
+			req.Header.Set("User-Agent", "test-agent")
+
+			if tc.requestID != "" {
+				req.Header.Set(RequestIDHeader, tc.requestID)
+			}
+
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedIP, got.ClientIP)
+			assert.Equal(t, "test-agent", got.UserAgent)
+			assert.Equal(t, audit.AnonymousActor, got.ActorOrAnonymous())
+			assert.NotEmpty(t, got.RequestID)
+			assert.Equal(t, got.RequestID, rec.Header().Get(RequestIDHeader))
+
+			if tc.keepRequestID {
+				assert.Equal(t, tc.requestID, got.RequestID)
+			} else {
+				assert.NotEqual(t, tc.requestID, got.RequestID)
+			}
+		})
+	}
+}
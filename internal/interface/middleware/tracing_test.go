@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fedo3nik/cart-go-api/internal/infrastructure/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	r := mux.NewRouter()
+	r.HandleFunc("/carts/{cartID}", func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	handler := Tracing(r)(r)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tt := []struct {
+		name           string
+		path           string
+		traceParent    string
+		expectedName   string
+		expectedStatus codes.Code
+	}{
+		{name: "New trace", path: "/carts/1", expectedName: "GET /carts/{cartID}", expectedStatus: codes.Error},
+		{
+			name:           "Continued trace",
+			path:           "/carts/1",
+			traceParent:    "00-" + traceID + "-00f067aa0ba902b7-01",
+			expectedName:   "GET /carts/{cartID}",
+			expectedStatus: codes.Error,
+		},
+		{name: "Unmatched route", path: "/unknown", expectedName: "GET", expectedStatus: codes.Unset},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			exporter.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.traceParent != "" {
+				req.Header.Set(tracing.TraceParentHeader, tc.traceParent)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.expectedName, spans[0].Name)
+			assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+			assert.Equal(t, tc.expectedStatus, spans[0].Status.Code)
+
+			if tc.traceParent != "" {
+				assert.Equal(t, traceID, spans[0].SpanContext.TraceID().String())
+				assert.True(t, spans[0].Parent.IsRemote())
+			} else {
+				assert.False(t, spans[0].Parent.IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+	"github.com/fedo3nik/cart-go-api/internal/interface/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// Route represents a single endpoint of the API.
+type Route struct {
+	Name    string       // Name of the route, unique within the version
+	Method  string       // HTTP method
+	Path    string       // Path template relative to the version prefix
+	Handler http.Handler // Handler of the endpoint
+	Scopes  []string     // Scopes required to call the endpoint when the authentication is enforced
+}
+
+// Version represents a set of routes served under the same path prefix.
+type Version struct {
+	Prefix     string    // Path prefix of the version, e.g. /v1. Empty prefix serves routes from the root
+	Routes     []Route   // Routes of the version
+	Deprecated bool      // Deprecated adds the Deprecation header to the responses
+	Sunset     time.Time // Sunset is a date after which the version will be removed, zero value omits the header
+	Successor  string    // Successor is a prefix of the version that replaces this one
+	RESTErrors bool      // RESTErrors reports the missing resources as 404 Not Found instead of 400 Bad Request
+}
+
+// New is a constructor for the mux.Router with all the versions registered.
+// Requests with a method not supported by the matched path get 405 Method Not Allowed
+// with the Allow header.
+func New(versions ...Version) *mux.Router {
+	r := mux.NewRouter()
+
+	for _, v := range versions {
+		sub := r.PathPrefix(v.Prefix).Subrouter()
+		if v.Prefix == "" {
+			sub = r.NewRoute().Subrouter()
+		}
+
+		if v.Deprecated {
+			sub.Use(deprecationMiddleware(v))
+		}
+
+		if v.RESTErrors {
+			sub.Use(restErrorsMiddleware)
+		}
+
+		for _, route := range v.Routes {
+			handler := route.Handler
+			if len(route.Scopes) > 0 {
+				handler = middleware.RequireScopes(route.Scopes...)(handler)
+			}
+
+			sub.Handle(route.Path, handler).Methods(route.Method).Name(v.Prefix + ":" + route.Name)
+		}
+	}
+
+	r.MethodNotAllowedHandler = methodNotAllowedHandler(r)
+
+	return r
+}
+
+// deprecationMiddleware adds the Deprecation, Sunset and Link headers to the responses of the version.
+func deprecationMiddleware(v Version) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+
+			if !v.Sunset.IsZero() {
+				w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if v.Successor != "" {
+				w.Header().Set("Link", "<"+v.Successor+">; rel=\"successor-version\"")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// restErrorsMiddleware reports the errors of the requests with the statuses of the REST versions of the API.
+func restErrorsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(controller.WithRESTErrors(r.Context())))
+	})
+}
+
+// methodNotAllowedHandler responds with 405 Method Not Allowed.
+// The Allow header lists the methods which are registered for the requested path.
+func methodNotAllowedHandler(r *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(r, req), ", "))
+
+		controller.WriteError(w, req, e.ErrMethodNotAllowed)
+	})
+}
+
+// allowedMethods returns sorted methods that match the path of the request.
+func allowedMethods(r *mux.Router, req *http.Request) []string {
+	candidates := map[string]struct{}{}
+
+	_ = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, m := range methods {
+			candidates[m] = struct{}{}
+		}
+
+		return nil
+	})
+
+	var allowed []string
+
+	for m := range candidates {
+		probe := req.Clone(req.Context())
+		probe.Method = m
+
+		var match mux.RouteMatch
+
+		if r.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, m)
+		}
+	}
+
+	sort.Strings(allowed)
+
+	return allowed
+}
@@ -0,0 +1,145 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func okHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestNew(t *testing.T) {
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New(
+		Version{
+			Prefix: "/v1",
+			Routes: []Route{
+				{Name: "getCart", Method: http.MethodGet, Path: "/carts/{cartID}", Handler: okHandler("v1")},
+				{Name: "removeCart", Method: http.MethodDelete, Path: "/carts/{cartID}", Handler: okHandler("v1")},
+			},
+			Deprecated: true,
+			Sunset:     sunset,
+			Successor:  "/v2",
+		},
+		Version{
+			Prefix: "/v2",
+			Routes: []Route{
+				{Name: "getCart", Method: http.MethodGet, Path: "/carts/{cartID}", Handler: okHandler("v2")},
+			},
+		},
+	)
+
+	tt := []struct {
+		name                string
+		method              string
+		path                string
+		expectedStatus      int
+		expectedBody        string
+		expectedAllow       string
+		expectedDeprecation string
+		expectedSunset      string
+	}{
+		{
+			name:                "Deprecated version",
+			method:              http.MethodGet,
+			path:                "/v1/carts/1",
+			expectedStatus:      http.StatusOK,
+			expectedBody:        "v1",
+			expectedDeprecation: "true",
+			expectedSunset:      "Fri, 01 Jan 2027 00:00:00 GMT",
+		},
+		{
+			name:           "Current version",
+			method:         http.MethodGet,
+			path:           "/v2/carts/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "v2",
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			path:           "/v1/carts/1",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "DELETE, GET",
+		},
+		{
+			name:           "Method not allowed in the version",
+			method:         http.MethodDelete,
+			path:           "/v2/carts/1",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET",
+		},
+		{
+			name:           "Not found",
+			method:         http.MethodGet,
+			path:           "/v3/carts/1",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedAllow, w.Header().Get("Allow"))
+			assert.Equal(t, tc.expectedDeprecation, w.Header().Get("Deprecation"))
+			assert.Equal(t, tc.expectedSunset, w.Header().Get("Sunset"))
+
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNew_RESTErrors(t *testing.T) {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.WriteError(w, r, e.ErrInvalidCartID)
+	})
+
+	r := New(
+		Version{Prefix: "/v1", Routes: []Route{{Name: "getCart", Method: http.MethodGet, Path: "/carts/{cartID}", Handler: notFound}}},
+		Version{
+			Prefix:     "/v2",
+			Routes:     []Route{{Name: "getCart", Method: http.MethodGet, Path: "/carts/{cartID}", Handler: notFound}},
+			RESTErrors: true,
+		},
+	)
+
+	tt := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "Legacy version", path: "/v1/carts/1", expectedStatus: http.StatusBadRequest},
+		{name: "REST version", path: "/v2/carts/1", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/interface/controller"
+	"github.com/fedo3nik/cart-go-api/internal/interface/graphql"
+)
+
+// V1Routes returns the legacy handler set of the cart API.
+func V1Routes(cartService service.Cart, events pubsub.Subscriber) []Route {
+	return append([]Route{
+		{
+			Name:    "createCart",
+			Method:  http.MethodPost,
+			Path:    "/carts",
+			Handler: controller.NewHTTPCreateCartHandler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "addItem",
+			Method:  http.MethodPost,
+			Path:    "/carts/{cartID}/items",
+			Handler: controller.NewHTTPAddItemHandler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "removeItem",
+			Method:  http.MethodDelete,
+			Path:    "/carts/{cartID}/items/{itemID}",
+			Handler: controller.NewHTTPRemoveItemHandler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "getCart",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}",
+			Handler: controller.NewHTTPGetCartHandler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+	}, sharedCartRoutes(cartService, events)...)
+}
+
+// V2Routes returns the handler set of the cart API which follows REST semantics.
+func V2Routes(cartService service.Cart, events pubsub.Subscriber) []Route {
+	return append([]Route{
+		{
+			Name:    "createCart",
+			Method:  http.MethodPost,
+			Path:    "/carts",
+			Handler: controller.NewHTTPCreateCartV2Handler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "addItem",
+			Method:  http.MethodPost,
+			Path:    "/carts/{cartID}/items",
+			Handler: controller.NewHTTPAddItemV2Handler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "removeItem",
+			Method:  http.MethodDelete,
+			Path:    "/carts/{cartID}/items/{itemID}",
+			Handler: controller.NewHTTPRemoveItemV2Handler(cartService),
+			Scopes:  []string{auth.ScopeCartWrite},
+		},
+		{
+			Name:    "getCart",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}",
+			Handler: controller.NewHTTPGetCartV2Handler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+	}, sharedCartRoutes(cartService, events)...)
+}
+
+// sharedCartRoutes returns the handlers of the cart API which are the same in all the versions.
+func sharedCartRoutes(cartService service.Cart, events pubsub.Subscriber) []Route {
+	return []Route{
+		{
+			Name:    "listCarts",
+			Method:  http.MethodGet,
+			Path:    "/carts",
+			Handler: controller.NewHTTPListCartsHandler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+		{
+			Name:    "cartHistory",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}/history",
+			Handler: controller.NewHTTPCartHistoryHandler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+		{
+			Name:    "cartLimits",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}/limits",
+			Handler: controller.NewHTTPCartLimitsHandler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+		{
+			Name:    "cartEvents",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}/events",
+			Handler: controller.NewHTTPCartEventsHandler(cartService, events),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+		{
+			Name:    "cartEventsWS",
+			Method:  http.MethodGet,
+			Path:    "/carts/{cartID}/events/ws",
+			Handler: controller.NewHTTPCartEventsWSHandler(cartService, events),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+	}
+}
+
+// GraphQLRoutes returns the GraphQL endpoint of the cart API.
+func GraphQLRoutes(cartService service.Cart) []Route {
+	return []Route{
+		{
+			Name:    "graphql",
+			Method:  http.MethodPost,
+			Path:    "/graphql",
+			Handler: graphql.NewHTTPHandler(cartService),
+			Scopes:  []string{auth.ScopeCartRead},
+		},
+	}
+}
+
+// WebhookRoutes returns the management endpoints of the webhook subscriptions.
+func WebhookRoutes(webhookService service.Webhooks) []Route {
+	return []Route{
+		{
+			Name:    "createWebhook",
+			Method:  http.MethodPost,
+			Path:    "/webhooks",
+			Handler: controller.NewHTTPCreateWebhookHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+		{
+			Name:    "listWebhooks",
+			Method:  http.MethodGet,
+			Path:    "/webhooks",
+			Handler: controller.NewHTTPListWebhooksHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+		{
+			Name:    "getWebhook",
+			Method:  http.MethodGet,
+			Path:    "/webhooks/{webhookID}",
+			Handler: controller.NewHTTPGetWebhookHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+		{
+			Name:    "updateWebhook",
+			Method:  http.MethodPut,
+			Path:    "/webhooks/{webhookID}",
+			Handler: controller.NewHTTPUpdateWebhookHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+		{
+			Name:    "deleteWebhook",
+			Method:  http.MethodDelete,
+			Path:    "/webhooks/{webhookID}",
+			Handler: controller.NewHTTPDeleteWebhookHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+		{
+			Name:    "listDeliveries",
+			Method:  http.MethodGet,
+			Path:    "/webhooks/{webhookID}/deliveries",
+			Handler: controller.NewHTTPListDeliveriesHandler(webhookService),
+			Scopes:  []string{auth.ScopeWebhooksManage},
+		},
+	}
+}
+
+// AdminRoutes returns the administrative endpoints.
+func AdminRoutes(auditService service.Audit, apiKeyService service.APIKeys) []Route {
+	return []Route{
+		{
+			Name:    "listAudit",
+			Method:  http.MethodGet,
+			Path:    "/audit",
+			Handler: controller.NewHTTPListAuditHandler(auditService),
+			Scopes:  []string{auth.ScopeAdmin},
+		},
+		{
+			Name:    "createAPIKey",
+			Method:  http.MethodPost,
+			Path:    "/api-keys",
+			Handler: controller.NewHTTPCreateAPIKeyHandler(apiKeyService),
+			Scopes:  []string{auth.ScopeAdmin},
+		},
+		{
+			Name:    "listAPIKeys",
+			Method:  http.MethodGet,
+			Path:    "/api-keys",
+			Handler: controller.NewHTTPListAPIKeysHandler(apiKeyService),
+			Scopes:  []string{auth.ScopeAdmin},
+		},
+		{
+			Name:    "rotateAPIKey",
+			Method:  http.MethodPost,
+			Path:    "/api-keys/{keyID}/rotate",
+			Handler: controller.NewHTTPRotateAPIKeyHandler(apiKeyService),
+			Scopes:  []string{auth.ScopeAdmin},
+		},
+		{
+			Name:    "revokeAPIKey",
+			Method:  http.MethodDelete,
+			Path:    "/api-keys/{keyID}",
+			Handler: controller.NewHTTPRevokeAPIKeyHandler(apiKeyService),
+			Scopes:  []string{auth.ScopeAdmin},
+		},
+	}
+}
@@ -0,0 +1,178 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// methodScopes is a registry of the scopes required by the methods of the CartService.
+var methodScopes = map[string][]string{
+	"/cart.v1.CartService/CreateCart": {auth.ScopeCartWrite},
+	"/cart.v1.CartService/AddItem":    {auth.ScopeCartWrite},
+	"/cart.v1.CartService/RemoveItem": {auth.ScopeCartWrite},
+	"/cart.v1.CartService/GetCart":    {auth.ScopeCartRead},
+	"/cart.v1.CartService/WatchCart":  {auth.ScopeCartRead},
+}
+
+// Authenticator is the interface that authenticates the call by the credentials in its metadata.
+// Authenticate returns nil principal and nil error if the call has no credentials of this kind.
+type Authenticator interface {
+	Authenticate(ctx context.Context, md metadata.MD) (*auth.Principal, error)
+}
+
+// BearerAuthenticator authenticates the calls with the JWT bearer token in the authorization metadata.
+type BearerAuthenticator struct {
+	Verifier *auth.JWTVerifier
+}
+
+// NewBearerAuthenticator is a constructor for BearerAuthenticator struct.
+func NewBearerAuthenticator(verifier *auth.JWTVerifier) *BearerAuthenticator {
+	return &BearerAuthenticator{Verifier: verifier}
+}
+
+// Authenticate verifies the bearer token of the call.
+func (ba *BearerAuthenticator) Authenticate(ctx context.Context, md metadata.MD) (*auth.Principal, error) {
+	v := md.Get("authorization")
+	if len(v) == 0 || len(v[0]) < len("Bearer ") || !strings.EqualFold(v[0][:len("Bearer ")], "Bearer ") {
+		return nil, nil
+	}
+
+	return ba.Verifier.Verify(ctx, strings.TrimSpace(v[0][len("Bearer "):]))
+}
+
+// APIKeyAuthenticator authenticates the calls with the API key in the x-api-key metadata.
+type APIKeyAuthenticator struct {
+	Verifier auth.APIKeyVerifier
+}
+
+// NewAPIKeyAuthenticator is a constructor for APIKeyAuthenticator struct.
+func NewAPIKeyAuthenticator(verifier auth.APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{Verifier: verifier}
+}
+
+// Authenticate verifies the API key of the call.
+func (ka *APIKeyAuthenticator) Authenticate(ctx context.Context, md metadata.MD) (*auth.Principal, error) {
+	v := md.Get("x-api-key")
+	if len(v) == 0 || v[0] == "" {
+		return nil, nil
+	}
+
+	return ka.Verifier.VerifyAPIKey(ctx, v[0])
+}
+
+// ClientCertAuthenticator authenticates the calls with the client certificate verified during the TLS handshake.
+type ClientCertAuthenticator struct {
+	Scopes []string // scopes granted to the clients with a verified certificate
+}
+
+// NewClientCertAuthenticator is a constructor for ClientCertAuthenticator struct.
+func NewClientCertAuthenticator(scopes []string) *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{Scopes: scopes}
+}
+
+// Authenticate returns the principal of the verified client certificate of the call.
+func (ca *ClientCertAuthenticator) Authenticate(ctx context.Context, _ metadata.MD) (*auth.Principal, error) {
+	id := peerClientIdentity(ctx)
+	if id == nil {
+		return nil, nil
+	}
+
+	return auth.NewClientCertPrincipal(id, ca.Scopes), nil
+}
+
+// peerClientIdentity returns the identity of the verified client certificate of the call,
+// nil if the connection isn't TLS or the client presented no certificate.
+func peerClientIdentity(ctx context.Context) *auth.ClientIdentity {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+
+	return auth.VerifiedClientIdentity(&info.State)
+}
+
+// authenticate authenticates the call with the first authenticator which finds the credentials
+// and checks the scopes required by the method.
+// The failures are throttled by the limiter keyed by the peer address, the blocked peers get ResourceExhausted.
+// Returns the context carrying the principal, the subject of the principal becomes the actor of the audit log.
+func authenticate(ctx context.Context, method string, limiter *ratelimit.FailureLimiter,
+	authenticators []Authenticator) (context.Context, error) {
+	var principal *auth.Principal
+
+	ip := audit.MetadataFrom(ctx).ClientIP
+	if limiter.RetryAfter(ip) > 0 {
+		return nil, toStatus(e.ErrRateLimited)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, a := range authenticators {
+		p, err := a.Authenticate(ctx, md)
+		if err != nil {
+			if errors.Is(err, e.ErrUnauthenticated) {
+				limiter.Fail(ctx, ip)
+			}
+
+			return nil, toStatus(err)
+		}
+
+		if p != nil {
+			principal = p
+
+			break
+		}
+	}
+
+	ctx = auth.WithAuthentication(ctx, principal)
+	if principal != nil {
+		ctx = audit.WithActor(ctx, principal.Subject)
+	}
+
+	err := auth.Require(ctx, methodScopes[method]...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return ctx, nil
+}
+
+// authUnaryInterceptor enforces the authentication for the unary calls.
+func authUnaryInterceptor(limiter *ratelimit.FailureLimiter, authenticators []Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, info.FullMethod, limiter, authenticators)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor enforces the authentication for the streaming calls.
+func authStreamInterceptor(limiter *ratelimit.FailureLimiter, authenticators []Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod, limiter, authenticators)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,259 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/audit"
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/logging"
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+	"github.com/fedo3nik/cart-go-api/internal/application/service"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/rpc/cartpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// statusCode describes which gRPC code is returned for the domain error.
+type statusCode struct {
+	err  error
+	code codes.Code
+}
+
+// statusCodes is a registry that maps domain errors to gRPC status codes.
+// Errors are matched with errors.Is in the order of declaration.
+var statusCodes = []statusCode{
+	{err: e.ErrDB, code: codes.Unavailable},
+	{err: e.ErrInvalidCartID, code: codes.NotFound},
+	{err: e.ErrInvalidQuantity, code: codes.InvalidArgument},
+	{err: e.ErrInvalidProduct, code: codes.InvalidArgument},
+	{err: e.ErrRemove, code: codes.NotFound},
+	{err: e.ErrInvalidRequest, code: codes.InvalidArgument},
+	{err: e.ErrInvalidFilter, code: codes.InvalidArgument},
+	{err: e.ErrUnauthenticated, code: codes.Unauthenticated},
+	{err: e.ErrForbidden, code: codes.PermissionDenied},
+	{err: e.ErrAccessDenied, code: codes.PermissionDenied},
+	{err: e.ErrRateLimited, code: codes.ResourceExhausted},
+	{err: e.ErrProductNameTooLong, code: codes.InvalidArgument},
+	{err: e.ErrLineQuantityExceeded, code: codes.InvalidArgument},
+	{err: e.ErrTooManyLines, code: codes.FailedPrecondition},
+	{err: e.ErrCartUnitsExceeded, code: codes.FailedPrecondition},
+	{err: e.ErrProductLimitExceeded, code: codes.FailedPrecondition},
+}
+
+// toStatus converts the domain error to the gRPC status error.
+// Unregistered errors are returned as Internal without the details.
+func toStatus(err error) error {
+	for _, sc := range statusCodes {
+		if errors.Is(err, sc.err) {
+			return status.Error(sc.code, sc.err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// CartServer implements cartpb.CartServiceServer on top of the service layer.
+type CartServer struct {
+	cartpb.UnimplementedCartServiceServer
+
+	cartService service.Cart
+	events      pubsub.Subscriber
+}
+
+// NewCartServer is a constructor for CartServer struct.
+func NewCartServer(cartService service.Cart, events pubsub.Subscriber) *CartServer {
+	return &CartServer{cartService: cartService, events: events}
+}
+
+// NewServer is a constructor for the gRPC server with the CartService registered.
+// The calls are traced and the authentication is enforced if at least one authenticator is passed.
+func NewServer(cartService service.Cart, events pubsub.Subscriber, authenticators ...Authenticator) *grpc.Server {
+	return NewTLSServer(cartService, events, nil, nil, authenticators...)
+}
+
+// NewTLSServer is a constructor for the gRPC server with the CartService registered
+// which serves TLS with the config, plaintext if the config is nil.
+// The calls are traced and the authentication is enforced if at least one authenticator is passed.
+// The failed authentications are throttled by the limiter, nil limiter doesn't throttle them.
+func NewTLSServer(cartService service.Cart, events pubsub.Subscriber, tlsConfig *tls.Config, limiter *ratelimit.FailureLimiter,
+	authenticators ...Authenticator) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{tracingInterceptor, requestMetadataInterceptor}
+	stream := []grpc.StreamServerInterceptor{tracingStreamInterceptor, requestMetadataStreamInterceptor}
+
+	if len(authenticators) > 0 {
+		unary = append(unary, authUnaryInterceptor(limiter, authenticators))
+		stream = append(stream, authStreamInterceptor(limiter, authenticators))
+	}
+
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(opts...)
+	cartpb.RegisterCartServiceServer(s, NewCartServer(cartService, events))
+
+	return s
+}
+
+// requestMetadataInterceptor stores the origin of the unary call in the context, see withRequestMetadata.
+func requestMetadataInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withRequestMetadata(ctx), req)
+}
+
+// requestMetadataStreamInterceptor stores the origin of the streaming call in the context, see withRequestMetadata.
+func requestMetadataStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestMetadata(ss.Context())})
+}
+
+// withRequestMetadata stores the origin of the call in the context for the audit log
+// and the logger with the request ID for the service layer.
+// The identity of the verified client certificate is stored for the authorization of the handlers.
+// The request ID and the user agent are taken from the x-request-id and user-agent metadata.
+func withRequestMetadata(ctx context.Context) context.Context {
+	m := audit.MetadataFrom(ctx)
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			m.RequestID = v[0]
+		}
+
+		if v := md.Get("user-agent"); len(v) > 0 {
+			m.UserAgent = v[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		m.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(m.ClientIP); err == nil {
+			m.ClientIP = host
+		}
+	}
+
+	if id := peerClientIdentity(ctx); id != nil {
+		ctx = auth.WithClientIdentity(ctx, id)
+	}
+
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With().Str("request_id", m.RequestID).Logger())
+
+	return audit.WithMetadata(ctx, m)
+}
+
+// wrappedStream is a server stream with the context replaced by the interceptor.
+type wrappedStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// toCart converts the cart model to the protobuf message.
+func toCart(cart *model.Cart) *cartpb.Cart {
+	pb := cartpb.Cart{Id: int64(cart.ID), Owner: cart.Owner, Items: make([]*cartpb.CartItem, 0, len(cart.Items))}
+
+	for i := range cart.Items {
+		pb.Items = append(pb.Items, toCartItem(&cart.Items[i]))
+	}
+
+	return &pb
+}
+
+// toCartItem converts the item model to the protobuf message.
+func toCartItem(item *model.CartItem) *cartpb.CartItem {
+	return &cartpb.CartItem{
+		Id:       int64(item.ID),
+		CartId:   int64(item.CartID),
+		Product:  item.Product,
+		Quantity: int64(item.Quantity),
+	}
+}
+
+// CreateCart creates a new cart using the method CreateCart from the service layer.
+func (cs *CartServer) CreateCart(ctx context.Context, req *cartpb.CreateCartRequest) (*cartpb.Cart, error) {
+	cart, err := cs.cartService.CreateCart(ctx, req.GetOwner())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toCart(cart), nil
+}
+
+// AddItem adds a new item to the cart using the method AddItem from the service layer.
+func (cs *CartServer) AddItem(ctx context.Context, req *cartpb.AddItemRequest) (*cartpb.CartItem, error) {
+	item, err := cs.cartService.AddItem(ctx, req.GetProduct(), int(req.GetQuantity()), int(req.GetCartId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toCartItem(item), nil
+}
+
+// RemoveItem removes the item from the cart using the method RemoveItem from the service layer.
+func (cs *CartServer) RemoveItem(ctx context.Context, req *cartpb.RemoveItemRequest) (*cartpb.RemoveItemResponse, error) {
+	err := cs.cartService.RemoveItem(ctx, int(req.GetCartId()), int(req.GetItemId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &cartpb.RemoveItemResponse{}, nil
+}
+
+// GetCart gets the cart using the method GetCart from the service layer.
+func (cs *CartServer) GetCart(ctx context.Context, req *cartpb.GetCartRequest) (*cartpb.Cart, error) {
+	cart, err := cs.cartService.GetCart(ctx, int(req.GetCartId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toCart(cart), nil
+}
+
+// WatchCart sends the current state of the cart and then the state after every change until the client cancels the stream.
+// The changes are received from the events hub, so the cart is read only when it changes.
+func (cs *CartServer) WatchCart(req *cartpb.WatchCartRequest, stream cartpb.CartService_WatchCartServer) error {
+	ctx := stream.Context()
+	cartID := int(req.GetCartId())
+
+	// The subscription starts before the first read, so the changes made in between aren't missed.
+	events, cancel := cs.events.Subscribe(cartID)
+	defer cancel()
+
+	for {
+		cart, err := cs.cartService.GetCart(ctx, cartID)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		err = stream.Send(toCart(cart))
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-events:
+			if !ok {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fedo3nik/cart-go-api/internal/application/auth"
+	"github.com/fedo3nik/cart-go-api/internal/application/pubsub"
+	"github.com/fedo3nik/cart-go-api/internal/application/ratelimit"
+	"github.com/fedo3nik/cart-go-api/internal/domain/model"
+	e "github.com/fedo3nik/cart-go-api/internal/errors"
+	"github.com/fedo3nik/cart-go-api/internal/interface/rpc/cartpb"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stubCartService is an in-memory implementation of the service.Cart interface for server tests.
+type stubCartService struct {
+	carts map[int]*model.Cart
+}
+
+func (s *stubCartService) CreateCart(_ context.Context, owner string) (*model.Cart, error) {
+	cart := model.Cart{ID: len(s.carts) + 1, Owner: owner, Items: []model.CartItem{}}
+	s.carts[cart.ID] = &cart
+
+	return &cart, nil
+}
+
+func (s *stubCartService) AddItem(_ context.Context, product string, quantity, cartID int) (*model.CartItem, error) {
+	if product == "" {
+		return nil, e.ErrInvalidProduct
+	}
+
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	item := model.CartItem{ID: len(cart.Items) + 1, CartID: cartID, Product: product, Quantity: quantity}
+	cart.Items = append(cart.Items, item)
+
+	return &item, nil
+}
+
+func (s *stubCartService) RemoveItem(_ context.Context, _, _ int) error {
+	return e.ErrRemove
+}
+
+func (s *stubCartService) GetCart(_ context.Context, cartID int) (*model.Cart, error) {
+	cart, ok := s.carts[cartID]
+	if !ok {
+		return nil, e.ErrInvalidCartID
+	}
+
+	return cart, nil
+}
+
+func (s *stubCartService) ListCarts(_ context.Context, _ model.CartFilter) (*model.CartPage, error) {
+	return &model.CartPage{}, nil
+}
+
+func (s *stubCartService) GetCartHistory(_ context.Context, _ int) ([]model.CartHistoryEvent, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func (s *stubCartService) GetCartAsOf(_ context.Context, _ int, _ time.Time) (*model.Cart, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func (s *stubCartService) GetCartLimits(_ context.Context, _ int) (*model.CartLimitsReport, error) {
+	return nil, e.ErrInvalidCartID
+}
+
+func TestCartServer(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	hub := pubsub.NewHub()
+
+	server := NewServer(&stubCartService{carts: map[int]*model.Cart{}}, hub)
+	defer server.Stop()
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	client := cartpb.NewCartServiceClient(conn)
+	ctx := context.Background()
+
+	cart, err := client.CreateCart(ctx, &cartpb.CreateCartRequest{Owner: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cart.GetId())
+
+	item, err := client.AddItem(ctx, &cartpb.AddItemRequest{CartId: 1, Product: "Hat", Quantity: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Hat", item.GetProduct())
+
+	tt := []struct {
+		name         string
+		call         func() error
+		expectedCode codes.Code
+	}{
+		{
+			name: "Missing cart",
+			call: func() error {
+				_, err := client.GetCart(ctx, &cartpb.GetCartRequest{CartId: 5})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "Blank product",
+			call: func() error {
+				_, err := client.AddItem(ctx, &cartpb.AddItemRequest{CartId: 1, Quantity: 1})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Missing item",
+			call: func() error {
+				_, err := client.RemoveItem(ctx, &cartpb.RemoveItemRequest{CartId: 1, ItemId: 7})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, status.Code(tc.call()))
+		})
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.WatchCart(watchCtx, &cartpb.WatchCartRequest{CartId: 1})
+	require.NoError(t, err)
+
+	snapshot, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Len(t, snapshot.GetItems(), 1)
+
+	added, err := client.AddItem(ctx, &cartpb.AddItemRequest{CartId: 1, Product: "Shoes", Quantity: 1})
+	require.NoError(t, err)
+
+	err = hub.Publish(ctx, &model.CartEvent{Type: model.CartEventItemAdded, CartID: 1,
+		Item: model.CartItem{ID: int(added.GetId()), CartID: 1, Product: "Shoes", Quantity: 1}})
+	require.NoError(t, err)
+
+	changed, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Len(t, changed.GetItems(), 2)
+}
+
+func TestToStatus(t *testing.T) {
+	tt := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{name: "Rate limited", err: e.ErrRateLimited, expectedCode: codes.ResourceExhausted},
+		{name: "Invalid filter", err: e.ErrInvalidFilter, expectedCode: codes.InvalidArgument},
+		{name: "Unregistered", err: errors.New("unexpected"), expectedCode: codes.Internal},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, status.Code(toStatus(tc.err)))
+		})
+	}
+}
+
+func TestCartServer_Authentication(t *testing.T) {
+	secret := []byte("test-secret")
+	lis := bufconn.Listen(1024 * 1024)
+
+	server := NewServer(&stubCartService{carts: map[int]*model.Cart{}}, pubsub.NewHub(),
+		NewBearerAuthenticator(&auth.JWTVerifier{Secret: secret}))
+	defer server.Stop()
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	client := cartpb.NewCartServiceClient(conn)
+
+	future := time.Now().Add(time.Hour).Unix()
+
+	withToken := func(scope string) context.Context {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice", "exp": future, "scope": scope}).
+			SignedString(secret)
+		require.NoError(t, err)
+
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	tt := []struct {
+		name         string
+		ctx          context.Context
+		expectedCode codes.Code
+	}{
+		{name: "Anonymous", ctx: context.Background(), expectedCode: codes.Unauthenticated},
+		{
+			name:         "Invalid token",
+			ctx:          metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid"),
+			expectedCode: codes.Unauthenticated,
+		},
+		{name: "Missing scope", ctx: withToken(auth.ScopeCartRead), expectedCode: codes.PermissionDenied},
+		{name: "Granted scope", ctx: withToken(auth.ScopeCartWrite), expectedCode: codes.OK},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateCart(tc.ctx, &cartpb.CreateCartRequest{Owner: "bob"})
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+
+	stream, err := client.WatchCart(context.Background(), &cartpb.WatchCartRequest{CartId: 1})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestCartServer_AuthenticationFailureLimit(t *testing.T) {
+	secret := []byte("test-secret")
+	lis := bufconn.Listen(1024 * 1024)
+
+	limiter := ratelimit.NewFailureLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 1, Period: time.Minute})
+
+	server := NewTLSServer(&stubCartService{carts: map[int]*model.Cart{}}, pubsub.NewHub(), nil, limiter,
+		NewBearerAuthenticator(&auth.JWTVerifier{Secret: secret}))
+	defer server.Stop()
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	client := cartpb.NewCartServiceClient(conn)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256,
+		jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(), "scope": auth.ScopeCartWrite}).SignedString(secret)
+	require.NoError(t, err)
+
+	invalid := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid")
+	valid := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.CreateCart(invalid, &cartpb.CreateCartRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	_, err = client.CreateCart(valid, &cartpb.CreateCartRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "the blocked peer must not be authenticated")
+}